@@ -8,6 +8,7 @@ require (
 	github.com/PuerkitoBio/goquery v1.10.3
 	github.com/expr-lang/expr v1.17.6
 	github.com/protocolbuffers/txtpbfmt v0.0.0-20251002044816-ff5ff96e8aaf
+	golang.org/x/net v0.44.0
 	golang.org/x/text v0.29.0
 	golang.org/x/time v0.13.0
 	google.golang.org/protobuf v1.36.10
@@ -16,5 +17,4 @@ require (
 require (
 	github.com/andybalholm/cascadia v1.3.3 // indirect
 	github.com/mitchellh/go-wordwrap v1.0.1 // indirect
-	golang.org/x/net v0.44.0 // indirect
 )
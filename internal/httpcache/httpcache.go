@@ -8,6 +8,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
 	"net/http/httputil"
@@ -16,11 +17,14 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
-// Transport caches HTTP responses indefinitely based on a URL and an optional
-// category. It supports redacting sensitive headers/parameters.
+// Transport caches HTTP responses indefinitely based on a URL (and the body for
+// POST requests) and an optional category. It supports redacting sensitive
+// headers/parameters.
 type Transport struct {
 	// Path is the path to store cached requests at.
 	Path string
@@ -34,11 +38,29 @@ type Transport struct {
 	// ResponseRedactor redacts responses.
 	ResponseRedactor ResponseRedactor
 
+	// MaxAge returns the maximum age of cached responses in a category, based
+	// on the Date header of the cached response (or the modification time of
+	// the cache file if missing). If nil or zero, cached responses are used
+	// indefinitely. Expired responses are still used if Next is nil.
+	MaxAge func(category string) time.Duration
+
+	// Refresh returns true if the cached response for a request should not be
+	// used. Cached responses are still used if Next is nil.
+	Refresh func(req *http.Request) bool
+
 	// Next is the transport to use for making requests. If nil, only cached
 	// responses are used.
 	Next http.RoundTripper
 }
 
+// cachedHeader is set on responses loaded from the cache.
+const cachedHeader = "X-Httpcache-Cached"
+
+// Cached returns true if resp was loaded from the cache.
+func Cached(resp *http.Response) bool {
+	return resp.Header.Get(cachedHeader) != ""
+}
+
 type categoryKey struct{}
 
 func CategoryContext(ctx context.Context, category string) context.Context {
@@ -56,21 +78,36 @@ func contextCategory(ctx context.Context) string {
 	return "req"
 }
 
-func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
-	if req.Method != http.MethodGet {
-		return nil, fmt.Errorf("httpcache: unsupported method %s", req.Method)
-	}
+type noFetchKey struct{}
+
+// NoFetchContext makes requests only use cached responses, returning an error
+// wrapping ErrNotCached if there isn't an unexpired one.
+func NoFetchContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noFetchKey{}, true)
+}
+
+type noStoreKey struct{}
+
+// NoStoreContext prevents fetched responses from being stored in the cache.
+func NoStoreContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noStoreKey{}, true)
+}
+
+// ErrNotCached is wrapped by the error returned if a response is not cached
+// and cannot be fetched.
+var ErrNotCached = errors.New("response not in cache")
 
-	var cacheName, cacheSuffix string
-	if t.Path != "" {
-		s := sha1.Sum([]byte(req.URL.String()))
-		cacheSuffix = "-" + hex.EncodeToString(s[:])
-		cacheName = filepath.Join(t.Path, contextCategory(req.Context())+cacheSuffix)
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, body, err := bufferRequest(req)
+	if err != nil {
+		return nil, err
 	}
+	cacheName, cacheSuffix := t.cacheName(req, body)
 
 	var resp *http.Response
 	if cacheName != "" {
-		buf, err := os.ReadFile(cacheName)
+		readName := cacheName
+		buf, err := os.ReadFile(readName)
 		if t.Fallback && errors.Is(err, fs.ErrNotExist) {
 			ds, err1 := os.ReadDir(t.Path)
 			if err1 != nil {
@@ -78,7 +115,8 @@ func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 			}
 			for _, d := range ds {
 				if strings.HasSuffix(d.Name(), cacheSuffix) {
-					buf, err = os.ReadFile(filepath.Join(t.Path, d.Name()))
+					readName = filepath.Join(t.Path, d.Name())
+					buf, err = os.ReadFile(readName)
 					if err != nil {
 						return nil, fmt.Errorf("httpcache: read fallback cached response: %w", err)
 					}
@@ -89,62 +127,160 @@ func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 		if err == nil {
 			r := bufio.NewReader(bytes.NewReader(buf))
 
-			req, err := http.ReadRequest(r)
+			creq, err := http.ReadRequest(r)
 			if err != nil {
 				return nil, fmt.Errorf("httpcache: read cached response: %w", err)
 			}
-			req.URL.Scheme = "https"
-			req.URL.Host = req.Host
+			creq.URL.Scheme = "https"
+			creq.URL.Host = creq.Host
+			if _, err := io.Copy(io.Discard, creq.Body); err != nil {
+				return nil, fmt.Errorf("httpcache: read cached response: %w", err)
+			}
 
-			resp, err = http.ReadResponse(r, req)
+			resp, err = http.ReadResponse(r, creq)
 			if err != nil {
 				return nil, fmt.Errorf("httpcache: read cached response: %w", err)
 			}
-			return resp, nil
+			if t.Next == nil || !t.stale(req, resp, readName) {
+				resp.Header.Set(cachedHeader, "1")
+				return resp, nil
+			}
+			resp.Body.Close()
 		} else if !errors.Is(err, os.ErrNotExist) {
 			return nil, fmt.Errorf("httpcache: read cached response: %w", err)
 		}
 	}
 
-	if t.Next == nil {
+	if t.Next == nil || req.Context().Value(noFetchKey{}) != nil {
 		if cacheName == "" {
-			return nil, fmt.Errorf("httpcache: fetch disabled")
+			return nil, fmt.Errorf("httpcache: fetch disabled, %w", ErrNotCached)
 		}
-		return nil, fmt.Errorf("httpcache: fetch disabled, response not in cache (%s)", cacheName)
+		return nil, fmt.Errorf("httpcache: fetch disabled, %w (%s)", ErrNotCached, cacheName)
 	}
 
-	redacted := req
-	if t.RequestRedactor != nil {
-		redacted = t.RequestRedactor.Redact(req)
-	}
-
-	resp, err := t.Next.RoundTrip(req)
+	resp, err = t.Next.RoundTrip(req)
 	if err != nil {
 		return nil, err
 	}
 
-	defer resp.Body.Close()
-
 	if t.ResponseRedactor != nil {
 		resp = t.ResponseRedactor.RedactResponse(resp)
 	}
 
+	if cacheName != "" && req.Context().Value(noStoreKey{}) == nil {
+		if err := t.store(cacheName, req, body, resp); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// Put stores resp as the cached response for req as if it was fetched. The
+// response body is consumed and replaced.
+func (t *Transport) Put(req *http.Request, resp *http.Response) error {
+	req, body, err := bufferRequest(req)
+	if err != nil {
+		return err
+	}
+	if cacheName, _ := t.cacheName(req, body); cacheName != "" {
+		return t.store(cacheName, req, body, resp)
+	}
+	return nil
+}
+
+// bufferRequest returns a copy of req with the body (for POST requests)
+// buffered so it can be used as part of the key and stored.
+func bufferRequest(req *http.Request) (*http.Request, []byte, error) {
+	switch req.Method {
+	case http.MethodGet:
+		return req, nil, nil
+	case http.MethodPost:
+		var body []byte
+		if req.Body != nil {
+			var err error
+			body, err = io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, nil, fmt.Errorf("httpcache: read request body: %w", err)
+			}
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.ContentLength = int64(len(body))
+		return req, body, nil
+	default:
+		return nil, nil, fmt.Errorf("httpcache: unsupported method %s", req.Method)
+	}
+}
+
+// cacheName returns the cache file for a request buffered by bufferRequest,
+// and the category-independent suffix of it.
+func (t *Transport) cacheName(req *http.Request, body []byte) (name, suffix string) {
+	if t.Path == "" {
+		return "", ""
+	}
+	key := req.URL.String()
+	if req.Method != http.MethodGet {
+		key = req.Method + " " + key + "\n" + string(body)
+	}
+	s := sha1.Sum([]byte(key))
+	suffix = "-" + hex.EncodeToString(s[:])
+	return filepath.Join(t.Path, contextCategory(req.Context())+suffix), suffix
+}
+
+// store writes the redacted request and the response to the cache file,
+// replacing the response body so it can still be read.
+func (t *Transport) store(name string, req *http.Request, body []byte, resp *http.Response) error {
+	defer resp.Body.Close() // replaced by DumpResponse
+
+	redacted := req
+	if t.RequestRedactor != nil {
+		redacted = t.RequestRedactor.Redact(req)
+	}
+	if req.Method != http.MethodGet {
+		r2 := *redacted
+		r2.Header = redacted.Header.Clone()
+		r2.Header.Set("Content-Length", strconv.Itoa(len(body))) // not written by DumpRequest
+		r2.Body = io.NopCloser(bytes.NewReader(body))            // may have been consumed by Next
+		redacted = &r2
+	}
+
 	reqbuf, err := httputil.DumpRequest(redacted, true)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	respbuf, err := httputil.DumpResponse(resp, true)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if cacheName != "" {
-		if err := os.WriteFile(cacheName, slices.Concat(reqbuf, respbuf), 0666); err != nil {
-			return nil, fmt.Errorf("httpcache: write cached response: %w", err)
+	if err := os.WriteFile(name, slices.Concat(reqbuf, respbuf), 0666); err != nil {
+		return fmt.Errorf("httpcache: write cached response: %w", err)
+	}
+	return nil
+}
+
+// stale checks whether the cached response for req should be refetched.
+func (t *Transport) stale(req *http.Request, resp *http.Response, name string) bool {
+	if t.Refresh != nil && t.Refresh(req) {
+		return true
+	}
+	if t.MaxAge != nil {
+		if maxAge := t.MaxAge(contextCategory(req.Context())); maxAge > 0 {
+			date, err := http.ParseTime(resp.Header.Get("Date"))
+			if err != nil {
+				if fi, err := os.Stat(name); err == nil {
+					date = fi.ModTime()
+				}
+			}
+			return time.Since(date) > maxAge
 		}
 	}
-	return resp, nil
+	return false
 }
 
 // Purge purges the specified categories from the cache.
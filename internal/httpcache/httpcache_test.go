@@ -0,0 +1,205 @@
+package httpcache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+// testTransport returns a cache transport which responds with the request
+// method and body, and a pointer to the number of fetched requests.
+func testTransport(t *testing.T) (*Transport, *int) {
+	var fetched int
+	return &Transport{
+		Path: t.TempDir(),
+		Next: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			fetched++
+			var body []byte
+			if r.Body != nil {
+				var err error
+				if body, err = io.ReadAll(r.Body); err != nil {
+					return nil, err
+				}
+			}
+			return &http.Response{
+				StatusCode: 200,
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(r.Method + " " + string(body))),
+			}, nil
+		}),
+	}, &fetched
+}
+
+func testDo(t *testing.T, rt http.RoundTripper, ctx context.Context, method, body string) (string, bool, error) {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, "https://example.com/test", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return string(buf), Cached(resp), nil
+}
+
+func TestPost(t *testing.T) {
+	c, fetched := testTransport(t)
+	ctx := context.Background()
+	for _, tc := range []struct {
+		method, body string
+		cached       bool
+	}{
+		{"POST", "a", false},
+		{"POST", "b", false},
+		{"POST", "a", true},
+		{"GET", "", false},
+		{"POST", "", false},
+		{"POST", "b", true},
+		{"GET", "", true},
+	} {
+		// the body must still be sent after being buffered for the key
+		if act, cached, err := testDo(t, c, ctx, tc.method, tc.body); err != nil {
+			t.Errorf("%s %q: unexpected error: %v", tc.method, tc.body, err)
+		} else if exp := tc.method + " " + tc.body; act != exp || cached != tc.cached {
+			t.Errorf("%s %q: got %q (cached=%t), expected %q (cached=%t)", tc.method, tc.body, act, cached, exp, tc.cached)
+		}
+	}
+	if *fetched != 4 {
+		t.Errorf("expected 4 fetches, got %d", *fetched)
+	}
+	if _, _, err := testDo(t, c, ctx, "PUT", "a"); err == nil {
+		t.Errorf("expected error for unsupported method")
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	c, fetched := testTransport(t)
+	c.MaxAge = func(category string) time.Duration {
+		if category == "short" {
+			return time.Hour
+		}
+		return 0
+	}
+	short := CategoryContext(context.Background(), "short")
+	long := CategoryContext(context.Background(), "long")
+	for _, ctx := range []context.Context{short, long} {
+		testDo(t, c, ctx, "GET", "")
+	}
+
+	// expire the cached responses
+	ds, err := os.ReadDir(c.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	for _, d := range ds {
+		if err := os.Chtimes(filepath.Join(c.Path, d.Name()), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, cached, _ := testDo(t, c, long, "GET", ""); !cached {
+		t.Errorf("expected response without max age to be cached")
+	}
+	if _, cached, _ := testDo(t, c, short, "GET", ""); cached {
+		t.Errorf("expected expired response to be refetched")
+	}
+	if _, cached, _ := testDo(t, c, short, "GET", ""); !cached {
+		t.Errorf("expected refetched response to be cached")
+	}
+	if *fetched != 3 {
+		t.Errorf("expected 3 fetches, got %d", *fetched)
+	}
+
+	// expired responses are still used if fetching is disabled
+	for _, d := range ds {
+		if err := os.Chtimes(filepath.Join(c.Path, d.Name()), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.Next = nil
+	if _, cached, err := testDo(t, c, short, "GET", ""); err != nil || !cached {
+		t.Errorf("expected expired response to be used without fetching, got error %v", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	c, fetched := testTransport(t)
+	var refresh bool
+	c.Refresh = func(req *http.Request) bool {
+		return refresh
+	}
+	ctx := context.Background()
+	testDo(t, c, ctx, "GET", "")
+	if _, cached, _ := testDo(t, c, ctx, "GET", ""); !cached {
+		t.Errorf("expected cached response")
+	}
+	refresh = true
+	if _, cached, _ := testDo(t, c, ctx, "GET", ""); cached {
+		t.Errorf("expected refreshed response")
+	}
+	if *fetched != 2 {
+		t.Errorf("expected 2 fetches, got %d", *fetched)
+	}
+	c.Next = nil
+	if _, cached, err := testDo(t, c, ctx, "GET", ""); err != nil || !cached {
+		t.Errorf("expected cached response to be used without fetching, got error %v", err)
+	}
+}
+
+func TestNoFetchNoStore(t *testing.T) {
+	c, fetched := testTransport(t)
+	ctx := context.Background()
+	if _, _, err := testDo(t, c, NoFetchContext(ctx), "POST", "a"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("expected ErrNotCached, got %v", err)
+	}
+	if _, cached, err := testDo(t, c, NoStoreContext(ctx), "POST", "a"); err != nil || cached {
+		t.Errorf("expected fetched response, got error %v", err)
+	}
+	if _, _, err := testDo(t, c, NoFetchContext(ctx), "POST", "a"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("expected response not to be stored, got %v", err)
+	}
+	if *fetched != 1 {
+		t.Errorf("expected 1 fetch, got %d", *fetched)
+	}
+
+	req, err := http.NewRequest("POST", "https://example.com/test", strings.NewReader("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put(req, &http.Response{
+		StatusCode: 200,
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("stored")),
+	}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if act, cached, err := testDo(t, c, NoFetchContext(ctx), "POST", "b"); err != nil || !cached || act != "stored" {
+		t.Errorf("expected stored response, got %q (cached=%t, error=%v)", act, cached, err)
+	}
+}
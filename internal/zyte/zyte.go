@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"cmp"
 	"context"
+	crand "crypto/rand"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"maps"
 	"math/rand"
@@ -14,6 +16,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -36,9 +39,42 @@ type Transport struct {
 	// false, beware of redirect loops.
 	FollowRedirect bool
 
+	// Geolocation is the ISO 3166-1 alpha-2 country code to make requests from
+	// (e.g., CA). If empty, Zyte chooses one.
+	Geolocation string
+
+	// IPType is the type of IP address to make requests from (datacenter or
+	// residential). If empty, Zyte chooses one.
+	IPType string
+
+	// Device is the type of device to emulate (desktop or mobile). If empty,
+	// Zyte's default (desktop) is used.
+	Device string
+
+	// Sessions is the number of client-managed sessions to keep in a pool. If
+	// non-zero, every request uses a session from the pool (chosen by the
+	// SessionContext key, or at random otherwise), so it shares cookies and the
+	// IP address with other requests in the session. Sessions are replaced when
+	// they are banned. If zero, sessions are only used for requests with a
+	// SessionContext, and are managed by Zyte.
+	Sessions int
+
+	// Parallelism is the maximum number of Zyte API requests in progress at
+	// once, which should match the account's limit to avoid rate-limiting
+	// responses. Additional requests wait until one finishes or their context
+	// is canceled. If zero, it is unlimited. It must not be changed after the
+	// first request.
+	Parallelism int
+
 	// Next is used for making Zyte API requests. If nil,
 	// [http.DefaultTransport] is used.
 	Next http.RoundTripper
+
+	sessionMu  sync.Mutex
+	sessionIDs []string
+
+	inflightOnce sync.Once
+	inflight     chan struct{}
 }
 
 // RetryFunc is called with the number of retries attempted and the last
@@ -146,6 +182,129 @@ func (z Error) Is(o error) bool {
 
 type requestKey struct{}
 
+type sessionKey struct{}
+
+// SessionContext returns a context which makes requests with the same key
+// share a Zyte session (see Transport.Sessions).
+func SessionContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, key)
+}
+
+// WithSession is like SessionContext, but for a request.
+func WithSession(r *http.Request, key string) *http.Request {
+	return r.WithContext(SessionContext(r.Context(), key))
+}
+
+func contextSession(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(sessionKey{}).(string)
+	return v, ok
+}
+
+// Options overrides Transport options for a request. Zero fields are not
+// overridden.
+type Options struct {
+	FollowRedirect *bool
+	Geolocation    string
+	IPType         string
+	Device         string
+
+	// Browser renders the page in a browser, returning the rendered html as
+	// the response body. Only GET requests without a body are supported, and
+	// only the Referer request header is sent. Browser requests cost more than
+	// plain ones, and always follow redirects.
+	Browser *bool
+
+	// Actions are browser actions to perform before returning the rendered
+	// html (see the Zyte API docs). Only used if Browser is set.
+	Actions []map[string]any
+
+	// Timeout limits the time taken by the request, including retries.
+	Timeout time.Duration
+}
+
+type optionsKey struct{}
+
+// WithOptions returns a context which overrides Transport options for requests
+// made with it, on top of any overrides already in ctx.
+func WithOptions(ctx context.Context, opt Options) context.Context {
+	if prev, ok := ctx.Value(optionsKey{}).(Options); ok {
+		opt = prev.merge(opt)
+	}
+	return context.WithValue(ctx, optionsKey{}, opt)
+}
+
+// merge returns o with the non-zero fields of x.
+func (o Options) merge(x Options) Options {
+	o.FollowRedirect = cmp.Or(x.FollowRedirect, o.FollowRedirect)
+	o.Geolocation = cmp.Or(x.Geolocation, o.Geolocation)
+	o.IPType = cmp.Or(x.IPType, o.IPType)
+	o.Device = cmp.Or(x.Device, o.Device)
+	o.Browser = cmp.Or(x.Browser, o.Browser)
+	if x.Actions != nil {
+		o.Actions = x.Actions
+	}
+	o.Timeout = cmp.Or(x.Timeout, o.Timeout)
+	return o
+}
+
+// options gets the options for a request made with ctx.
+func (z *Transport) options(ctx context.Context) Options {
+	followRedirect := z.FollowRedirect
+	opt := Options{
+		FollowRedirect: &followRedirect,
+		Geolocation:    z.Geolocation,
+		IPType:         z.IPType,
+		Device:         z.Device,
+	}
+	if x, ok := ctx.Value(optionsKey{}).(Options); ok {
+		opt = opt.merge(x)
+	}
+	return opt
+}
+
+// session gets the pooled session for ctx, returning -1 if there isn't a pool.
+func (z *Transport) session(ctx context.Context) (slot int, id string) {
+	if z.Sessions <= 0 {
+		return -1, ""
+	}
+	z.sessionMu.Lock()
+	defer z.sessionMu.Unlock()
+
+	if len(z.sessionIDs) != z.Sessions {
+		z.sessionIDs = make([]string, z.Sessions)
+	}
+	if key, ok := contextSession(ctx); ok {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		slot = int(h.Sum32() % uint32(z.Sessions))
+	} else {
+		slot = rand.Intn(z.Sessions)
+	}
+	if z.sessionIDs[slot] == "" {
+		z.sessionIDs[slot] = newSessionID()
+	}
+	return slot, z.sessionIDs[slot]
+}
+
+// dropSession replaces the pooled session if it is still id.
+func (z *Transport) dropSession(slot int, id string) {
+	z.sessionMu.Lock()
+	defer z.sessionMu.Unlock()
+
+	if slot >= 0 && slot < len(z.sessionIDs) && z.sessionIDs[slot] == id {
+		z.sessionIDs[slot] = ""
+	}
+}
+
+// newSessionID generates a random UUID for a client-managed session.
+func newSessionID() string {
+	var b [16]byte
+	crand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
 var _ http.RoundTripper = (*Transport)(nil)
 
 func (z *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -156,11 +315,16 @@ func (z *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	}
 	ctx = context.WithValue(ctx, requestKey{}, true)
 
+	opt := z.options(ctx)
+	if opt.Browser != nil && *opt.Browser {
+		return z.roundTripBrowser(ctx, req, opt)
+	}
+
 	zreqObj := map[string]any{
 		"httpResponseBody":    true,
 		"httpResponseHeaders": true,
 		"url":                 req.URL.String(),
-		"followRedirect":      z.FollowRedirect,
+		"followRedirect":      *opt.FollowRedirect,
 	}
 	if req.Method != http.MethodGet {
 		zreqObj["httpRequestMethod"] = req.Method
@@ -192,11 +356,6 @@ func (z *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 		}
 		zreqObj["customHttpRequestHeaders"] = obj
 	}
-	zreqBuf, err := json.Marshal(zreqObj)
-	if err != nil {
-		return nil, fmt.Errorf("zyte: prepare request: %w", err)
-	}
-
 	var zrespObj struct {
 		URL                 string  `json:"url"`
 		StatusCode          int     `json:"statusCode"`
@@ -206,25 +365,226 @@ func (z *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 			Value string `json:"value"`
 		} `json:"httpResponseHeaders"`
 	}
+	if err := z.extract(ctx, zreqObj, &zrespObj); err != nil {
+		return nil, err
+	}
+	if zrespObj.StatusCode == 0 || zrespObj.URL == "" || zrespObj.HTTPResponseBody == nil || zrespObj.HTTPResponseHeaders == nil {
+		return nil, fmt.Errorf("zyte: failed to parse response: missing fields")
+	}
+
+	body := *zrespObj.HTTPResponseBody
+	fresp, err := makeResponse(ctx, req, zrespObj.URL, zrespObj.StatusCode, body)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range *zrespObj.HTTPResponseHeaders {
+		fresp.Header.Add(h.Name, h.Value)
+	}
+	if len(body) != 0 {
+		if fresp.Header.Get("Content-Encoding") != "" {
+			fresp.ContentLength = -1
+			fresp.Uncompressed = true
+			fresp.Header.Del("Content-Encoding")
+			fresp.Header.Del("Content-Length")
+		} else {
+			fresp.ContentLength = int64(len(body))
+		}
+	}
+	return fresp, nil
+}
+
+// roundTripBrowser is RoundTrip for browser requests.
+func (z *Transport) roundTripBrowser(ctx context.Context, req *http.Request, opt Options) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return nil, fmt.Errorf("zyte: browser requests must be GET")
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		return nil, fmt.Errorf("zyte: browser requests must not have a body")
+	}
+	if cookies := req.Cookies(); len(cookies) != 0 {
+		return nil, fmt.Errorf("zyte: cookies not supported")
+	}
+
+	zreqObj := map[string]any{
+		"browserHtml": true,
+		"url":         req.URL.String(),
+	}
+	if referer := req.Header.Get("Referer"); referer != "" {
+		zreqObj["requestHeaders"] = map[string]any{
+			"referer": referer,
+		}
+	}
+	if opt.Actions != nil {
+		zreqObj["actions"] = opt.Actions
+	}
+
+	var zrespObj struct {
+		URL         string  `json:"url"`
+		StatusCode  int     `json:"statusCode"`
+		BrowserHTML *string `json:"browserHtml"`
+	}
+	if err := z.extract(ctx, zreqObj, &zrespObj); err != nil {
+		return nil, err
+	}
+	if zrespObj.StatusCode == 0 || zrespObj.URL == "" || zrespObj.BrowserHTML == nil {
+		return nil, fmt.Errorf("zyte: failed to parse response: missing fields")
+	}
+
+	fresp, err := makeResponse(ctx, req, zrespObj.URL, zrespObj.StatusCode, []byte(*zrespObj.BrowserHTML))
+	if err != nil {
+		return nil, err
+	}
+	fresp.Header.Set("Content-Type", "text/html; charset=utf-8")
+	fresp.ContentLength = int64(len(*zrespObj.BrowserHTML))
+	return fresp, nil
+}
+
+// makeResponse makes a response to req for a Zyte API response.
+func makeResponse(ctx context.Context, req *http.Request, u string, status int, body []byte) (*http.Response, error) {
+	freq := req.Clone(ctx)
+	if ru, err := url.Parse(u); err != nil {
+		return nil, fmt.Errorf("parse response url: %w", err)
+	} else {
+		freq.URL = ru
+		freq.Host = ru.Host
+	}
+	fresp := &http.Response{
+		Status:     http.StatusText(status),
+		StatusCode: status,
+		Proto:      req.Proto,
+		ProtoMajor: req.ProtoMajor,
+		ProtoMinor: req.ProtoMinor,
+		Request:    freq,
+		Header:     http.Header{},
+		Close:      true,
+	}
+	if len(body) != 0 {
+		fresp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return fresp, nil
+}
+
+// ScreenshotOptions controls how a screenshot is taken.
+type ScreenshotOptions struct {
+	// Format is the image format (jpeg or png). If empty, Zyte's default
+	// (jpeg) is used.
+	Format string
+
+	// FullPage captures the full scrollable page instead of the viewport.
+	FullPage bool
+
+	// Width and Height set the browser viewport size in pixels. If both are
+	// zero, Zyte's default is used.
+	Width, Height int
+}
+
+// Screenshot renders u in a browser and returns a screenshot of it. Limits and
+// retries are handled like RoundTrip. Browser requests cost more than plain
+// ones.
+func (z *Transport) Screenshot(ctx context.Context, u string, opt ScreenshotOptions) ([]byte, error) {
+	if ctx.Value(requestKey{}) != nil {
+		return nil, fmt.Errorf("recursive %T", z)
+	}
+	ctx = context.WithValue(ctx, requestKey{}, true)
+
+	zreqObj := map[string]any{
+		"url":        u,
+		"screenshot": true,
+	}
+	screenshotOptions := map[string]any{}
+	if opt.Format != "" {
+		screenshotOptions["format"] = opt.Format
+	}
+	if opt.FullPage {
+		screenshotOptions["fullPage"] = true
+	}
+	if len(screenshotOptions) != 0 {
+		zreqObj["screenshotOptions"] = screenshotOptions
+	}
+	if opt.Width != 0 || opt.Height != 0 {
+		zreqObj["viewport"] = map[string]any{
+			"width":  opt.Width,
+			"height": opt.Height,
+		}
+	}
+
+	var zrespObj struct {
+		Screenshot *[]byte `json:"screenshot"` // base64
+	}
+	if err := z.extract(ctx, zreqObj, &zrespObj); err != nil {
+		return nil, err
+	}
+	if zrespObj.Screenshot == nil {
+		return nil, fmt.Errorf("zyte: failed to parse response: missing fields")
+	}
+	return *zrespObj.Screenshot, nil
+}
+
+// extract makes a Zyte API request, handling limits, retries, and errors, and
+// decodes the successful response into zrespObj.
+func (z *Transport) extract(ctx context.Context, zreqObj map[string]any, zrespObj any) error {
+	opt := z.options(ctx)
+	if opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.Timeout)
+		defer cancel()
+	}
+	if opt.Geolocation != "" {
+		zreqObj["geolocation"] = opt.Geolocation
+	}
+	if opt.IPType != "" {
+		zreqObj["ipType"] = opt.IPType
+	}
+	if opt.Device != "" {
+		zreqObj["device"] = opt.Device
+	}
+
 	var tries int
 	for {
 		if z.Limit != nil {
 			if err := z.Limit(0); err != nil {
-				return nil, fmt.Errorf("zyte: request limit reached: %w", err)
+				return fmt.Errorf("zyte: request limit reached: %w", err)
 			}
 		}
 
+		slot, session := z.session(ctx)
+		if session != "" {
+			zreqObj["session"] = map[string]any{
+				"id": session,
+			}
+		} else if key, ok := contextSession(ctx); ok {
+			zreqObj["sessionContext"] = []any{map[string]any{
+				"name":  "session",
+				"value": key,
+			}}
+		}
+		zreqBuf, err := json.Marshal(zreqObj)
+		if err != nil {
+			return fmt.Errorf("zyte: prepare request: %w", err)
+		}
+
 		zreq, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.zyte.com/v1/extract", bytes.NewReader(zreqBuf))
 		if err != nil {
-			return nil, fmt.Errorf("zyte: prepare request: %w", err)
+			return fmt.Errorf("zyte: prepare request: %w", err)
 		}
 		if z.APIKey != "" {
 			zreq.SetBasicAuth(z.APIKey, "")
 		}
 
+		release, err := z.acquire(ctx)
+		if err != nil {
+			return err
+		}
 		zresp, err := cmp.Or(z.Next, http.DefaultTransport).RoundTrip(zreq)
 		if err != nil {
-			return nil, err
+			release()
+			return err
+		}
+		zbody, err := io.ReadAll(zresp.Body)
+		zresp.Body.Close()
+		release() // the request is only finished once the body is consumed
+		if err != nil {
+			return fmt.Errorf("zyte: read response: %w", err)
 		}
 
 		// https://docs.zyte.com/zyte-api/usage/errors.html#successful-responses
@@ -240,7 +600,7 @@ func (z *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 			if s == "" {
 				select {
 				case <-ctx.Done():
-					return nil, ctx.Err()
+					return ctx.Err()
 				case <-time.After(time.Second * 30):
 					continue
 				}
@@ -248,7 +608,7 @@ func (z *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 			if retryAfter, err := http.ParseTime(s); err == nil {
 				select {
 				case <-ctx.Done():
-					return nil, ctx.Err()
+					return ctx.Err()
 				case <-time.After(time.Until(retryAfter)):
 					continue
 				}
@@ -256,83 +616,67 @@ func (z *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 			if retryAfter, err := strconv.Atoi(s); err == nil {
 				select {
 				case <-ctx.Done():
-					return nil, ctx.Err()
+					return ctx.Err()
 				case <-time.After(time.Second * time.Duration(retryAfter)):
 					continue
 				}
 			}
-			return nil, fmt.Errorf("zyte: failed to parse rate-limit retry-after %q", s)
+			return fmt.Errorf("zyte: failed to parse rate-limit retry-after %q", s)
 		}
 
 		// https://docs.zyte.com/zyte-api/usage/errors.html#ban-responses
 		// https://docs.zyte.com/zyte-api/usage/errors.html#permanent-download-errors
 		if zresp.StatusCode == 500 || zresp.StatusCode == 520 || zresp.StatusCode == 521 {
+			if session != "" {
+				z.dropSession(slot, session) // use a new session (and ip) for the retry
+			}
 			if z.Retry != nil && z.Retry(ctx, tries, zresp.StatusCode) {
 				tries++
 				continue
 			}
 			if err := ctx.Err(); err != nil {
-				return nil, err
+				return err
 			}
-			return nil, fmt.Errorf("zyte: retry limit reached (try %d, status %d)", tries, zresp.StatusCode)
+			return fmt.Errorf("zyte: retry limit reached (try %d, status %d)", tries, zresp.StatusCode)
 		}
 
 		// https://docs.zyte.com/zyte-api/usage/errors.html#unsuccessful-responses
 		if zresp.StatusCode != 200 {
-			buf, err := io.ReadAll(zresp.Body)
-			if err != nil {
-				return nil, fmt.Errorf("zyte: failed to parse error %d response: %w", zresp.StatusCode, err)
-			}
+			buf := zbody
 			var zerr Error
 			if err := json.Unmarshal(buf, &zerr); err != nil {
 				if len(buf) > 1024 {
 					buf = buf[:1024]
 				}
-				return nil, fmt.Errorf("zyte: failed to parse error %d response %q: %w", zresp.StatusCode, string(buf), err)
+				return fmt.Errorf("zyte: failed to parse error %d response %q: %w", zresp.StatusCode, string(buf), err)
 			}
-			return nil, zerr
+			return zerr
 		}
 
 		// https://docs.zyte.com/zyte-api/usage/errors.html#successful-responses
-		if err := json.NewDecoder(zresp.Body).Decode(&zrespObj); err != nil {
-			return nil, fmt.Errorf("zyte: failed to parse response: %w", err)
-		}
-		if zrespObj.StatusCode == 0 || zrespObj.URL == "" || zrespObj.HTTPResponseBody == nil || zrespObj.HTTPResponseHeaders == nil {
-			return nil, fmt.Errorf("zyte: failed to parse response: missing fields")
+		if err := json.Unmarshal(zbody, zrespObj); err != nil {
+			return fmt.Errorf("zyte: failed to parse response: %w", err)
 		}
-		break
+		return nil
 	}
 
-	freq := req.Clone(ctx)
-	if ru, err := url.Parse(zrespObj.URL); err != nil {
-		return nil, fmt.Errorf("parse response url: %w", err)
-	} else {
-		freq.URL = ru
-		freq.Host = ru.Host
-	}
-	fresp := &http.Response{
-		Status:     http.StatusText(zrespObj.StatusCode),
-		StatusCode: zrespObj.StatusCode,
-		Proto:      req.Proto,
-		ProtoMajor: req.ProtoMajor,
-		ProtoMinor: req.ProtoMinor,
-		Request:    freq,
-		Header:     http.Header{},
-		Close:      true,
-	}
-	for _, h := range *zrespObj.HTTPResponseHeaders {
-		fresp.Header.Add(h.Name, h.Value)
-	}
-	if buf := *zrespObj.HTTPResponseBody; len(buf) != 0 {
-		if fresp.Header.Get("Content-Encoding") != "" {
-			fresp.ContentLength = -1
-			fresp.Uncompressed = true
-			fresp.Header.Del("Content-Encoding")
-			fresp.Header.Del("Content-Length")
-		} else {
-			fresp.ContentLength = int64(len(buf))
+}
+
+// acquire waits for a slot to make a Zyte API request, returning a function to
+// release it.
+func (z *Transport) acquire(ctx context.Context) (release func(), err error) {
+	z.inflightOnce.Do(func() {
+		if z.Parallelism > 0 {
+			z.inflight = make(chan struct{}, z.Parallelism)
 		}
-		fresp.Body = io.NopCloser(bytes.NewReader(buf))
+	})
+	if z.inflight == nil {
+		return func() {}, nil
+	}
+	select {
+	case z.inflight <- struct{}{}:
+		return func() { <-z.inflight }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
-	return fresp, nil
 }
@@ -0,0 +1,492 @@
+package zyte
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+// slowBody is a response body which takes a while to read.
+type slowBody struct {
+	io.Reader
+	once  sync.Once
+	close func()
+}
+
+func (b *slowBody) Read(p []byte) (int, error) {
+	b.once.Do(func() { time.Sleep(time.Millisecond * 20) })
+	return b.Reader.Read(p)
+}
+
+func (b *slowBody) Close() error {
+	b.close()
+	return nil
+}
+
+// testAPI returns a fake Zyte API transport which calls fn with the decoded
+// request, and responds with the returned status and body (if a successful
+// response, the body is used as the page or browser html, or screenshot).
+func testAPI(t *testing.T, fn func(zreq map[string]any) (int, string)) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		var zreq map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&zreq); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		status, body := fn(zreq)
+		if status == 200 {
+			zresp := map[string]any{
+				"url":        zreq["url"],
+				"statusCode": 200,
+			}
+			if zreq["screenshot"] == true {
+				zresp["screenshot"] = []byte(body)
+			} else if zreq["browserHtml"] == true {
+				zresp["browserHtml"] = body
+			} else {
+				zresp["httpResponseBody"] = []byte(body)
+				zresp["httpResponseHeaders"] = []any{}
+			}
+			buf, err := json.Marshal(zresp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body = string(buf)
+		}
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{},
+			Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+		}, nil
+	})
+}
+
+func testGet(ctx context.Context, z *Transport) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := z.RoundTrip(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	buf, err := io.ReadAll(resp.Body)
+	return string(buf), err
+}
+
+func TestRetry(t *testing.T) {
+	var (
+		retries  []int
+		statuses []int
+	)
+	z := &Transport{
+		Retry: func(ctx context.Context, tries, code int) bool {
+			retries = append(retries, code)
+			return tries < 2
+		},
+		Next: testAPI(t, func(zreq map[string]any) (int, string) {
+			status := statuses[0]
+			statuses = statuses[1:]
+			switch status {
+			case 200:
+				return status, "hello"
+			case 520:
+				return status, `{"type":"/download/temporary-error","title":"Temporary Downloading Error","status":520}`
+			default:
+				return status, `{"type":"/request/invalid","title":"Invalid Request","status":400}`
+			}
+		}),
+	}
+
+	statuses = []int{520, 520, 200}
+	if body, err := testGet(context.Background(), z); err != nil || body != "hello" {
+		t.Errorf("unexpected response %q (error: %v)", body, err)
+	}
+	if len(retries) != 2 {
+		t.Errorf("expected 2 retries, got %v", retries)
+	}
+
+	retries = nil
+	statuses = []int{520, 520, 520}
+	if _, err := testGet(context.Background(), z); err == nil {
+		t.Errorf("expected error after retry limit")
+	}
+	if len(retries) != 3 {
+		t.Errorf("expected 3 retry attempts, got %v", retries)
+	}
+
+	retries = nil
+	statuses = []int{400}
+	if _, err := testGet(context.Background(), z); !errors.Is(err, Error{Type: "/request/invalid"}) {
+		t.Errorf("expected api error, got %v", err)
+	}
+	if len(retries) != 0 {
+		t.Errorf("expected error not to be retried, got %v", retries)
+	}
+}
+
+func TestScreenshot(t *testing.T) {
+	var last map[string]any
+	z := &Transport{
+		Next: testAPI(t, func(zreq map[string]any) (int, string) {
+			last = zreq
+			return 200, "\x89PNG\r\n\x1a\n"
+		}),
+	}
+
+	img, err := z.Screenshot(context.Background(), "https://example.com/", ScreenshotOptions{
+		Format:   "png",
+		FullPage: true,
+		Width:    1280,
+		Height:   720,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(img) != "\x89PNG\r\n\x1a\n" {
+		t.Errorf("unexpected screenshot %q", img)
+	}
+	if last["url"] != "https://example.com/" || last["screenshot"] != true {
+		t.Errorf("expected screenshot request, got %v", last)
+	}
+	if _, ok := last["httpResponseBody"]; ok {
+		t.Errorf("expected screenshot request without the page body, got %v", last)
+	}
+	if opt, _ := last["screenshotOptions"].(map[string]any); opt["format"] != "png" || opt["fullPage"] != true {
+		t.Errorf("unexpected screenshot options %v", last["screenshotOptions"])
+	}
+	if vp, _ := last["viewport"].(map[string]any); vp["width"] != 1280.0 || vp["height"] != 720.0 {
+		t.Errorf("unexpected viewport %v", last["viewport"])
+	}
+
+	if _, err := z.Screenshot(context.Background(), "https://example.com/", ScreenshotOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, k := range []string{"screenshotOptions", "viewport"} {
+		if v, ok := last[k]; ok {
+			t.Errorf("expected default %s, got %v", k, v)
+		}
+	}
+}
+
+func TestSessions(t *testing.T) {
+	var (
+		sessions []string
+		statuses = []int{200, 520, 521, 200, 200}
+	)
+	z := &Transport{
+		Sessions: 1,
+		Retry: func(ctx context.Context, tries, code int) bool {
+			return tries < 3
+		},
+		Next: testAPI(t, func(zreq map[string]any) (int, string) {
+			session, _ := zreq["session"].(map[string]any)
+			id, _ := session["id"].(string)
+			sessions = append(sessions, id)
+			status := statuses[0]
+			statuses = statuses[1:]
+			return status, `{"type":"/download/temporary-error","title":"Temporary Downloading Error","status":520}`
+		}),
+	}
+	for range 3 {
+		if _, err := testGet(context.Background(), z); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(sessions) != 5 {
+		t.Fatalf("expected 5 requests, got %d", len(sessions))
+	}
+	for i, s := range sessions {
+		if s == "" {
+			t.Errorf("request %d: expected session", i)
+		}
+	}
+	// a ban response should replace the session for the retry, and the new
+	// one should be kept for later requests
+	if sessions[0] != sessions[1] {
+		t.Errorf("expected the session to be reused before the ban")
+	}
+	if sessions[1] == sessions[2] || sessions[2] == sessions[3] {
+		t.Errorf("expected the session to be replaced after each ban")
+	}
+	if sessions[3] != sessions[4] {
+		t.Errorf("expected the session to be reused after the ban")
+	}
+}
+
+func TestSessionContext(t *testing.T) {
+	var last map[string]any
+	z := &Transport{
+		Sessions: 8,
+		Next: testAPI(t, func(zreq map[string]any) (int, string) {
+			last = zreq
+			return 200, "hello"
+		}),
+	}
+	sessionID := func(ctx context.Context) string {
+		if _, err := testGet(ctx, z); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := last["sessionContext"]; ok {
+			t.Errorf("expected client-managed session, got %v", last)
+		}
+		session, _ := last["session"].(map[string]any)
+		id, _ := session["id"].(string)
+		if id == "" {
+			t.Fatalf("expected session, got %v", last)
+		}
+		return id
+	}
+
+	// the same key should always use the same pooled session
+	ids := map[string]string{}
+	pool := map[string]bool{}
+	for _, key := range strings.Split("abcdefghijklmnopqrstuvwxyz", "") {
+		id := sessionID(SessionContext(context.Background(), key))
+		if x := sessionID(SessionContext(context.Background(), key)); x != id {
+			t.Errorf("key %q: expected session %s to be reused, got %s", key, id, x)
+		}
+		ids[key] = id
+		pool[id] = true
+	}
+	if len(pool) < 2 || len(pool) > z.Sessions {
+		t.Errorf("expected keys to be spread over at most %d sessions, got %d", z.Sessions, len(pool))
+	}
+	for key, id := range ids {
+		if x := sessionID(SessionContext(context.Background(), key)); x != id {
+			t.Errorf("key %q: expected session %s to be reused, got %s", key, id, x)
+		}
+	}
+
+	// requests without a key still use the pool
+	if id := sessionID(context.Background()); !pool[id] {
+		t.Errorf("expected a pooled session, got %s", id)
+	}
+}
+
+func TestSessionContextManaged(t *testing.T) {
+	var last map[string]any
+	z := &Transport{
+		Next: testAPI(t, func(zreq map[string]any) (int, string) {
+			last = zreq
+			return 200, "hello"
+		}),
+	}
+	if _, err := testGet(SessionContext(context.Background(), "booking"), z); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := last["session"]; ok {
+		t.Errorf("expected no client-managed session, got %v", last)
+	}
+	if sc, _ := last["sessionContext"].([]any); len(sc) != 1 {
+		t.Errorf("expected session context, got %v", last)
+	} else if x, _ := sc[0].(map[string]any); x["name"] != "session" || x["value"] != "booking" {
+		t.Errorf("unexpected session context %v", sc)
+	}
+
+	if _, err := testGet(context.Background(), z); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, k := range []string{"session", "sessionContext"} {
+		if v, ok := last[k]; ok {
+			t.Errorf("expected no %s without a session context, got %v", k, v)
+		}
+	}
+}
+
+func TestNetworkOptions(t *testing.T) {
+	var last map[string]any
+	next := testAPI(t, func(zreq map[string]any) (int, string) {
+		last = zreq
+		return 200, "hello"
+	})
+
+	z := &Transport{Next: next}
+	if _, err := testGet(context.Background(), z); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, k := range []string{"geolocation", "ipType", "device"} {
+		if v, ok := last[k]; ok {
+			t.Errorf("expected default %s, got %v", k, v)
+		}
+	}
+
+	z = &Transport{
+		Geolocation: "CA",
+		IPType:      "residential",
+		Device:      "mobile",
+		Next:        next,
+	}
+	if _, err := testGet(context.Background(), z); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last["geolocation"] != "CA" || last["ipType"] != "residential" || last["device"] != "mobile" {
+		t.Errorf("expected network options, got %v", last)
+	}
+	if _, err := z.Screenshot(context.Background(), "https://example.com/", ScreenshotOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last["geolocation"] != "CA" || last["ipType"] != "residential" || last["device"] != "mobile" {
+		t.Errorf("expected network options for screenshot, got %v", last)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	var last map[string]any
+	z := &Transport{
+		FollowRedirect: true,
+		Geolocation:    "CA",
+		Next: testAPI(t, func(zreq map[string]any) (int, string) {
+			last = zreq
+			if zreq["browserHtml"] == true {
+				return 200, "<html>"
+			}
+			return 200, "hello"
+		}),
+	}
+	yes, no := true, false
+
+	ctx := WithOptions(context.Background(), Options{Browser: &yes, IPType: "residential"})
+	if body, err := testGet(ctx, z); err != nil || body != "<html>" {
+		t.Errorf("unexpected response %q (error: %v)", body, err)
+	}
+	if last["browserHtml"] != true || last["ipType"] != "residential" || last["geolocation"] != "CA" {
+		t.Errorf("expected browser request, got %v", last)
+	}
+
+	ctx = WithOptions(ctx, Options{Browser: &no, FollowRedirect: &no})
+	if body, err := testGet(ctx, z); err != nil || body != "hello" {
+		t.Errorf("unexpected response %q (error: %v)", body, err)
+	}
+	if _, ok := last["browserHtml"]; ok || last["followRedirect"] != false || last["ipType"] != "residential" {
+		t.Errorf("expected plain request without redirects, got %v", last)
+	}
+}
+
+func TestOptionsTimeout(t *testing.T) {
+	z := &Transport{
+		Retry: func(ctx context.Context, tries, code int) bool {
+			return ctx.Err() == nil
+		},
+		Next: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			<-r.Context().Done()
+			return nil, r.Context().Err()
+		}),
+	}
+	ctx := WithOptions(context.Background(), Options{Timeout: time.Millisecond * 20})
+	if _, err := testGet(ctx, z); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	// the timeout should include retries
+	var tries int
+	z.Next = testAPI(t, func(zreq map[string]any) (int, string) {
+		tries++
+		time.Sleep(time.Millisecond * 5)
+		return 520, `{"type":"/download/temporary-error","title":"Temporary Downloading Error","status":520}`
+	})
+	if _, err := testGet(ctx, z); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if tries < 2 {
+		t.Errorf("expected the request to be retried until the timeout, got %d tries", tries)
+	}
+}
+
+func TestParallelism(t *testing.T) {
+	var active, peak atomic.Int32
+	next := testAPI(t, func(zreq map[string]any) (int, string) {
+		return 200, "hello"
+	})
+	z := &Transport{
+		Parallelism: 2,
+		Next: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			n := active.Add(1)
+			for {
+				if p := peak.Load(); n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			resp, err := next.RoundTrip(r)
+			if err != nil {
+				return nil, err
+			}
+			// the request is in progress until the body is consumed
+			resp.Body = &slowBody{Reader: resp.Body, close: func() { active.Add(-1) }}
+			return resp, nil
+		}),
+	}
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Go(func() {
+			if body, err := testGet(context.Background(), z); err != nil || body != "hello" {
+				t.Errorf("unexpected response %q (error: %v)", body, err)
+			}
+		})
+	}
+	wg.Wait()
+	if n := peak.Load(); n != 2 {
+		t.Errorf("expected at most 2 requests in progress, got %d", n)
+	}
+}
+
+func TestParallelismCancel(t *testing.T) {
+	var (
+		calls   atomic.Int32
+		started = make(chan struct{})
+		unblock = make(chan struct{})
+	)
+	next := testAPI(t, func(zreq map[string]any) (int, string) {
+		return 200, "hello"
+	})
+	z := &Transport{
+		Parallelism: 1,
+		Next: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if calls.Add(1) == 1 {
+				close(started)
+				<-unblock
+			}
+			return next.RoundTrip(r)
+		}),
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if body, err := testGet(context.Background(), z); err != nil || body != "hello" {
+			t.Errorf("unexpected response %q (error: %v)", body, err)
+		}
+	}()
+	<-started
+
+	// the second request should give up waiting for the slot
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	if _, err := testGet(ctx, z); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected canceled request not to be made, got %d calls", n)
+	}
+
+	// and the slot should still be usable
+	close(unblock)
+	<-done
+	if body, err := testGet(context.Background(), z); err != nil || body != "hello" {
+		t.Errorf("unexpected response %q (error: %v)", body, err)
+	}
+}
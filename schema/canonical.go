@@ -0,0 +1,83 @@
+package schema
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Canonicalize sorts pb in-place into a deterministic order and removes
+// identical duplicates, so snapshots of the same data compare equal regardless
+// of the order it was scraped in. Facilities are sorted by name and source url,
+// schedule groups by label, schedules by caption and date range, and
+// attribution, activity descriptions, and alerts by their text. Ties are broken
+// by the full serialized content. Activities and days within schedules are not
+// reordered since the rows and columns correspond to each other.
+func Canonicalize(pb *Data) {
+	slices.Sort(pb.GetAttribution())
+	pb.SetAttribution(slices.Compact(pb.GetAttribution()))
+	pb.SetFacilities(canonicalSort(pb.GetFacilities(), func(a, b *Facility) int {
+		return cmp.Or(
+			strings.Compare(a.GetName(), b.GetName()),
+			strings.Compare(a.GetSource().GetUrl(), b.GetSource().GetUrl()),
+		)
+	}))
+	for _, f := range pb.GetFacilities() {
+		f.SetScheduleGroups(canonicalSort(f.GetScheduleGroups(), func(a, b *ScheduleGroup) int {
+			return strings.Compare(a.GetLabel(), b.GetLabel())
+		}))
+		for _, g := range f.GetScheduleGroups() {
+			g.SetSchedules(canonicalSort(g.GetSchedules(), func(a, b *Schedule) int {
+				return cmp.Or(
+					strings.Compare(a.GetCaption(), b.GetCaption()),
+					Date(a.GetXFrom()).Compare(Date(b.GetXFrom())),
+					Date(a.GetXTo()).Compare(Date(b.GetXTo())),
+				)
+			}))
+		}
+	}
+	pb.SetActivities(canonicalSort(pb.GetActivities(), func(a, b *ActivityInfo) int {
+		return cmp.Or(
+			strings.Compare(a.GetXName(), b.GetXName()),
+			strings.Compare(a.GetSource().GetUrl(), b.GetSource().GetUrl()),
+		)
+	}))
+	pb.SetAlerts(canonicalSort(pb.GetAlerts(), func(a, b *Alert) int {
+		return cmp.Or(
+			strings.Compare(a.GetTitle(), b.GetTitle()),
+			strings.Compare(a.GetHtml(), b.GetHtml()),
+		)
+	}))
+}
+
+// canonicalSort sorts xs by fn, then by the serialized messages, removing
+// messages which serialize identically.
+func canonicalSort[M proto.Message](xs []M, fn func(a, b M) int) []M {
+	type entry struct {
+		m M
+		b []byte
+	}
+	es := make([]entry, len(xs))
+	for i, m := range xs {
+		buf, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+		if err != nil {
+			panic(fmt.Errorf("schema: canonicalize: %w", err)) // should never happen for a valid message
+		}
+		es[i] = entry{m, buf}
+	}
+	slices.SortFunc(es, func(a, b entry) int {
+		return cmp.Or(fn(a.m, b.m), bytes.Compare(a.b, b.b))
+	})
+	es = slices.CompactFunc(es, func(a, b entry) bool {
+		return bytes.Equal(a.b, b.b)
+	})
+	xs = xs[:0]
+	for _, e := range es {
+		xs = append(xs, e.m)
+	}
+	return xs
+}
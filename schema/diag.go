@@ -0,0 +1,100 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// DiagError makes an error diagnostic.
+func DiagError(stage Diagnostic_Stage, context, format string, a ...any) *Diagnostic {
+	return Diagnostic_builder{
+		Severity: Diagnostic_ERROR,
+		Stage:    stage,
+		Message:  fmt.Sprintf(format, a...),
+		Context:  context,
+	}.Build()
+}
+
+// DiagWarning makes a warning diagnostic.
+func DiagWarning(stage Diagnostic_Stage, context, format string, a ...any) *Diagnostic {
+	return Diagnostic_builder{
+		Severity: Diagnostic_WARNING,
+		Stage:    stage,
+		Message:  fmt.Sprintf(format, a...),
+		Context:  context,
+	}.Build()
+}
+
+// AppendDiagnostics appends the diagnostics to ds, skipping ones identical to
+// one which is already present.
+func AppendDiagnostics(ds []*Diagnostic, add ...*Diagnostic) []*Diagnostic {
+	for _, x := range add {
+		if !slices.ContainsFunc(ds, func(d *Diagnostic) bool {
+			return proto.Equal(d, x)
+		}) {
+			ds = append(ds, x)
+		}
+	}
+	return ds
+}
+
+// WithSeverity returns a new slice containing the diagnostics in ds with the
+// severity.
+func WithSeverity(ds []*Diagnostic, sev Diagnostic_Severity) []*Diagnostic {
+	var r []*Diagnostic
+	for _, d := range ds {
+		if d.GetSeverity() == sev {
+			r = append(r, d)
+		}
+	}
+	return r
+}
+
+// WithStage returns a new slice containing the diagnostics in ds with one of
+// the stages.
+func WithStage(ds []*Diagnostic, stages ...Diagnostic_Stage) []*Diagnostic {
+	var r []*Diagnostic
+	for _, d := range ds {
+		if slices.Contains(stages, d.GetStage()) {
+			r = append(r, d)
+		}
+	}
+	return r
+}
+
+// WithoutStage returns a new slice containing the diagnostics in ds without
+// any of the stages.
+func WithoutStage(ds []*Diagnostic, stages ...Diagnostic_Stage) []*Diagnostic {
+	var r []*Diagnostic
+	for _, d := range ds {
+		if !slices.Contains(stages, d.GetStage()) {
+			r = append(r, d)
+		}
+	}
+	return r
+}
+
+// Text renders d as a single line in the format of the old _errors strings,
+// i.e., the message prefixed by the context (if any), then "warning: " (if it's
+// a warning).
+func (d *Diagnostic) Text() string {
+	s := d.GetMessage()
+	if c := d.GetContext(); c != "" {
+		s = c + ": " + s
+	}
+	if d.GetSeverity() == Diagnostic_WARNING {
+		s = "warning: " + s
+	}
+	return s
+}
+
+// DiagnosticStrings renders each diagnostic in ds using Text.
+func DiagnosticStrings(ds []*Diagnostic) []string {
+	r := make([]string, len(ds))
+	for i, d := range ds {
+		r[i] = d.Text()
+	}
+	return r
+}
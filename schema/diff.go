@@ -0,0 +1,175 @@
+package schema
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind is the type of a Change.
+type ChangeKind int
+
+const (
+	FacilityAdded       ChangeKind = iota + 1 // facility only in the new data
+	FacilityRemoved                           // facility only in the old data
+	FieldChanged                              // facility name, address, or description changed
+	NotificationChanged                       // facility notifications or special hours changed
+	ScheduleAdded                             // schedule only in the new facility
+	ScheduleRemoved                           // schedule only in the old facility
+	ScheduleChanged                           // schedule time slots changed (followed by the slot changes)
+	TimeSlotAdded                             // time slot only in the new schedule
+	TimeSlotRemoved                           // time slot only in the old schedule
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case FacilityAdded:
+		return "FacilityAdded"
+	case FacilityRemoved:
+		return "FacilityRemoved"
+	case FieldChanged:
+		return "FieldChanged"
+	case NotificationChanged:
+		return "NotificationChanged"
+	case ScheduleAdded:
+		return "ScheduleAdded"
+	case ScheduleRemoved:
+		return "ScheduleRemoved"
+	case ScheduleChanged:
+		return "ScheduleChanged"
+	case TimeSlotAdded:
+		return "TimeSlotAdded"
+	case TimeSlotRemoved:
+		return "TimeSlotRemoved"
+	}
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Change is a single difference between two versions of the data.
+type Change struct {
+	Kind     ChangeKind
+	Facility *Facility // in the new data, or the old data if removed
+	Field    string    // field name (name, address, description, notifications, special hours), for field and notification changes
+	Old, New string    // field values, for field and notification changes
+	Schedule string    // schedule key (group label > caption), for schedule and time slot changes
+	Slot     string    // time slot (activity label: day time label), for time slot changes
+}
+
+// Diff compares two versions of the data, returning the changes from prev to
+// cur in order. Facilities are matched by stable id (or name if they don't
+// have a source url), schedules by group label and caption, and time slots by
+// activity label, day, and time label. Removed facilities come first in prev
+// order, then the other changes in cur order.
+func Diff(prev, cur *Data) []Change {
+	var changes []Change
+	prevIdx := diffIndex(prev.GetFacilities(), diffFacilityKey)
+	curIdx := diffIndex(cur.GetFacilities(), diffFacilityKey)
+	for _, f := range prev.GetFacilities() {
+		if _, ok := curIdx[diffFacilityKey(f)]; !ok {
+			changes = append(changes, Change{Kind: FacilityRemoved, Facility: f})
+		}
+	}
+	for _, f := range cur.GetFacilities() {
+		o, ok := prevIdx[diffFacilityKey(f)]
+		if !ok {
+			changes = append(changes, Change{Kind: FacilityAdded, Facility: f})
+			continue
+		}
+		changes = append(changes, diffFacility(o, f)...)
+	}
+	return changes
+}
+
+func diffFacility(a, b *Facility) []Change {
+	var changes []Change
+	for _, x := range []struct {
+		kind ChangeKind
+		name string
+		a, b string
+	}{
+		{FieldChanged, "name", a.GetName(), b.GetName()},
+		{FieldChanged, "address", a.GetAddress(), b.GetAddress()},
+		{FieldChanged, "description", a.GetDescription(), b.GetDescription()},
+		{NotificationChanged, "notifications", a.GetNotificationsHtml(), b.GetNotificationsHtml()},
+		{NotificationChanged, "special hours", a.GetSpecialHoursHtml(), b.GetSpecialHoursHtml()},
+	} {
+		if x.a != x.b {
+			changes = append(changes, Change{Kind: x.kind, Facility: b, Field: x.name, Old: x.a, New: x.b})
+		}
+	}
+	old := diffSchedules(a)
+	cur := diffSchedules(b)
+	for _, k := range cur.keys {
+		if _, ok := old.slots[k]; !ok {
+			changes = append(changes, Change{Kind: ScheduleAdded, Facility: b, Schedule: k})
+		}
+	}
+	for _, k := range old.keys {
+		if _, ok := cur.slots[k]; !ok {
+			changes = append(changes, Change{Kind: ScheduleRemoved, Facility: b, Schedule: k})
+		}
+	}
+	for _, k := range cur.keys {
+		o, ok := old.slots[k]
+		if !ok {
+			continue
+		}
+		var slots []Change
+		for _, x := range cur.slots[k] {
+			if !slices.Contains(o, x) {
+				slots = append(slots, Change{Kind: TimeSlotAdded, Facility: b, Schedule: k, Slot: x})
+			}
+		}
+		for _, x := range o {
+			if !slices.Contains(cur.slots[k], x) {
+				slots = append(slots, Change{Kind: TimeSlotRemoved, Facility: b, Schedule: k, Slot: x})
+			}
+		}
+		if len(slots) != 0 {
+			changes = append(changes, Change{Kind: ScheduleChanged, Facility: b, Schedule: k})
+			changes = append(changes, slots...)
+		}
+	}
+	return changes
+}
+
+type diffScheduleSet struct {
+	keys  []string            // "group > caption", in order
+	slots map[string][]string // "activity: day time"
+}
+
+func diffSchedules(f *Facility) diffScheduleSet {
+	set := diffScheduleSet{slots: map[string][]string{}}
+	for x := range f.AllSchedules() {
+		if k := x.Group.GetLabel() + " > " + x.Schedule.GetCaption(); !slices.Contains(set.keys, k) {
+			set.keys = append(set.keys, k)
+			set.slots[k] = []string{}
+		}
+	}
+	for x := range f.AllTimeRanges() {
+		k := x.Group.GetLabel() + " > " + x.Schedule.GetCaption()
+		slot := strings.TrimSpace(x.Activity.GetLabel() + ": " + x.Day + " " + x.Time.GetLabel())
+		if !slices.Contains(set.slots[k], slot) {
+			set.slots[k] = append(set.slots[k], slot)
+		}
+	}
+	return set
+}
+
+func diffFacilityKey(f *Facility) string {
+	if id := cmp.Or(f.GetXId(), FacilityID(f.GetSource().GetUrl())); id != "" {
+		return "id:" + id
+	}
+	return "name:" + f.GetName()
+}
+
+func diffIndex[T any](xs []T, key func(T) string) map[string]T {
+	m := make(map[string]T, len(xs))
+	for _, x := range xs {
+		if _, ok := m[key(x)]; !ok {
+			m[key(x)] = x
+		}
+	}
+	return m
+}
@@ -0,0 +1,109 @@
+package schema
+
+import (
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Filter selects a subset of the data using predicates, each of which is
+// ignored if nil.
+type Filter struct {
+	// Facility selects facilities.
+	Facility func(f *Facility) bool
+
+	// Activity selects schedule activities in the selected facilities.
+	Activity func(a *Schedule_Activity) bool
+
+	// TimeRange selects time ranges in the selected activities.
+	TimeRange func(t *TimeRange) bool
+}
+
+// Apply returns a deep copy of pb with only the selected facilities and time
+// ranges. Activities, schedules, schedule groups, and facilities left without
+// any time ranges are removed if an Activity or TimeRange predicate is set.
+// Activity days are kept so they still correspond to the schedule days.
+func (fl Filter) Apply(pb *Data) *Data {
+	pb = DeepClone(pb)
+	slots := fl.Activity != nil || fl.TimeRange != nil
+	pb.SetFacilities(slices.DeleteFunc(pb.GetFacilities(), func(f *Facility) bool {
+		if fl.Facility != nil && !fl.Facility(f) {
+			return true
+		}
+		if !slots {
+			return false
+		}
+		f.SetScheduleGroups(slices.DeleteFunc(f.GetScheduleGroups(), func(g *ScheduleGroup) bool {
+			g.SetSchedules(slices.DeleteFunc(g.GetSchedules(), func(s *Schedule) bool {
+				s.SetActivities(slices.DeleteFunc(s.GetActivities(), func(a *Schedule_Activity) bool {
+					if fl.Activity != nil && !fl.Activity(a) {
+						return true
+					}
+					var n int
+					for _, d := range a.GetDays() {
+						if fl.TimeRange != nil {
+							d.SetTimes(slices.DeleteFunc(d.GetTimes(), func(t *TimeRange) bool {
+								return !fl.TimeRange(t)
+							}))
+						}
+						n += len(d.GetTimes())
+					}
+					return n == 0
+				}))
+				return len(s.GetActivities()) == 0
+			}))
+			return len(g.GetSchedules()) == 0
+		}))
+		return len(f.GetScheduleGroups()) == 0
+	}))
+	return pb
+}
+
+// DeepClone returns a deep copy of pb, which can be modified without affecting
+// the original.
+func DeepClone(pb *Data) *Data {
+	return proto.CloneOf(pb)
+}
+
+// Merge combines multiple snapshots into a new one. Attribution, activities,
+// and alerts are deduplicated (activities by normalized name and source url,
+// and alerts by title and html), and activities are sorted by name.
+// Facilities are copied in order without being deduplicated, since whether two
+// facilities are the same is up to the caller (e.g., by stable id). The
+// inputs must already be migrated to SchemaVersion (e.g., by Unmarshal), and
+// the result has it set. The metadata isn't kept since it describes a single
+// scrape, so the caller must set it if the result is from a new one.
+func Merge(pbs ...*Data) *Data {
+	data := Data_builder{
+		SchemaVersion: SchemaVersion,
+	}
+	for _, pb := range pbs {
+		for _, x := range pb.GetAttribution() {
+			if !slices.Contains(data.Attribution, x) {
+				data.Attribution = append(data.Attribution, x)
+			}
+		}
+		for _, x := range pb.GetActivities() {
+			if !slices.ContainsFunc(data.Activities, func(o *ActivityInfo) bool {
+				return o.GetXName() == x.GetXName() && o.GetSource().GetUrl() == x.GetSource().GetUrl()
+			}) {
+				data.Activities = append(data.Activities, proto.CloneOf(x))
+			}
+		}
+		for _, x := range pb.GetAlerts() {
+			if !slices.ContainsFunc(data.Alerts, func(o *Alert) bool {
+				return o.GetTitle() == x.GetTitle() && o.GetHtml() == x.GetHtml()
+			}) {
+				data.Alerts = append(data.Alerts, proto.CloneOf(x))
+			}
+		}
+		for _, f := range pb.GetFacilities() {
+			data.Facilities = append(data.Facilities, proto.CloneOf(f))
+		}
+	}
+	slices.SortStableFunc(data.Activities, func(a, b *ActivityInfo) int {
+		return strings.Compare(a.GetXName(), b.GetXName())
+	})
+	return data.Build()
+}
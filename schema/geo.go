@@ -0,0 +1,168 @@
+package schema
+
+import (
+	"math"
+	"strings"
+)
+
+// earthRadius is the mean radius of the earth in metres.
+const earthRadius = 6371e3
+
+// DistanceTo returns the great-circle distance between ll and o in metres,
+// using the haversine formula.
+func (ll *LngLat) DistanceTo(o *LngLat) float64 {
+	lat1 := float64(ll.GetLat()) * math.Pi / 180
+	lat2 := float64(o.GetLat()) * math.Pi / 180
+	dlat := lat2 - lat1
+	dlng := float64(o.GetLng()-ll.GetLng()) * math.Pi / 180
+	h := math.Sin(dlat/2)*math.Sin(dlat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dlng/2)*math.Sin(dlng/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(min(h, 1)))
+}
+
+// Within checks whether ll is inside b.
+func (ll *LngLat) Within(b GeoBounds) bool {
+	return b.Contains(ll)
+}
+
+// GeoBounds is a bounding box in degrees. It does not wrap around the
+// antimeridian.
+type GeoBounds struct {
+	MinLng, MinLat float64
+	MaxLng, MaxLat float64
+}
+
+// BoundsAround returns the smallest bounding box containing all points within
+// radius metres of center, which is useful for quickly filtering points before
+// checking DistanceTo.
+func BoundsAround(center *LngLat, radius float64) GeoBounds {
+	lat := float64(center.GetLat())
+	lng := float64(center.GetLng())
+	dlat := radius / earthRadius * 180 / math.Pi
+	dlng := 180.0
+	if c := math.Cos(lat * math.Pi / 180); c > 0 {
+		dlng = min(dlat/c, 180)
+	}
+	return GeoBounds{
+		MinLng: max(lng-dlng, -180),
+		MinLat: max(lat-dlat, -90),
+		MaxLng: min(lng+dlng, 180),
+		MaxLat: min(lat+dlat, 90),
+	}
+}
+
+// BoundsOf returns the smallest bounding box containing the points, ignoring
+// nil ones. If there aren't any, it returns the zero GeoBounds and false.
+func BoundsOf(points ...*LngLat) (GeoBounds, bool) {
+	var (
+		b  GeoBounds
+		ok bool
+	)
+	for _, ll := range points {
+		if ll == nil {
+			continue
+		}
+		lng, lat := float64(ll.GetLng()), float64(ll.GetLat())
+		if !ok {
+			b, ok = GeoBounds{lng, lat, lng, lat}, true
+			continue
+		}
+		b.MinLng, b.MaxLng = min(b.MinLng, lng), max(b.MaxLng, lng)
+		b.MinLat, b.MaxLat = min(b.MinLat, lat), max(b.MaxLat, lat)
+	}
+	return b, ok
+}
+
+// Contains checks whether ll is inside b (inclusive). It returns false if ll is
+// nil.
+func (b GeoBounds) Contains(ll *LngLat) bool {
+	if ll == nil {
+		return false
+	}
+	lng, lat := float64(ll.GetLng()), float64(ll.GetLat())
+	return lng >= b.MinLng && lng <= b.MaxLng && lat >= b.MinLat && lat <= b.MaxLat
+}
+
+// Center returns the midpoint of b.
+func (b GeoBounds) Center() *LngLat {
+	return LngLat_builder{
+		Lng: float32((b.MinLng + b.MaxLng) / 2),
+		Lat: float32((b.MinLat + b.MaxLat) / 2),
+	}.Build()
+}
+
+// geohashAlphabet is the base32 alphabet used by geohashes.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Geohash encodes ll as a geohash with precision characters (1-12). Points
+// sharing a geohash prefix are near each other, so truncated geohashes can be
+// used to group points into cells without a spatial index.
+func (ll *LngLat) Geohash(precision int) string {
+	precision = min(max(precision, 1), 12)
+	var (
+		cell = GeoBounds{-180, -90, 180, 90}
+		lng  = float64(ll.GetLng())
+		lat  = float64(ll.GetLat())
+		even = true // bits alternate between longitude and latitude
+		hash = make([]byte, precision)
+	)
+	for i := range hash {
+		var ch int
+		for range 5 {
+			lo, hi, v := &cell.MinLat, &cell.MaxLat, lat
+			if even {
+				lo, hi, v = &cell.MinLng, &cell.MaxLng, lng
+			}
+			ch <<= 1
+			if mid := (*lo + *hi) / 2; v >= mid {
+				ch |= 1
+				*lo = mid
+			} else {
+				*hi = mid
+			}
+			even = !even
+		}
+		hash[i] = geohashAlphabet[ch]
+	}
+	return string(hash)
+}
+
+// GeohashBounds decodes a geohash (case-insensitive) into the bounds of the
+// cell it represents. It returns false if the geohash is empty or invalid.
+func GeohashBounds(hash string) (GeoBounds, bool) {
+	if hash == "" {
+		return GeoBounds{}, false
+	}
+	var (
+		cell = GeoBounds{-180, -90, 180, 90}
+		even = true
+	)
+	for _, c := range strings.ToLower(hash) {
+		ch := strings.IndexRune(geohashAlphabet, c)
+		if ch < 0 {
+			return GeoBounds{}, false
+		}
+		for i := 4; i >= 0; i-- {
+			lo, hi := &cell.MinLat, &cell.MaxLat
+			if even {
+				lo, hi = &cell.MinLng, &cell.MaxLng
+			}
+			if mid := (*lo + *hi) / 2; ch>>i&1 == 1 {
+				*lo = mid
+			} else {
+				*hi = mid
+			}
+			even = !even
+		}
+	}
+	return cell, true
+}
+
+// GeohashDecode decodes a geohash into the center of the cell it represents.
+// It returns nil if the geohash is empty or invalid.
+func GeohashDecode(hash string) *LngLat {
+	b, ok := GeohashBounds(hash)
+	if !ok {
+		return nil
+	}
+	return b.Center()
+}
@@ -0,0 +1,153 @@
+package schema
+
+import (
+	"strings"
+	"time"
+)
+
+// Holiday is a public holiday observed by the City of Ottawa.
+type Holiday struct {
+	Name      string // english name
+	Statutory bool   // whether it's an ontario statutory holiday
+	names     []string
+	date      func(year int) (time.Month, int)
+}
+
+var holidays = []*Holiday{
+	{"New Year's Day", true, []string{"new year's day", "new years day", "jour de l'an"}, fixedDate(time.January, 1)},
+	{"Family Day", true, []string{"family day", "jour de la famille"}, nthWeekday(time.February, time.Monday, 3)},
+	{"Good Friday", true, []string{"good friday", "vendredi saint"}, func(year int) (time.Month, int) {
+		t := easter(year).AddDate(0, 0, -2)
+		return t.Month(), t.Day()
+	}},
+	{"Victoria Day", true, []string{"victoria day", "fête de la reine", "fête de la reine victoria", "journée nationale des patriotes"}, func(year int) (time.Month, int) {
+		t := time.Date(year, time.May, 24, 0, 0, 0, 0, time.UTC) // monday on or before may 24
+		return t.Month(), t.Day() - (int(t.Weekday())+6)%7
+	}},
+	{"Canada Day", true, []string{"canada day", "fête du canada"}, fixedDate(time.July, 1)},
+	{"Civic Holiday", false, []string{"civic holiday", "congé civique"}, nthWeekday(time.August, time.Monday, 1)},
+	{"Labour Day", true, []string{"labour day", "labor day", "fête du travail"}, nthWeekday(time.September, time.Monday, 1)},
+	{"Thanksgiving", true, []string{"thanksgiving", "action de grâce", "action de grâces"}, nthWeekday(time.October, time.Monday, 2)},
+	{"Christmas Day", true, []string{"christmas day", "jour de noël"}, fixedDate(time.December, 25)},
+	{"Boxing Day", true, []string{"boxing day", "lendemain de noël"}, fixedDate(time.December, 26)},
+}
+
+func fixedDate(month time.Month, day int) func(int) (time.Month, int) {
+	return func(int) (time.Month, int) {
+		return month, day
+	}
+}
+
+func nthWeekday(month time.Month, wkday time.Weekday, n int) func(int) (time.Month, int) {
+	return func(year int) (time.Month, int) {
+		first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+		return month, 1 + (int(wkday)-int(first)+7)%7 + (n-1)*7
+	}
+}
+
+// easter returns the date of easter sunday in the gregorian calendar.
+func easter(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// Holidays returns all holidays observed by the City of Ottawa in the order
+// they occur in a year. The returned values must not be modified.
+func Holidays() []*Holiday {
+	return holidays
+}
+
+// Date returns the date of the holiday in the specified year.
+func (h *Holiday) Date(year int) Date {
+	month, day := h.date(year)
+	return MakeDate(year, month, day, time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday())
+}
+
+// Nearest returns the date of the next occurrence of the holiday on or after t,
+// or of the previous one if it was at most a week before t. Schedules for a
+// holiday are usually posted shortly before it and taken down shortly after,
+// so this is more likely to be correct than the closest one (e.g., Thanksgiving
+// in January is the upcoming one, not the one three months ago).
+func (h *Holiday) Nearest(t time.Time) Date {
+	after := time.Date(t.Year(), t.Month(), t.Day()-7, 0, 0, 0, 0, t.Location())
+	for year := t.Year() - 1; ; year++ {
+		month, day := h.date(year)
+		if !time.Date(year, month, day, 0, 0, 0, 0, t.Location()).Before(after) {
+			return h.Date(year)
+		}
+	}
+}
+
+// HolidayOn returns the holiday on the specified date, if any.
+func HolidayOn(year int, month time.Month, day int) (*Holiday, bool) {
+	for _, h := range holidays {
+		if m, d := h.date(year); m == month && d == day {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+// ParseHoliday parses the name of a holiday (in english or french).
+func ParseHoliday(s string) (*Holiday, bool) {
+	s = normalizeHolidayName(s)
+	for _, h := range holidays {
+		for _, n := range h.names {
+			if s == n {
+				return h, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// FindHoliday returns the first holiday referenced by name (in english or
+// french) in s, if any.
+func FindHoliday(s string) (*Holiday, bool) {
+	s = normalizeHolidayName(s)
+	var (
+		hol *Holiday
+		idx = -1
+	)
+	for _, h := range holidays {
+		for _, n := range h.names {
+			if i := indexWord(s, n); i != -1 && (idx == -1 || i < idx) {
+				hol, idx = h, i
+			}
+		}
+	}
+	return hol, hol != nil
+}
+
+func normalizeHolidayName(s string) string {
+	return strings.Join(strings.Fields(strings.NewReplacer("’", "'", "‘", "'").Replace(strings.ToLower(s))), " ")
+}
+
+// indexWord is like [strings.Index], but only matches whole words.
+func indexWord(s, word string) int {
+	for off := 0; ; {
+		i := strings.Index(s[off:], word)
+		if i == -1 {
+			return -1
+		}
+		i += off
+		if j := i + len(word); (i == 0 || !isWordByte(s[i-1])) && (j == len(s) || !isWordByte(s[j])) {
+			return i
+		}
+		off = i + 1
+	}
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c >= 0x80
+}
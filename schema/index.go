@@ -0,0 +1,175 @@
+package schema
+
+import (
+	"cmp"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Index contains lookup tables over Data. It must be rebuilt if the data is
+// modified.
+type Index struct {
+	data       *Data
+	names      map[string]*Facility
+	slugs      map[string]*Facility
+	ids        map[string]*Facility
+	facSlugs   map[*Facility]string
+	activities map[string][]ActivityRef
+	info       map[string]*ActivityInfo
+}
+
+// ActivityRef is an activity in the schedule it's in.
+type ActivityRef struct {
+	Facility *Facility
+	Group    *ScheduleGroup
+	Schedule *Schedule
+	Activity *Schedule_Activity
+}
+
+// ScheduleRef is a schedule in the group and facility it's in.
+type ScheduleRef struct {
+	Facility *Facility
+	Group    *ScheduleGroup
+	Schedule *Schedule
+}
+
+// NewIndex builds an index over pb.
+func NewIndex(pb *Data) *Index {
+	x := &Index{
+		data:       pb,
+		names:      map[string]*Facility{},
+		slugs:      map[string]*Facility{},
+		ids:        map[string]*Facility{},
+		facSlugs:   map[*Facility]string{},
+		activities: map[string][]ActivityRef{},
+		info:       map[string]*ActivityInfo{},
+	}
+	for _, f := range pb.GetFacilities() {
+		for _, name := range append([]string{f.GetName()}, f.GetXAliases()...) {
+			if k := strings.ToLower(name); k != "" {
+				if _, ok := x.names[k]; !ok {
+					x.names[k] = f
+				}
+			}
+		}
+		if id := f.GetXId(); id != "" {
+			if _, ok := x.ids[id]; !ok {
+				x.ids[id] = f
+			}
+		}
+		base := Slug(f.GetName())
+		slug := base
+		for i := 2; x.slugs[slug] != nil; i++ {
+			slug = base + "-" + strconv.Itoa(i)
+		}
+		x.slugs[slug] = f
+		x.facSlugs[f] = slug
+		for a := range f.AllActivities() {
+			if name := a.Activity.GetXName(); name != "" {
+				x.activities[name] = append(x.activities[name], a)
+			}
+		}
+	}
+	for _, a := range pb.GetActivities() {
+		if name := a.GetXName(); name != "" {
+			if _, ok := x.info[name]; !ok {
+				x.info[name] = a
+			}
+		}
+	}
+	return x
+}
+
+// Facility finds a facility by its name or one of its aliases,
+// case-insensitively. If multiple facilities match, the first one is returned.
+func (x *Index) Facility(name string) (*Facility, bool) {
+	f, ok := x.names[strings.ToLower(name)]
+	return f, ok
+}
+
+// FacilityBySlug finds a facility by its slug (see FacilitySlug).
+func (x *Index) FacilityBySlug(slug string) (*Facility, bool) {
+	f, ok := x.slugs[slug]
+	return f, ok
+}
+
+// FacilityByID finds a facility by its stable identifier (see FacilityID).
+func (x *Index) FacilityByID(id string) (*Facility, bool) {
+	f, ok := x.ids[id]
+	return f, ok
+}
+
+// FacilitySlug returns the unique slug for a facility in the index, which is
+// the Slug of its name, suffixed with a number if it conflicts with an earlier
+// facility.
+func (x *Index) FacilitySlug(f *Facility) (string, bool) {
+	slug, ok := x.facSlugs[f]
+	return slug, ok
+}
+
+// Activities returns the activities with the normalized name (see
+// Schedule.Activity._name), in data order.
+func (x *Index) Activities(name string) []ActivityRef {
+	return x.activities[name]
+}
+
+// ActivityInfo returns the description of the activity with the normalized
+// name.
+func (x *Index) ActivityInfo(name string) (*ActivityInfo, bool) {
+	a, ok := x.info[name]
+	return a, ok
+}
+
+// SchedulesOn returns the schedules which may be in effect on the date of t,
+// in data order. Schedules are included if their parsed date range contains
+// the date (unknown sides are unbounded), and holiday schedules are only
+// included on that holiday.
+func (x *Index) SchedulesOn(t time.Time) []ScheduleRef {
+	var (
+		refs       []ScheduleRef
+		d          = DateOf(t)
+		hol, isHol = HolidayOn(t.Year(), t.Month(), t.Day())
+	)
+	for r := range x.data.AllSchedules() {
+		if name := r.Schedule.GetXHoliday(); name != "" && (!isHol || hol.Name != name) {
+			continue
+		}
+		if !(DateRange{Date(r.Schedule.GetXFrom()), Date(r.Schedule.GetXTo())}).Contains(d) {
+			continue
+		}
+		refs = append(refs, r)
+	}
+	return refs
+}
+
+// Slug makes a url-safe identifier from s by lowercasing it and joining the
+// letters and digits with dashes. It returns "page" if s doesn't contain any.
+func Slug(s string) string {
+	return cmp.Or(slug(s), "page")
+}
+
+func slug(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(norm.NFKC.String(s)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "-")
+}
+
+// FacilityID computes the stable identifier for a facility from its source url,
+// which is the Slug of the last path segment (e.g., "routhier-community-centre"
+// for https://ottawa.ca/en/recreation-and-parks/facilities/place-listing/routhier-community-centre).
+// Unlike the name, it doesn't change when the facility is renamed on the listing,
+// and unlike the url, it doesn't depend on the scheme, host, or query string. It
+// returns an empty string if the url can't be parsed or doesn't have a path.
+func FacilityID(u string) string {
+	p, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+	return slug(path.Base(path.Clean("/" + p.Path)))
+}
@@ -0,0 +1,104 @@
+package schema
+
+import "iter"
+
+// TimeRangeRef is a time range in the activity, schedule, group, and facility
+// it's in.
+type TimeRangeRef struct {
+	Facility *Facility
+	Group    *ScheduleGroup
+	Schedule *Schedule
+	Activity *Schedule_Activity
+	DayIndex int    // index of the day column
+	Day      string // raw day column header, empty if missing
+	Time     *TimeRange
+}
+
+// AllSchedules iterates over the schedules in all facilities, in data order.
+func (pb *Data) AllSchedules() iter.Seq[ScheduleRef] {
+	return func(yield func(ScheduleRef) bool) {
+		for _, f := range pb.GetFacilities() {
+			for x := range f.AllSchedules() {
+				if !yield(x) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// AllActivities iterates over the schedule activities in all facilities, in
+// data order.
+func (pb *Data) AllActivities() iter.Seq[ActivityRef] {
+	return func(yield func(ActivityRef) bool) {
+		for _, f := range pb.GetFacilities() {
+			for x := range f.AllActivities() {
+				if !yield(x) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// AllTimeRanges iterates over the time ranges in all facilities, in data
+// order.
+func (pb *Data) AllTimeRanges() iter.Seq[TimeRangeRef] {
+	return func(yield func(TimeRangeRef) bool) {
+		for _, f := range pb.GetFacilities() {
+			for x := range f.AllTimeRanges() {
+				if !yield(x) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// AllSchedules iterates over the schedules in the facility, in data order.
+func (f *Facility) AllSchedules() iter.Seq[ScheduleRef] {
+	return func(yield func(ScheduleRef) bool) {
+		for _, g := range f.GetScheduleGroups() {
+			for _, s := range g.GetSchedules() {
+				if !yield(ScheduleRef{f, g, s}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// AllActivities iterates over the schedule activities in the facility, in data
+// order.
+func (f *Facility) AllActivities() iter.Seq[ActivityRef] {
+	return func(yield func(ActivityRef) bool) {
+		for x := range f.AllSchedules() {
+			for _, a := range x.Schedule.GetActivities() {
+				if !yield(ActivityRef{x.Facility, x.Group, x.Schedule, a}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// AllTimeRanges iterates over the time ranges in the facility, in data order
+// (i.e., by activity, then by day column).
+func (f *Facility) AllTimeRanges() iter.Seq[TimeRangeRef] {
+	return func(yield func(TimeRangeRef) bool) {
+		for x := range f.AllActivities() {
+			days := x.Schedule.GetDays()
+			for i, d := range x.Activity.GetDays() {
+				var day string
+				if i < len(days) {
+					day = days[i]
+				}
+				for _, t := range d.GetTimes() {
+					if !yield(TimeRangeRef{x.Facility, x.Group, x.Schedule, x.Activity, i, day, t}) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package schema
+
+import "time"
+
+// SetCounts sets the facility, schedule, time range, and diagnostic counts in
+// m from pb.
+func (m *Meta) SetCounts(pb *Data) {
+	var facilities, schedules, timeRanges, errors, warnings int32
+	count := func(ds []*Diagnostic) {
+		for _, d := range ds {
+			switch d.GetSeverity() {
+			case Diagnostic_ERROR:
+				errors++
+			case Diagnostic_WARNING:
+				warnings++
+			}
+		}
+	}
+	for _, f := range pb.GetFacilities() {
+		facilities++
+		count(f.GetXDiagnostics())
+		for _, g := range f.GetScheduleGroups() {
+			for _, s := range g.GetSchedules() {
+				schedules++
+				for _, a := range s.GetActivities() {
+					for _, d := range a.GetDays() {
+						timeRanges += int32(len(d.GetTimes()))
+					}
+				}
+			}
+		}
+	}
+	for _, a := range pb.GetActivities() {
+		count(a.GetXDiagnostics())
+	}
+	m.SetFacilities(facilities)
+	m.SetSchedules(schedules)
+	m.SetTimeRanges(timeRanges)
+	m.SetErrors(errors)
+	m.SetWarnings(warnings)
+}
+
+// Duration returns how long the scrape took. It returns false if the start or
+// finish time isn't set.
+func (m *Meta) Duration() (time.Duration, bool) {
+	if !m.HasStarted() || !m.HasFinished() {
+		return 0, false
+	}
+	return m.GetFinished().AsTime().Sub(m.GetStarted().AsTime()), true
+}
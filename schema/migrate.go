@@ -0,0 +1,199 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SchemaVersion is the current schema version. It must be incremented, with a
+// migration added, whenever a change is made which older snapshots need to be
+// upgraded for (e.g., when a field is replaced).
+const SchemaVersion = 1
+
+// migrations[v] upgrades data from schema version v to v+1 in-place.
+var migrations = [SchemaVersion]func(pb *Data){
+	migrateDiagnostics,
+}
+
+// Migrate upgrades pb in-place from the schema version it was produced with to
+// SchemaVersion. It returns an error if pb is from a newer version.
+func Migrate(pb *Data) error {
+	v := pb.GetSchemaVersion()
+	if v < 0 || v > SchemaVersion {
+		return fmt.Errorf("unsupported schema version %d (current is %d)", v, SchemaVersion)
+	}
+	for ; v < SchemaVersion; v++ {
+		migrations[v](pb)
+	}
+	pb.SetSchemaVersion(SchemaVersion)
+	return nil
+}
+
+// Unmarshal decodes a snapshot in the format (binpb, json, or textpb), then
+// migrates it to SchemaVersion. Fields which were removed from the schema are
+// preserved where a migration needs them, except in textpb where they are
+// discarded.
+func Unmarshal(buf []byte, format string) (*Data, error) {
+	var pb Data
+	switch format {
+	case "binpb":
+		if err := proto.Unmarshal(buf, &pb); err != nil {
+			return nil, fmt.Errorf("unmarshal binpb: %w", err)
+		}
+	case "json":
+		buf, removed, err := extractJSONErrors(buf)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal json: %w", err)
+		}
+		if err := protojson.Unmarshal(buf, &pb); err != nil {
+			return nil, fmt.Errorf("unmarshal json: %w", err)
+		}
+		for i, f := range pb.GetFacilities() {
+			appendUnknownStrings(f, facilityErrorsField, removed["facilities"][i])
+		}
+		for i, a := range pb.GetActivities() {
+			appendUnknownStrings(a, activityErrorsField, removed["activities"][i])
+		}
+	case "textpb":
+		if err := prototext.Unmarshal(buf, &pb); err != nil {
+			return nil, fmt.Errorf("unmarshal textpb: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	if err := Migrate(&pb); err != nil {
+		return nil, err
+	}
+	return &pb, nil
+}
+
+// Field numbers of the _errors strings replaced by _diagnostics in version 1.
+const (
+	facilityErrorsField protowire.Number = 9
+	activityErrorsField protowire.Number = 5
+)
+
+// migrateDiagnostics converts the _errors strings (which are now unknown
+// fields) to diagnostics, and sets the facility _id.
+func migrateDiagnostics(pb *Data) {
+	for _, f := range pb.GetFacilities() {
+		for _, s := range takeUnknownStrings(f, facilityErrorsField) {
+			f.SetXDiagnostics(append(f.GetXDiagnostics(), legacyDiagnostic(s)))
+		}
+		if f.GetXId() == "" {
+			f.SetXId(FacilityID(f.GetSource().GetUrl()))
+		}
+	}
+	for _, a := range pb.GetActivities() {
+		for _, s := range takeUnknownStrings(a, activityErrorsField) {
+			a.SetXDiagnostics(append(a.GetXDiagnostics(), legacyDiagnostic(s)))
+		}
+	}
+}
+
+// legacyDiagnostic converts an _errors string into a diagnostic, guessing the
+// stage from the message.
+func legacyDiagnostic(s string) *Diagnostic {
+	var b Diagnostic_builder
+	if x, ok := strings.CutPrefix(s, "warning: "); ok {
+		b.Severity, s = Diagnostic_WARNING, x
+	}
+	switch {
+	case strings.HasPrefix(s, "failed to fetch"):
+		b.Stage = Diagnostic_FETCH
+	case strings.HasPrefix(s, "failed to resolve address"):
+		b.Stage = Diagnostic_GEOCODE
+	}
+	b.Message = s
+	return b.Build()
+}
+
+// takeUnknownStrings removes and returns the length-delimited unknown fields
+// with the field number from m.
+func takeUnknownStrings(m proto.Message, num protowire.Number) []string {
+	var (
+		r     = m.ProtoReflect()
+		buf   = r.GetUnknown()
+		rest  protoreflect.RawFields
+		found []string
+	)
+	for len(buf) > 0 {
+		n, typ, tlen := protowire.ConsumeTag(buf)
+		if tlen < 0 {
+			return found // leave the rest of the malformed fields as-is
+		}
+		vlen := protowire.ConsumeFieldValue(n, typ, buf[tlen:])
+		if vlen < 0 {
+			return found
+		}
+		if n == num && typ == protowire.BytesType {
+			v, _ := protowire.ConsumeString(buf[tlen:])
+			found = append(found, v)
+		} else {
+			rest = append(rest, buf[:tlen+vlen]...)
+		}
+		buf = buf[tlen+vlen:]
+	}
+	r.SetUnknown(rest)
+	return found
+}
+
+// appendUnknownStrings adds length-delimited unknown fields with the field
+// number to m.
+func appendUnknownStrings(m proto.Message, num protowire.Number, vs []string) {
+	if len(vs) == 0 {
+		return
+	}
+	r := m.ProtoReflect()
+	buf := r.GetUnknown()
+	for _, v := range vs {
+		buf = protowire.AppendTag(buf, num, protowire.BytesType)
+		buf = protowire.AppendString(buf, v)
+	}
+	r.SetUnknown(buf)
+}
+
+// extractJSONErrors removes the _errors arrays from the facilities and
+// activities in a json snapshot, since protojson doesn't allow unknown fields,
+// returning them by top-level field and index.
+func extractJSONErrors(buf []byte) ([]byte, map[string]map[int][]string, error) {
+	if !bytes.Contains(buf, []byte(`"_errors"`)) {
+		return buf, nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return nil, nil, err
+	}
+	removed := map[string]map[int][]string{}
+	for _, k := range []string{"facilities", "activities"} {
+		xs, _ := obj[k].([]any)
+		for i, x := range xs {
+			x, _ := x.(map[string]any)
+			errs, ok := x["_errors"].([]any)
+			if !ok {
+				continue
+			}
+			delete(x, "_errors")
+			for _, e := range errs {
+				if s, ok := e.(string); ok {
+					if removed[k] == nil {
+						removed[k] = map[int][]string{}
+					}
+					removed[k][i] = append(removed[k][i], s)
+				}
+			}
+		}
+	}
+	buf, err := json.Marshal(obj)
+	return buf, removed, err
+}
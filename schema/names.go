@@ -0,0 +1,189 @@
+package schema
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// frenchMonths maps lowercase french month names and abbreviations to months.
+var frenchMonths = map[string]time.Month{
+	"janvier":   time.January,
+	"janv":      time.January,
+	"février":   time.February,
+	"fevrier":   time.February,
+	"févr":      time.February,
+	"fevr":      time.February,
+	"mars":      time.March,
+	"avril":     time.April,
+	"avr":       time.April,
+	"mai":       time.May,
+	"juin":      time.June,
+	"juillet":   time.July,
+	"juil":      time.July,
+	"août":      time.August,
+	"aout":      time.August,
+	"septembre": time.September,
+	"sept":      time.September,
+	"octobre":   time.October,
+	"novembre":  time.November,
+	"décembre":  time.December,
+	"decembre":  time.December,
+	"déc":       time.December,
+}
+
+// frenchWeekdays maps lowercase french weekday names and abbreviations to
+// weekdays. The abbreviation for mardi is excluded since it conflicts with the
+// english abbreviation for march.
+var frenchWeekdays = map[string]time.Weekday{
+	"dimanche": time.Sunday,
+	"dim":      time.Sunday,
+	"lundi":    time.Monday,
+	"lun":      time.Monday,
+	"mardi":    time.Tuesday,
+	"mercredi": time.Wednesday,
+	"mer":      time.Wednesday,
+	"jeudi":    time.Thursday,
+	"jeu":      time.Thursday,
+	"vendredi": time.Friday,
+	"ven":      time.Friday,
+	"samedi":   time.Saturday,
+	"sam":      time.Saturday,
+}
+
+// ParseMonth case-insensitively parses an english or french month name, either
+// in full or abbreviated (the first three letters in english, or the usual
+// abbreviations in french, optionally followed by a period).
+func ParseMonth(s string) (time.Month, bool) {
+	s = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), ".")
+	for m := time.January; m <= time.December; m++ {
+		if x := strings.ToLower(m.String()); s == x || s == x[:3] {
+			return m, true
+		}
+	}
+	m, ok := frenchMonths[s]
+	return m, ok
+}
+
+// ParseWeekday case-insensitively parses an english or french weekday name,
+// either in full or abbreviated (the first three letters in english, or the
+// usual abbreviations in french, optionally followed by a period).
+func ParseWeekday(s string) (time.Weekday, bool) {
+	s = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), ".")
+	for w := time.Sunday; w <= time.Saturday; w++ {
+		if x := strings.ToLower(w.String()); s == x || s == x[:3] {
+			return w, true
+		}
+	}
+	w, ok := frenchWeekdays[s]
+	return w, ok
+}
+
+// MonthNames returns the sorted lowercase month names and abbreviations
+// accepted by ParseMonth for a language (en or fr).
+func MonthNames(lang string) []string {
+	switch lang {
+	case "en":
+		var names []string
+		for m := time.January; m <= time.December; m++ {
+			x := strings.ToLower(m.String())
+			names = append(names, x, x[:3])
+		}
+		return slices.Compact(slices.Sorted(slices.Values(names)))
+	case "fr":
+		return slices.Sorted(maps.Keys(frenchMonths))
+	}
+	return nil
+}
+
+// WeekdayNames returns the sorted lowercase weekday names and abbreviations
+// accepted by ParseWeekday for a language (en or fr).
+func WeekdayNames(lang string) []string {
+	switch lang {
+	case "en":
+		var names []string
+		for w := time.Sunday; w <= time.Saturday; w++ {
+			x := strings.ToLower(w.String())
+			names = append(names, x, x[:3])
+		}
+		return slices.Sorted(slices.Values(names))
+	case "fr":
+		return slices.Sorted(maps.Keys(frenchWeekdays))
+	}
+	return nil
+}
+
+// frenchMonthNames are the french month names and abbreviations, starting
+// with January.
+var frenchMonthNames = [12][2]string{
+	{"janvier", "janv."},
+	{"février", "févr."},
+	{"mars", "mars"},
+	{"avril", "avr."},
+	{"mai", "mai"},
+	{"juin", "juin"},
+	{"juillet", "juil."},
+	{"août", "août"},
+	{"septembre", "sept."},
+	{"octobre", "oct."},
+	{"novembre", "nov."},
+	{"décembre", "déc."},
+}
+
+// frenchWeekdayNames are the french weekday names and abbreviations, starting
+// with Sunday.
+var frenchWeekdayNames = [7][2]string{
+	{"dimanche", "dim."},
+	{"lundi", "lun."},
+	{"mardi", "mar."},
+	{"mercredi", "mer."},
+	{"jeudi", "jeu."},
+	{"vendredi", "ven."},
+	{"samedi", "sam."},
+}
+
+// MonthName returns the name of the month for a language (en or fr),
+// optionally abbreviated (the first three letters in english, or the usual
+// abbreviations in french). The english name is capitalized, and the french
+// one is lowercase. It returns an empty string if the month or language is
+// invalid.
+func MonthName(m time.Month, lang string, short bool) string {
+	if m < time.January || m > time.December {
+		return ""
+	}
+	switch lang {
+	case "en":
+		if short {
+			return m.String()[:3]
+		}
+		return m.String()
+	case "fr":
+		if short {
+			return frenchMonthNames[m-1][1]
+		}
+		return frenchMonthNames[m-1][0]
+	}
+	return ""
+}
+
+// WeekdayName returns the name of the weekday for a language (en or fr),
+// optionally abbreviated (see MonthName).
+func WeekdayName(w time.Weekday, lang string, short bool) string {
+	if w < time.Sunday || w > time.Saturday {
+		return ""
+	}
+	switch lang {
+	case "en":
+		if short {
+			return w.String()[:3]
+		}
+		return w.String()
+	case "fr":
+		if short {
+			return frenchWeekdayNames[w][1]
+		}
+		return frenchWeekdayNames[w][0]
+	}
+	return ""
+}
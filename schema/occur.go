@@ -0,0 +1,111 @@
+package schema
+
+import (
+	"iter"
+	"time"
+)
+
+// Occurrence is a single occurrence of a scheduled time slot on a specific
+// date.
+type Occurrence struct {
+	TimeRangeRef
+	Date    time.Time // midnight UTC on the date the time slot starts
+	Range   ClockRange
+	Start   time.Time // Range.Start on Date in the local timezone
+	End     time.Time // Range.End on Date in the local timezone
+	Holiday *Holiday  // set if the date is a holiday which doesn't have its own schedule in the group
+}
+
+// Occurrences iterates over the occurrences of the parsed time slots in all
+// facilities between the from and to dates (inclusive), in facility order (see
+// Facility.Occurrences).
+func (pb *Data) Occurrences(from, to time.Time, loc *time.Location) iter.Seq[Occurrence] {
+	return func(yield func(Occurrence) bool) {
+		for _, f := range pb.GetFacilities() {
+			for o := range f.Occurrences(from, to, loc) {
+				if !yield(o) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Occurrences iterates over the occurrences of the parsed time slots in the
+// facility between the from and to dates (inclusive) in order of schedule and
+// date, with the start and end times localized to loc.
+//
+// Time slots are only expanded on dates within the parsed schedule date range
+// (or any date if a side of it is unknown), and on the parsed date of the day
+// column if there is one. Holiday schedules are only expanded on that holiday,
+// and other schedules are not expanded on holidays which have a holiday
+// schedule in the same group (they are flagged if they fall on a holiday
+// without one, since the facility may have different hours or be closed).
+func (f *Facility) Occurrences(from, to time.Time, loc *time.Location) iter.Seq[Occurrence] {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return func(yield func(Occurrence) bool) {
+		for x := range f.AllTimeRanges() {
+			wkday, r, ok := x.Time.AsXParsed()
+			if !ok || !r.IsValid() {
+				continue
+			}
+			start, end := from, to
+			if t, ok := Date(x.Schedule.GetXFrom()).date(); ok && t.After(start) {
+				start = t
+			}
+			if t, ok := Date(x.Schedule.GetXTo()).date(); ok && t.Before(end) {
+				end = t
+			}
+			var dayDate time.Time
+			if dd := x.Schedule.GetXDaydates(); x.DayIndex < len(dd) {
+				dayDate, _ = Date(dd[x.DayIndex]).date()
+			}
+			for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+				if d.Weekday() != wkday {
+					continue
+				}
+				if !dayDate.IsZero() && !d.Equal(dayDate) {
+					continue
+				}
+				hol, isHoliday := HolidayOn(d.Year(), d.Month(), d.Day())
+				if name := x.Schedule.GetXHoliday(); name != "" {
+					if !isHoliday || hol.Name != name {
+						continue
+					}
+					hol = nil
+				} else if isHoliday {
+					if groupHasHolidaySchedule(x.Group, hol.Name) {
+						continue
+					}
+				}
+				if !yield(Occurrence{x, d, r, LocalTime(d, r.Start, loc), LocalTime(d, r.End, loc), hol}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// groupHasHolidaySchedule checks whether g has a schedule for the holiday.
+func groupHasHolidaySchedule(g *ScheduleGroup, name string) bool {
+	for _, s := range g.GetSchedules() {
+		if s.GetXHoliday() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalTime returns the clock time on the date of t (which may be past
+// midnight) in loc. Clock times skipped by a DST transition are normalized past
+// it.
+func LocalTime(t time.Time, c ClockTime, loc *time.Location) time.Time {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), 0, int(c), 0, 0, time.UTC)
+	local := time.Date(t.Year(), t.Month(), t.Day(), 0, int(c), 0, 0, loc)
+	if local.Hour() != wall.Hour() || local.Minute() != wall.Minute() {
+		_, off := local.Zone() // skipped by a transition, so use the offset before it
+		local = wall.Add(-time.Duration(off) * time.Second).In(loc)
+	}
+	return local
+}
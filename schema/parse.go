@@ -0,0 +1,580 @@
+package schema
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ParseOptions controls how free-form text is parsed.
+type ParseOptions struct {
+	// Strict rejects common typos and informal forms which are otherwise
+	// tolerated (e.g., duplicate am/pm suffixes or separators, "December 24
+	// and 25").
+	Strict bool
+
+	// French also accepts french forms (e.g., "18 h 30", "midi", "de ... à
+	// ...", "du 6 au 20 janvier", "1er").
+	French bool
+}
+
+// ParseClockRange parses a free-form time range like "6:30-8 pm" or "noon to
+// 1:30pm" (or "de 18 h à 20 h" if French), as found in schedule tables.
+//
+// Each side is a 12h time (with am/pm, or noon/midnight) or a 24h time (HH:MM
+// or HHMM), and the sides are separated by a dash or "to". If only the end has
+// am/pm, it is also applied to the start if the range would otherwise be 12
+// hours or longer (e.g., "6:30-8 pm", but not "11-1 pm"). Ambiguous mixes of
+// 12h and 24h times, open ranges, and empty ranges are rejected. If the end is
+// before the start, it is on the next day.
+//
+// Whitespace, unicode dashes, and case are normalized before parsing.
+func ParseClockRange(s string, opt ParseOptions) (r ClockRange, ok bool) {
+	strict := opt.Strict
+
+	s = normalizeClockText(s)
+
+	// TODO: rewrite this all now that I've decided how the edge cases should behave
+
+	parseSeparator := func(s string) (s1, s2 string, ok bool) {
+		if opt.French {
+			return cutFirst(s, "-", "to", "à")
+		}
+		return cutFirst(s, "-", "to")
+	}
+
+	parsePart := func(s string, mdef byte) (t ClockTime, m byte, ok bool) {
+		switch s {
+		case "midnight":
+			return MakeClockTime(0, 0), 'a', true // midnight implies am
+		case "noon":
+			return MakeClockTime(12, 0), 'p', true // noon implies pm
+		}
+		var sh, sm string
+		if opt.French {
+			switch s {
+			case "minuit":
+				return MakeClockTime(0, 0), 0, true // french midnight (24h)
+			case "midi":
+				return MakeClockTime(12, 0), 0, true // french noon (24h)
+			}
+			sh, sm, ok = strings.Cut(s, "h") // french time
+			if ok && sm == "" {
+				sm = "00" // no minute (e.g., "13 h")
+			}
+		}
+		if !ok {
+			if len(s) == 4 && strings.TrimFunc(s, func(r rune) bool { return r >= '0' && r <= '9' }) == "" {
+				sh, sm, m = s[:2], s[2:], 0 // military time
+			} else {
+				if s, ok = strings.CutSuffix(s, "pm"); ok {
+					if !strict {
+						for {
+							x, ok := strings.CutSuffix(strings.TrimRight(s, " "), "pm")
+							if !ok {
+								break
+							}
+							s = x // be lenient about duplicate pm suffixes
+						}
+					}
+					m = 'p' // 12h pm
+				} else if s, ok = strings.CutSuffix(s, "am"); ok {
+					if !strict {
+						for {
+							x, ok := strings.CutSuffix(strings.TrimRight(s, " "), "am")
+							if !ok {
+								break
+							}
+							s = x // be lenient about duplicate am suffixes
+						}
+					}
+					m = 'a' // 12h am
+				} else {
+					m = mdef // 24h or assumed am/pm
+				}
+				sh, sm, ok = strings.Cut(s, ":")
+				if !ok {
+					sm = "00" // no minute
+				}
+			}
+		}
+		if len(sh) > 2 || len(sm) > 2 {
+			return 0, 0, false // invalid hour/minute length
+		}
+		hh, err := strconv.ParseInt(sh, 10, 0)
+		if err != nil {
+			return 0, 0, false // invalid hour
+		}
+		if m != 0 {
+			if hh < 1 || hh > 12 {
+				return 0, 0, false // invalid 12h hour
+			}
+			switch m {
+			case 'p':
+				if hh < 12 {
+					hh += 12
+				}
+			case 'a':
+				if hh == 12 {
+					hh = 0
+				}
+			}
+		} else {
+			if hh < 0 || hh > 23 {
+				return 0, 0, false // invalid 24h hour
+			}
+		}
+		mm, err := strconv.ParseInt(sm, 10, 0)
+		if err != nil {
+			return 0, 0, false // invalid minute
+		}
+		if mm < 0 || mm > 59 {
+			return 0, 0, false // invalid 24h minute
+		}
+		return MakeClockTime(int(hh), int(mm)), m, true
+	}
+
+	if s == "" {
+		return r, false // empty
+	}
+	if x, ok := strings.CutPrefix(s, "de"); ok && opt.French && (strings.HasPrefix(x, "midi") || strings.HasPrefix(x, "minuit") || x != "" && x[0] >= '0' && x[0] <= '9') {
+		s = x // french "de ... à ..."
+	}
+	s1, s2, ok := parseSeparator(s)
+	if !ok {
+		return r, false // single time
+	}
+	if !strict {
+		for {
+			s2a, s2b, ok := parseSeparator(s2)
+			if !ok {
+				break // no extraneous separators
+			}
+			if strings.TrimSpace(s2a) != "" || strings.TrimSpace(s2b) == "" {
+				break // junk on the left side, or nothing on the right side
+			}
+			s2 = s2b // be lenient about extraneous separators with nothing in between (it's a frequent typo)
+		}
+	}
+	if s1 == "" || s2 == "" {
+		return r, false // open range
+	}
+	t1, m1, ok := parsePart(s1, 0)
+	if !ok {
+		return r, false // invalid lhs
+	}
+	t2, m2, ok := parsePart(s2, 0)
+	if !ok {
+		return r, false // invalid rhs
+	}
+	if m1 != 0 && m2 == 0 {
+		return r, false // ambiguous lhs 12h and rhs 24h
+	}
+	if m1 == 0 && t1 >= 13*60 && m2 != 0 {
+		return r, false // ambiguous lhs 24h and rhs 12h
+	}
+	if m1 == 0 && m2 == 'a' && t2 < 60 && t1 >= 12*60 && t1 < 13*60 {
+		t1 -= 12 * 60 // RHS is 12:XX AM and LHS is 12:XX
+	}
+	if m1 == 0 && m2 != 0 {
+		// only if lhs is before rhs AND the difference is greater than 12h
+		if t1 < t2 && t2-t1 >= 12*60 {
+			t1, m1, ok = parsePart(s1, m2) // reparse lhs with 12h rhs am/pm
+			if !ok {
+				return r, false // lhs hour is now invalid
+			}
+			_ = m1
+		}
+	}
+	if t1 == t2 {
+		return r, false // zero range
+	}
+	if t1 > t2 {
+		t2 += 24 * 60 // next day
+	}
+	return ClockRange{Start: t1, End: t2}, true
+}
+
+var (
+	cutDateRangeRe   = sync.OnceValue(func() *regexp.Regexp { return makeCutDateRangeRe(false) })
+	cutDateRangeFrRe = sync.OnceValue(func() *regexp.Regexp { return makeCutDateRangeRe(true) })
+)
+
+func makeCutDateRangeRe(french bool) *regexp.Regexp {
+	var (
+		frMonths   = strings.Join(MonthNames("fr"), `|`)
+		frWeekdays = strings.Join(WeekdayNames("fr"), `|`)
+	)
+	var b strings.Builder
+	b.WriteString(`(?i)`)          // case-insensitive
+	b.WriteString(`^`)             // anchor
+	b.WriteString(`\s*`)           // trim whitespace
+	b.WriteString(`(.+?)`)         // prefix
+	b.WriteString(`[ -]*[-][ -]*`) // separator (spaces/dashes around at least one dash)
+	b.WriteString(`((?:(?:[a-z]+`) // date range modifier
+	if french {
+		b.WriteString(`|jusqu['’]au|jusqu['’]à|`)        // ... or french until
+		b.WriteString(`à partir du|à compter du|dès le`) // ... or french starting
+	}
+	b.WriteString(`)\s*)?`)
+	b.WriteString(`(?:`) // start of date range:
+	b.WriteString(`(?:`) // ... month
+	for i := range 12 {
+		x := time.Month(1 + i).String()
+		if i != 0 {
+			b.WriteString(`|`)
+		}
+		b.WriteString(x[:3]) // first 3
+		b.WriteString(`|`)
+		b.WriteString(x) // or the whole thing
+	}
+	if french {
+		b.WriteString(`|` + frMonths) // or french
+	}
+	b.WriteString(`)(?:$|[ ,])`) // ... ... followed by a space or comma or end
+	b.WriteString(`|(?:`)        // ... or weekday
+	for i := range 7 {
+		x := time.Weekday(i).String()
+		if i != 0 {
+			b.WriteString(`|`)
+		}
+		b.WriteString(x[:3]) // first 3
+		b.WriteString(`|`)
+		b.WriteString(x) // or the whole thing
+	}
+	if french {
+		b.WriteString(`|` + frWeekdays) // or french
+	}
+	b.WriteString(`)(?:$|[ ,])`) // ... ... followed by a space or comma or end
+	if french {
+		b.WriteString(`|(?:[0-9]{1,2}(?:er)?\s+(?:au\s|(?:` + frMonths) // ... or french day and month (or day-only range start)
+		b.WriteString(`)(?:$|[ ,])))`)                                  // ... ... followed by a space or comma or end
+	}
+	b.WriteString(`).*)`) // and the rest
+	b.WriteString(`\s*`)  // trim whitespace
+	b.WriteString(`$`)    // anchor
+	return regexp.MustCompile(b.String())
+}
+
+// CutDateRange splits a schedule caption like "Lane swim - January 6 to April
+// 20" into the prefix and the date range (for ParseDateRange). It cuts s
+// around the first run of spaces/dashes (including at least one dash)
+// followed by an optional word (e.g., "starting"), then a month, a weekday, or
+// (if French) a day followed by a month or "au", then a space, comma, or the
+// end of the string. For best results, s should have already been normalized.
+//
+// note: we do it this way so we can be sure we didn't leave part of a date
+// behind with ParseDateRange.
+func CutDateRange(s string, opt ParseOptions) (prefix, dates string, ok bool) {
+	re := cutDateRangeRe
+	if opt.French {
+		re = cutDateRangeFrRe
+	}
+	if m := re().FindStringSubmatch(s); m != nil {
+		return m[1], m[2], true
+	}
+	return s, "", false
+}
+
+// ParseDateRange parses a schedule date range, which is one of:
+//
+//   - a single date (e.g., "January 6"), which is both the from and to date
+//   - "starting ..." or "until ..." for a one-sided range
+//   - "... to ...", where the right side may be only a day number (e.g.,
+//     "January 6 to 20")
+//   - "... and ...", for two consecutive days without a year (e.g., "December
+//     24 and 25"), unless Strict
+//
+// If French, the equivalent french forms are also accepted (e.g., "du 6 au 20
+// janvier", "à partir du 6 janvier", "jusqu'au 20 janvier"). Each date is
+// parsed with ParseLooseDate, and must have at least the month and day (except
+// for the day-only sides described above). If successful, the range will
+// always have at least the month and day set on one side.
+func ParseDateRange(s string, opt ParseOptions) (r DateRange, ok bool) {
+	s = normalizeText(s)
+
+	var (
+		startingPrefixes = []string{"starting "}
+		untilPrefixes    = []string{"until "}
+		rangeSeparators  = []string{" to "}
+	)
+	if opt.French {
+		startingPrefixes = append(startingPrefixes, "à partir du ", "à partir de ", "à compter du ", "dès le ")
+		untilPrefixes = append(untilPrefixes, "jusqu'au ", "jusqu'à ") // note: apostrophes are normalized
+		rangeSeparators = append(rangeSeparators, " au ", " à ")
+	}
+
+	var starting, until bool
+	for _, x := range startingPrefixes {
+		if s, starting = strings.CutPrefix(s, x); starting {
+			break
+		}
+	}
+	if !starting {
+		for _, x := range untilPrefixes {
+			if s, until = strings.CutPrefix(s, x); until {
+				break
+			}
+		}
+	}
+	if !starting && !until && opt.French {
+		s = strings.TrimPrefix(s, "du ") // french "du ... au ..."
+	}
+
+	var and, to bool
+	leftStr, rightStr, to := cutFirst(s, rangeSeparators...)
+	if !to && !opt.Strict {
+		leftStr, rightStr, and = strings.Cut(s, " and ")
+	}
+	if (and || to) && (starting || until) {
+		return r, false // can't both be a range and a one-sided date
+	}
+
+	parsePart := func(s string) (Date, bool) {
+		d, ok := ParseLooseDate(s, opt)
+		if !ok {
+			return d, false
+		}
+		if _, hasDay := d.Day(); !hasDay {
+			return d, false
+		}
+		if _, hasMonth := d.Month(); !hasMonth {
+			return d, false
+		}
+		return d, true
+	}
+
+	left, ok := parsePart(leftStr)
+	if !ok && to && opt.French {
+		// french day-only lhs (e.g., "du 6 au 20 janvier")
+		if day, err := strconv.ParseInt(strings.TrimSuffix(leftStr, "er"), 10, 0); err == nil && day >= 1 && day <= 31 {
+			if right, rok := parsePart(rightStr); rok {
+				year, hasYear := right.Year()
+				if !hasYear {
+					year = 0
+				}
+				month, _ := right.Month()
+				left, ok = MakeDate(year, month, int(day), -1), true
+			}
+		}
+	}
+	if !ok {
+		return r, false // failed to parse left side or single
+	}
+
+	switch {
+	case to, and: // ... and/to ...
+		var right Date
+		if and {
+			if _, hasYear := left.Year(); hasYear {
+				return r, false // cannot have year for an "and" range
+			}
+		}
+		if day, err := strconv.ParseInt(rightStr, 10, 0); err == nil && day >= 1 && day <= 32 {
+			year, hasYear := left.Year()
+			if !hasYear {
+				year = 0
+			}
+			month, hasMonth := left.Month()
+			if !hasMonth {
+				month = 0
+			}
+			if and {
+				leftDay, hasLeftDay := left.Day()
+				if !hasLeftDay {
+					return r, false // must have left day for an "and" range
+				}
+				if leftDay+1 != int(day) {
+					return r, false // right day must be 1 more than the left day for an "and" range
+				}
+			}
+			right, ok = MakeDate(year, month, int(day), -1), true
+		} else if and {
+			return r, false // must only have day number for an "and" range
+		} else {
+			right, ok = parsePart(rightStr)
+		}
+		if !ok {
+			return r, false // failed to parse right side
+		}
+		r.From = left
+		r.To = right
+
+	case starting: // starting ...
+		r.From = left
+
+	case until: // until ...
+		r.To = left
+
+	default: // ...
+		r.From = left
+		r.To = left
+	}
+	return r, true
+}
+
+// ParseLooseDate attempts to loosely parse an incomplete date string. The date
+// string must contain only any of the month name, day number, year (2000-2999),
+// and/or weekday name, in any order, separated by spaces, commas, periods, or
+// dashes. If French, french month and weekday names, and the "1er" ordinal,
+// are also accepted. It returns false if there is any unparsed text, if there
+// are duplicate components, or if the weekday or day isn't valid for the rest
+// of the date.
+func ParseLooseDate(s string, opt ParseOptions) (Date, bool) {
+	var (
+		yyyy int
+		mm   time.Month
+		dd   int
+		w    time.Weekday = -1
+	)
+	for seg := range strings.FieldsFuncSeq(normalizeText(s), func(r rune) bool {
+		return r == '.' || r == ',' || r == '-' || unicode.IsSpace(r)
+	}) {
+		var (
+			segMonth time.Month
+			segWkday time.Weekday = -1
+		)
+		if m, ok := parseMonthName(seg, opt.French); ok {
+			segMonth = m
+		} else if wd, ok := parseWeekdayName(seg, opt.French); ok {
+			segWkday = wd
+		} else if seg == "1er" && opt.French {
+			seg = "1" // french ordinal
+		}
+		if segMonth != 0 {
+			if mm != 0 {
+				return 0, false // duplicate month
+			}
+			mm = segMonth
+			continue
+		}
+		if segWkday != -1 {
+			if w != -1 {
+				return 0, false // duplicate weekday
+			}
+			w = segWkday
+			continue
+		}
+		if len(seg) == 4 && seg[0] == '2' {
+			if n, err := strconv.ParseInt(seg, 10, 0); err == nil {
+				if n < 2000 || n >= 3000 {
+					return 0, false // year out of range
+				}
+				if yyyy != 0 {
+					return 0, false // duplicate year
+				}
+				yyyy = int(n)
+				continue
+			}
+		}
+		if len(seg) == 2 || len(seg) == 1 {
+			if n, err := strconv.ParseInt(seg, 10, 0); err == nil {
+				if n < 1 || n > 31 {
+					return 0, false // day out of range
+				}
+				if dd != 0 {
+					return 0, false // duplicate day
+				}
+				dd = int(n)
+				continue
+			}
+		}
+		return 0, false // unparsed segment
+	}
+	d := MakeDate(yyyy, mm, dd, w)
+	return d, d.IsValid() // checks that it's nonzero, that the weekday/day is valid for the month/year if specified
+}
+
+// parseMonthName is like ParseMonth, but only accepts english names unless
+// french.
+func parseMonthName(s string, french bool) (time.Month, bool) {
+	m, ok := ParseMonth(s)
+	if ok && !french && !slices.Contains(MonthNames("en"), strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), ".")) {
+		return 0, false
+	}
+	return m, ok
+}
+
+// parseWeekdayName is like ParseWeekday, but only accepts english names unless
+// french.
+func parseWeekdayName(s string, french bool) (time.Weekday, bool) {
+	w, ok := ParseWeekday(s)
+	if ok && !french && !slices.Contains(WeekdayNames("en"), strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), ".")) {
+		return 0, false
+	}
+	return w, ok
+}
+
+// normalizeText applies unicode normalization to s, lowercases it, normalizes
+// dashes and smart punctuation, removes invisible characters, and collapses
+// whitespace.
+func normalizeText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '\u200b', '\ufeff', '\u200d', '\u200c':
+			return -1 // zero-width spaces
+		case '“', '”', '‟':
+			return '"'
+		case '\u2018', '\u2019', '\u201b':
+			return '\''
+		case '\u2039':
+			return '<'
+		case '\u203a':
+			return '>'
+		}
+		switch {
+		case unicode.IsSpace(r), unicode.Is(unicode.Zs, r):
+			return ' '
+		case unicode.Is(unicode.Pd, r):
+			return '-'
+		case !unicode.IsGraphic(r):
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, norm.NFKC.String(s))
+	s = string(slices.CompactFunc([]rune(s), func(a, b rune) bool {
+		return a == ' ' && a == b
+	}))
+	return strings.TrimSpace(s)
+}
+
+// normalizeClockText applies unicode normalization to s, lowercases it,
+// normalizes dashes, and removes whitespace and invisible characters.
+func normalizeClockText(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\u200b', r == '\ufeff', r == '\u200d', r == '\u200c':
+			return -1 // zero-width spaces
+		case unicode.IsSpace(r), unicode.Is(unicode.Zs, r):
+			return -1
+		case unicode.Is(unicode.Pd, r):
+			return '-'
+		case !unicode.IsGraphic(r):
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, norm.NFKC.String(s))
+}
+
+// cutFirst is like [strings.Cut], but cuts on the first of multiple
+// separators.
+func cutFirst(s string, sep ...string) (before, after string, ok bool) {
+	sn, si := 0, -1
+	for _, sep := range sep {
+		if i := strings.Index(s, sep); i >= 0 {
+			if si < 0 || i < si {
+				sn, si = len(sep), i
+			}
+		}
+	}
+	if si >= 0 {
+		return s[:si], s[si+sn:], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,276 @@
+// Package plain provides a plain Go struct view of the facility schedules, for
+// applications which don't need the full protobuf model.
+//
+// Conversions only keep the schedule information. Scrape metadata (source
+// details other than the url, and diagnostics) isn't included, and explicitly
+// ambiguous (zero) schedule dates are treated the same as missing ones.
+package plain
+
+import (
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// Facility is a facility and its schedules.
+type Facility struct {
+	ID                string // see schema.FacilityID
+	Name              string
+	Description       string
+	URL               string // source url
+	Address           string
+	Location          *LngLat // nil if not geocoded
+	NotificationsHTML string
+	SpecialHoursHTML  string
+	Aliases           []string
+	Translation       string // id of the same facility in the other language, if linked
+	Groups            []Group
+}
+
+// LngLat is a geographic coordinate.
+type LngLat struct {
+	Lng, Lat float32
+}
+
+// Group is a group of schedules for a facility (e.g., "Swimming").
+type Group struct {
+	Label            string
+	Title            string // parsed from the label
+	ChangesHTML      string // schedule changes
+	ReservationLinks []Link
+	NoResv           bool     // reservations explicitly not required
+	Holidays         []string // english names of holidays referenced by the schedule changes
+	Schedules        []Schedule
+}
+
+// Schedule is a table of activities by day.
+type Schedule struct {
+	Caption    string
+	Name       string           // parsed from the caption
+	DateText   string           // raw date range from the caption
+	Dates      schema.DateRange // parsed from the date text, zero sides if unknown
+	Days       []string         // day column headers
+	DayDates   []schema.Date    // parsed from the day column headers, zero if unknown
+	Holiday    string           // english name of the holiday the schedule is for
+	Activities []Activity
+}
+
+// Activity is a row in a schedule.
+type Activity struct {
+	Label    string
+	Name     string // parsed from the label
+	Venue    string // parsed from the label
+	Resv     *bool  // nil if not stated
+	Links    []Link
+	Sessions []Session
+}
+
+// Session is a time slot for an activity in a schedule day column.
+type Session struct {
+	Day     int // index of the day column
+	Label   string
+	Weekday time.Weekday      // -1 if unknown
+	Time    schema.ClockRange // sides are -1 if unknown
+}
+
+// Parsed checks whether the weekday and time of the session are known.
+func (s Session) Parsed() bool {
+	return s.Weekday != -1 && s.Time.Start.IsValid() && s.Time.End.IsValid()
+}
+
+// Link is a labeled url.
+type Link struct {
+	Label string
+	URL   string
+}
+
+// Facilities converts the facilities in pb.
+func Facilities(pb *schema.Data) []Facility {
+	fs := make([]Facility, len(pb.GetFacilities()))
+	for i, f := range pb.GetFacilities() {
+		fs[i] = FromProto(f)
+	}
+	return fs
+}
+
+// FromProto converts a facility from the protobuf model.
+func FromProto(f *schema.Facility) Facility {
+	x := Facility{
+		ID:                f.GetXId(),
+		Name:              f.GetName(),
+		Description:       f.GetDescription(),
+		URL:               f.GetSource().GetUrl(),
+		Address:           f.GetAddress(),
+		NotificationsHTML: f.GetNotificationsHtml(),
+		SpecialHoursHTML:  f.GetSpecialHoursHtml(),
+		Aliases:           f.GetXAliases(),
+		Translation:       f.GetXTranslation(),
+	}
+	if f.HasXLnglat() {
+		x.Location = &LngLat{f.GetXLnglat().GetLng(), f.GetXLnglat().GetLat()}
+	}
+	for _, g := range f.GetScheduleGroups() {
+		y := Group{
+			Label:       g.GetLabel(),
+			Title:       g.GetXTitle(),
+			ChangesHTML: g.GetScheduleChangesHtml(),
+			NoResv:      g.GetXNoresv(),
+			Holidays:    g.GetXHolidays(),
+		}
+		for _, l := range g.GetReservationLinks() {
+			y.ReservationLinks = append(y.ReservationLinks, Link{l.GetLabel(), l.GetUrl()})
+		}
+		for _, s := range g.GetSchedules() {
+			z := Schedule{
+				Caption:  s.GetCaption(),
+				Name:     s.GetXName(),
+				DateText: s.GetXDate(),
+				Dates:    schema.DateRange{From: schema.Date(s.GetXFrom()), To: schema.Date(s.GetXTo())},
+				Days:     s.GetDays(),
+				Holiday:  s.GetXHoliday(),
+			}
+			for _, d := range s.GetXDaydates() {
+				z.DayDates = append(z.DayDates, schema.Date(d))
+			}
+			for _, a := range s.GetActivities() {
+				z.Activities = append(z.Activities, activityFromProto(a))
+			}
+			y.Schedules = append(y.Schedules, z)
+		}
+		x.Groups = append(x.Groups, y)
+	}
+	return x
+}
+
+func activityFromProto(a *schema.Schedule_Activity) Activity {
+	x := Activity{
+		Label: a.GetLabel(),
+		Name:  a.GetXName(),
+		Venue: a.GetXVenue(),
+	}
+	if a.HasXResv() {
+		resv := a.GetXResv()
+		x.Resv = &resv
+	}
+	for _, l := range a.GetLinks() {
+		x.Links = append(x.Links, Link{l.GetLabel(), l.GetUrl()})
+	}
+	for i, d := range a.GetDays() {
+		for _, t := range d.GetTimes() {
+			s := Session{
+				Day:     i,
+				Label:   t.GetLabel(),
+				Weekday: -1,
+				Time:    schema.ClockRange{Start: -1, End: -1},
+			}
+			if t.HasXWkday() {
+				s.Weekday = t.GetXWkday().AsWeekday()
+			}
+			if t.HasXStart() {
+				s.Time.Start = schema.ClockTime(t.GetXStart())
+			}
+			if t.HasXEnd() {
+				s.Time.End = schema.ClockTime(t.GetXEnd())
+			}
+			x.Sessions = append(x.Sessions, s)
+		}
+	}
+	return x
+}
+
+// Proto converts f to the protobuf model. Activities have a day for each
+// schedule day column (or more if a session references one past the end).
+func (f Facility) Proto() *schema.Facility {
+	x := schema.Facility_builder{
+		XId:               f.ID,
+		Name:              f.Name,
+		Description:       f.Description,
+		Address:           f.Address,
+		NotificationsHtml: f.NotificationsHTML,
+		SpecialHoursHtml:  f.SpecialHoursHTML,
+		XAliases:          f.Aliases,
+		XTranslation:      f.Translation,
+	}
+	if f.URL != "" {
+		x.Source = schema.Source_builder{Url: f.URL}.Build()
+	}
+	if f.Location != nil {
+		x.XLnglat = schema.LngLat_builder{Lng: f.Location.Lng, Lat: f.Location.Lat}.Build()
+	}
+	for _, g := range f.Groups {
+		y := schema.ScheduleGroup_builder{
+			Label:               g.Label,
+			XTitle:              g.Title,
+			ScheduleChangesHtml: g.ChangesHTML,
+			XNoresv:             g.NoResv,
+			XHolidays:           g.Holidays,
+		}
+		for _, l := range g.ReservationLinks {
+			y.ReservationLinks = append(y.ReservationLinks, schema.ReservationLink_builder{Label: l.Label, Url: l.URL}.Build())
+		}
+		for _, s := range g.Schedules {
+			z := schema.Schedule_builder{
+				Caption:  s.Caption,
+				XName:    s.Name,
+				XDate:    s.DateText,
+				Days:     s.Days,
+				XHoliday: s.Holiday,
+			}
+			if !s.Dates.From.IsZero() {
+				z.XFrom = ptrTo(int32(s.Dates.From))
+			}
+			if !s.Dates.To.IsZero() {
+				z.XTo = ptrTo(int32(s.Dates.To))
+			}
+			for _, d := range s.DayDates {
+				z.XDaydates = append(z.XDaydates, int32(d))
+			}
+			for _, a := range s.Activities {
+				z.Activities = append(z.Activities, a.proto(len(s.Days)))
+			}
+			y.Schedules = append(y.Schedules, z.Build())
+		}
+		x.ScheduleGroups = append(x.ScheduleGroups, y.Build())
+	}
+	return x.Build()
+}
+
+func (a Activity) proto(days int) *schema.Schedule_Activity {
+	x := schema.Schedule_Activity_builder{
+		Label:  a.Label,
+		XName:  a.Name,
+		XVenue: a.Venue,
+		XResv:  a.Resv,
+	}
+	for _, l := range a.Links {
+		x.Links = append(x.Links, schema.Link_builder{Label: l.Label, Url: l.URL}.Build())
+	}
+	for _, s := range a.Sessions {
+		days = max(days, s.Day+1)
+	}
+	times := make([][]*schema.TimeRange, days)
+	for _, s := range a.Sessions {
+		if s.Day < 0 {
+			continue
+		}
+		t := schema.TimeRange_builder{Label: s.Label}
+		if s.Weekday >= time.Sunday && s.Weekday <= time.Saturday {
+			t.XWkday = ptrTo(schema.ToWeekday(s.Weekday))
+		}
+		if s.Time.Start.IsValid() {
+			t.XStart = ptrTo(int32(s.Time.Start))
+		}
+		if s.Time.End.IsValid() {
+			t.XEnd = ptrTo(int32(s.Time.End))
+		}
+		times[s.Day] = append(times[s.Day], t.Build())
+	}
+	for _, ts := range times {
+		x.Days = append(x.Days, schema.Schedule_ActivityDay_builder{Times: ts}.Build())
+	}
+	return x.Build()
+}
+
+func ptrTo[T any](x T) *T {
+	return &x
+}
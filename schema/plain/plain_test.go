@@ -0,0 +1,68 @@
+package plain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRoundTrip(t *testing.T) {
+	pb := schema.Facility_builder{
+		XId:      "a-pool",
+		Name:     "A Pool",
+		Source:   schema.Source_builder{Url: "https://ottawa.ca/en/a-pool"}.Build(),
+		Address:  "1 Main St",
+		XLnglat:  schema.LngLat_builder{Lng: -75.7, Lat: 45.4}.Build(),
+		XAliases: []string{"The Pool"},
+		ScheduleGroups: []*schema.ScheduleGroup{
+			schema.ScheduleGroup_builder{
+				Label:            "Swimming",
+				XTitle:           "Swimming",
+				ReservationLinks: []*schema.ReservationLink{schema.ReservationLink_builder{Label: "Reserve", Url: "https://example.com"}.Build()},
+				Schedules: []*schema.Schedule{
+					schema.Schedule_builder{
+						Caption: "Swimming - January 6 to April 6",
+						XFrom:   ptrTo(int32(2025_01_06_2)),
+						XTo:     ptrTo(int32(2025_04_06_1)),
+						Days:    []string{"Monday", "Tuesday"},
+						Activities: []*schema.Schedule_Activity{
+							schema.Schedule_Activity_builder{
+								Label: "Lane swim",
+								XResv: ptrTo(true),
+								Days: []*schema.Schedule_ActivityDay{
+									schema.Schedule_ActivityDay_builder{Times: []*schema.TimeRange{
+										schema.TimeRange_builder{Label: "9 - 10 am", XWkday: ptrTo(schema.Weekday_MONDAY), XStart: ptrTo(int32(9 * 60)), XEnd: ptrTo(int32(10 * 60))}.Build(),
+										schema.TimeRange_builder{Label: "noon - ?", XWkday: ptrTo(schema.Weekday_MONDAY), XStart: ptrTo(int32(12 * 60))}.Build(),
+									}}.Build(),
+									schema.Schedule_ActivityDay_builder{}.Build(),
+								},
+							}.Build(),
+						},
+					}.Build(),
+				},
+			}.Build(),
+		},
+	}.Build()
+
+	f := FromProto(pb)
+	if n := len(f.Groups[0].Schedules[0].Activities[0].Sessions); n != 2 {
+		t.Fatalf("expected 2 sessions, got %d", n)
+	}
+	if s := f.Groups[0].Schedules[0].Activities[0].Sessions[0]; !s.Parsed() || s.Weekday != time.Monday || s.Time != schema.MakeClockRange(9, 0, 10, 0) {
+		t.Errorf("unexpected session %+v", s)
+	}
+	if s := f.Groups[0].Schedules[0].Activities[0].Sessions[1]; s.Parsed() || s.Time.End != -1 {
+		t.Errorf("unexpected session %+v", s)
+	}
+	if act := f.Groups[0].Schedules[0].Dates.String(); act != "Monday, January 6, 2025 to Sunday, April 6, 2025" {
+		t.Errorf("unexpected dates %q", act)
+	}
+	if act := f.Proto(); !proto.Equal(act, pb) {
+		t.Errorf("round-trip mismatch:\n\tgot: %v\n\texp: %v", act, pb)
+	}
+	if fs := Facilities(schema.Data_builder{Facilities: []*schema.Facility{pb}}.Build()); len(fs) != 1 || fs[0].ID != "a-pool" {
+		t.Errorf("unexpected facilities %+v", fs)
+	}
+}
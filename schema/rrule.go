@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"strings"
+	"time"
+)
+
+// Recurrence is a weekly recurring event as RFC 5545 content lines. If the
+// timezone isn't UTC, the times are local to a TZID, which needs a matching
+// VTIMEZONE component in the calendar.
+type Recurrence struct {
+	DTStart string // e.g., DTSTART;TZID=America/Toronto:20250106T070000
+	DTEnd   string // e.g., DTEND;TZID=America/Toronto:20250106T080000
+	RRule   string // e.g., RRULE:FREQ=WEEKLY;BYDAY=MO;UNTIL=20251222T045959Z
+}
+
+// MakeRecurrence converts a weekly time slot on the weekday within the
+// (inclusive) date range into a recurrence in loc, starting on the first
+// matching date. The from date must be fully specified, and the to date must
+// be fully specified or zero (for no end). It returns false if the recurrence
+// can't be represented or has no occurrences.
+func MakeRecurrence(w time.Weekday, r ClockRange, dates DateRange, loc *time.Location) (Recurrence, bool) {
+	if w < time.Sunday || w > time.Saturday || !r.IsValid() {
+		return Recurrence{}, false
+	}
+	first, ok := dates.From.NextWeekday(w)
+	if !ok {
+		return Recurrence{}, false
+	}
+	start, _ := first.date()
+	var until time.Time
+	if !dates.To.IsZero() {
+		last, ok := dates.To.date()
+		if !ok || last.Before(start) {
+			return Recurrence{}, false
+		}
+		until = time.Date(last.Year(), last.Month(), last.Day(), 23, 59, 59, 0, loc).UTC()
+	}
+
+	prop := func(name string, t ClockTime) string {
+		wall := start.Add(time.Duration(t) * time.Minute).Format("20060102T150405")
+		if loc == time.UTC {
+			return name + ":" + wall + "Z"
+		}
+		return name + ";TZID=" + loc.String() + ":" + wall
+	}
+	rec := Recurrence{
+		DTStart: prop("DTSTART", r.Start),
+		DTEnd:   prop("DTEND", r.End),
+		RRule:   "RRULE:FREQ=WEEKLY;BYDAY=" + strings.ToUpper(ToWeekday(w).Code()),
+	}
+	if !until.IsZero() {
+		rec.RRule += ";UNTIL=" + until.Format("20060102T150405Z")
+	}
+	return rec, true
+}
+
+// AsXRecurrence converts the parsed weekday and time of tr, and the parsed
+// date range of the schedule it's in, into a recurrence (see MakeRecurrence).
+func (tr *TimeRange) AsXRecurrence(s *Schedule, loc *time.Location) (Recurrence, bool) {
+	w, r, ok := tr.AsXParsed()
+	if !ok {
+		return Recurrence{}, false
+	}
+	return MakeRecurrence(w, r, DateRange{Date(s.GetXFrom()), Date(s.GetXTo())}, loc)
+}
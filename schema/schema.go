@@ -1,12 +1,20 @@
 package schema
 
 import (
+	"cmp"
 	_ "embed"
+	"iter"
+	"math/bits"
 	"reflect"
 	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 //go:generate go run github.com/bufbuild/buf/cmd/buf@v1.57.2 generate --template {"version":"v2","plugins":[{"local":["go","tool","protoc-gen-go"],"out":".","opt":["paths=source_relative","Mschema.proto=./schema","default_api_level=API_OPAQUE"]}]}
@@ -18,6 +26,29 @@ func Proto() string {
 	return string(schema)
 }
 
+// Descriptor returns a self-contained descriptor set for the schema (including
+// its dependencies), which can be used to decode the data without the proto
+// file.
+func Descriptor() *descriptorpb.FileDescriptorSet {
+	var (
+		set  descriptorpb.FileDescriptorSet
+		seen = map[string]bool{}
+		add  func(protoreflect.FileDescriptor)
+	)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		for i := range fd.Imports().Len() {
+			add(fd.Imports().Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	add(File_schema_proto)
+	return &set
+}
+
 func ToWeekday(w time.Weekday) Weekday {
 	return Weekday(w)
 }
@@ -26,6 +57,211 @@ func (w Weekday) AsWeekday() time.Weekday {
 	return time.Weekday(w)
 }
 
+// weekdayCodes are the two-letter weekday codes.
+var weekdayCodes = [...]string{"su", "mo", "tu", "we", "th", "fr", "sa"}
+
+// Name returns the full name of the weekday for a language (en or fr), or an
+// empty string if it's invalid (see WeekdayName).
+func (w Weekday) Name(lang string) string {
+	return WeekdayName(w.AsWeekday(), lang, false)
+}
+
+// Abbrev returns the abbreviated name of the weekday for a language (en or fr),
+// or an empty string if it's invalid (see WeekdayName).
+func (w Weekday) Abbrev(lang string) string {
+	return WeekdayName(w.AsWeekday(), lang, true)
+}
+
+// Code returns the lowercase two-letter code for the weekday (e.g., "su",
+// "mo"), as used by iCalendar (uppercase) and for compact keys, or an empty
+// string if it's invalid.
+func (w Weekday) Code() string {
+	if w < Weekday_SUNDAY || w > Weekday_SATURDAY {
+		return ""
+	}
+	return weekdayCodes[w]
+}
+
+// WeekdaySet is a set of weekdays as a bitmask, where bit n is set if
+// time.Weekday(n) is in the set.
+type WeekdaySet uint8
+
+// AllWeekdays contains every weekday.
+const AllWeekdays WeekdaySet = 1<<7 - 1
+
+// MakeWeekdaySet makes a set containing the specified weekdays. Invalid
+// weekdays are ignored.
+func MakeWeekdaySet(wkdays ...time.Weekday) WeekdaySet {
+	var s WeekdaySet
+	for _, w := range wkdays {
+		s = s.Add(w)
+	}
+	return s
+}
+
+// ParseWeekdaySet parses the weekdays mentioned in a string like a schedule
+// table header (e.g., "Monday", "Sat & Sun", "Mon - Fri", "Tues to Thurs",
+// "lun. au ven."). Words accepted by ParseWeekday, or which are a prefix of an
+// english weekday name (optionally plural) and at least three letters long,
+// are matched, and weekdays separated only by a dash, "to", "through", or "au"
+// are treated as an inclusive range (wrapping around the end of the week if
+// needed). It returns false if no weekdays were found.
+func ParseWeekdaySet(s string) (WeekdaySet, bool) {
+	var (
+		set  WeekdaySet
+		last = time.Weekday(-1) // last weekday, if only separators since
+		rng  bool               // whether there was a range separator since last
+	)
+	for tok := range weekdayTokens(strings.ToLower(s)) {
+		switch tok {
+		case "-", "to", "through", "thru", "au":
+			rng = last != -1
+			continue
+		}
+		w, ok := parseWeekday(tok)
+		if !ok {
+			last, rng = -1, false
+			continue
+		}
+		if rng {
+			for x := last; x != w; x = (x + 1) % 7 {
+				set = set.Add(x)
+			}
+		}
+		set = set.Add(w)
+		last, rng = w, false
+	}
+	return set, set != 0
+}
+
+// weekdayTokens splits s into words and dashes.
+func weekdayTokens(s string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		word := -1
+		for i, r := range s {
+			if unicode.IsLetter(r) {
+				if word == -1 {
+					word = i
+				}
+				continue
+			}
+			if word != -1 {
+				if !yield(s[word:i]) {
+					return
+				}
+				word = -1
+			}
+			if unicode.Is(unicode.Pd, r) && !yield("-") {
+				return
+			}
+		}
+		if word != -1 {
+			yield(s[word:])
+		}
+	}
+}
+
+// parseWeekday parses a lowercase weekday name accepted by ParseWeekday, or a
+// (possibly abbreviated or plural) english weekday name.
+func parseWeekday(s string) (time.Weekday, bool) {
+	if w, ok := ParseWeekday(s); ok {
+		return w, true
+	}
+	for _, x := range []string{s, strings.TrimSuffix(s, "s")} {
+		if len(x) >= 3 {
+			for w := range time.Weekday(7) {
+				if strings.HasPrefix(strings.ToLower(w.String()), x) {
+					return w, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// Has checks whether w is in the set.
+func (s WeekdaySet) Has(w time.Weekday) bool {
+	return w >= time.Sunday && w <= time.Saturday && s&(1<<w) != 0
+}
+
+// Add returns the set with w added.
+func (s WeekdaySet) Add(w time.Weekday) WeekdaySet {
+	if w >= time.Sunday && w <= time.Saturday {
+		s |= 1 << w
+	}
+	return s
+}
+
+// Remove returns the set with w removed.
+func (s WeekdaySet) Remove(w time.Weekday) WeekdaySet {
+	if w >= time.Sunday && w <= time.Saturday {
+		s &^= 1 << w
+	}
+	return s
+}
+
+// Union returns the weekdays in either set.
+func (s WeekdaySet) Union(o WeekdaySet) WeekdaySet {
+	return (s | o) & AllWeekdays
+}
+
+// Intersect returns the weekdays in both sets.
+func (s WeekdaySet) Intersect(o WeekdaySet) WeekdaySet {
+	return s & o & AllWeekdays
+}
+
+// Difference returns the weekdays in s but not in o.
+func (s WeekdaySet) Difference(o WeekdaySet) WeekdaySet {
+	return s &^ o & AllWeekdays
+}
+
+// Len returns the number of weekdays in the set.
+func (s WeekdaySet) Len() int {
+	return bits.OnesCount8(uint8(s & AllWeekdays))
+}
+
+// All iterates over the weekdays in the set, starting with Sunday.
+func (s WeekdaySet) All() iter.Seq[time.Weekday] {
+	return func(yield func(time.Weekday) bool) {
+		for w := range time.Weekday(7) {
+			if s.Has(w) && !yield(w) {
+				return
+			}
+		}
+	}
+}
+
+// String formats the set as comma-separated abbreviated weekdays starting with
+// Sunday, with runs of three or more collapsed into a range (e.g., "Sun,
+// Tue-Thu, Sat").
+func (s WeekdaySet) String() string {
+	var b strings.Builder
+	for w := time.Sunday; w <= time.Saturday; w++ {
+		if !s.Has(w) {
+			continue
+		}
+		end := w
+		for end < time.Saturday && s.Has(end+1) {
+			end++
+		}
+		if b.Len() != 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(w.String()[:3])
+		switch end - w {
+		case 0:
+		case 1:
+			b.WriteString(", ")
+			b.WriteString(end.String()[:3])
+		default:
+			b.WriteString("-")
+			b.WriteString(end.String()[:3])
+		}
+		w = end
+	}
+	return b.String()
+}
+
 type ClockTime int32
 
 func MakeClockTime(hh, mm int) ClockTime {
@@ -54,31 +290,70 @@ func (t ClockTime) String() string {
 }
 
 func (t ClockTime) Format(ampm bool) string {
+	return t.FormatWith(ClockFormat{AMPM: ampm})
+}
+
+// ClockFormat controls how clock times are formatted. Times past midnight are
+// prefixed with a '>' for each day.
+type ClockFormat struct {
+	// AMPM uses 12h times with an am/pm suffix (e.g., "6:30pm") instead of 24h
+	// times (e.g., "18:30"). It is ignored if French.
+	AMPM bool
+
+	// French uses the french style (e.g., "18 h 30").
+	French bool
+
+	// Compact omits zero minutes for 12h times (e.g., "6pm") and french times
+	// (e.g., "18h", without spaces), the leading zero for 24h times (e.g.,
+	// "9:00"), and the spaces around the range separator.
+	Compact bool
+}
+
+// FormatWith formats t using the options.
+func (t ClockTime) FormatWith(opt ClockFormat) string {
 	if !t.IsValid() {
 		return "invalid"
 	}
 	var b strings.Builder
 	d, hh, mm := t.Split()
+	for range d {
+		b.WriteByte('>')
+	}
+	if opt.French {
+		b.WriteString(strconv.Itoa(hh))
+		if opt.Compact {
+			b.WriteByte('h')
+			if mm != 0 {
+				b.WriteByte('0' + byte(mm/10))
+				b.WriteByte('0' + byte(mm%10))
+			}
+		} else {
+			b.WriteString(" h ")
+			b.WriteByte('0' + byte(mm/10))
+			b.WriteByte('0' + byte(mm%10))
+		}
+		return b.String()
+	}
+	ampm := opt.AMPM
 	ap := byte('a')
 	if ampm && hh >= 12 {
 		ap = 'p'
 		hh -= 12
 	}
-	for range d {
-		b.WriteByte('>')
-	}
 	if ampm && hh == 0 {
 		b.WriteByte('1')
 		b.WriteByte('2')
 	} else {
-		if !ampm || hh >= 10 {
+		if (!ampm && !opt.Compact) || hh >= 10 {
 			b.WriteByte('0' + byte(hh/10))
 		}
 		b.WriteByte('0' + byte(hh%10))
 	}
-	b.WriteByte(':')
-	b.WriteByte('0' + byte(mm/10))
-	b.WriteByte('0' + byte(mm%10))
+	if !ampm || !opt.Compact || mm != 0 {
+		b.WriteByte(':')
+		b.WriteByte('0' + byte(mm/10))
+		b.WriteByte('0' + byte(mm%10))
+	}
 	if ampm {
 		b.WriteByte(ap)
 		b.WriteByte('m')
@@ -108,6 +383,55 @@ func (t ClockTime) GoString() string {
 	return b.String()
 }
 
+// MakeClockTimeFromTime returns the clock time of t in its location, truncated
+// to the minute.
+func MakeClockTimeFromTime(t time.Time) ClockTime {
+	return MakeClockTime(t.Hour(), t.Minute())
+}
+
+// Add returns t plus d, truncated to the minute. If t is invalid or the result
+// is before midnight on the first day, it returns an invalid clock time.
+func (t ClockTime) Add(d time.Duration) ClockTime {
+	if !t.IsValid() {
+		return -1
+	}
+	return (t + ClockTime(d/time.Minute)).Norm()
+}
+
+// Sub returns the duration t-u. If either is invalid, it returns zero.
+func (t ClockTime) Sub(u ClockTime) time.Duration {
+	if !t.IsValid() || !u.IsValid() {
+		return 0
+	}
+	return time.Duration(t-u) * time.Minute
+}
+
+// RoundTo rounds t to the nearest multiple of d (which must be at least a
+// minute) since midnight on the first day, rounding halfway values up.
+func (t ClockTime) RoundTo(d time.Duration) ClockTime {
+	m := ClockTime(d / time.Minute)
+	if !t.IsValid() || m <= 0 {
+		return t.Norm()
+	}
+	return (t + m/2) / m * m
+}
+
+// Compare returns -1 if t is before u, 0 if they are the same, and +1 if t is
+// after u. Invalid clock times are ordered before valid ones.
+func (t ClockTime) Compare(u ClockTime) int {
+	return cmp.Compare(t.Norm(), u.Norm())
+}
+
+// Before checks whether t is before u.
+func (t ClockTime) Before(u ClockTime) bool {
+	return t.Compare(u) < 0
+}
+
+// After checks whether t is after u.
+func (t ClockTime) After(u ClockTime) bool {
+	return t.Compare(u) > 0
+}
+
 type ClockRange struct {
 	Start ClockTime
 	End   ClockTime
@@ -133,19 +457,33 @@ func (r ClockRange) String() string {
 }
 
 func (r ClockRange) Format(ampm bool) string {
+	return r.FormatWith(ClockFormat{AMPM: ampm})
+}
+
+// FormatWith formats r using the options. If the range is less than a day and
+// starts on the first day, the end isn't marked as being on the next day, and
+// for 12h times, the am/pm suffix of the start is omitted if it's the same as
+// the end.
+func (r ClockRange) FormatWith(opt ClockFormat) string {
 	if !r.IsValid() {
 		return "invalid"
 	}
-	x := r.Start.Format(ampm)
-	y := r.End.Format(ampm)
+	x := r.Start.FormatWith(opt)
+	y := r.End.FormatWith(opt)
 	if r.End-r.Start < 24*60 && r.Start < 24*60 {
 		if y[0] == '>' {
 			y = y[1:]
 		}
-		if ampm && x[len(x)-2] == y[len(y)-2] {
+		if opt.AMPM && !opt.French && x[len(x)-2] == y[len(y)-2] {
 			x = x[:len(x)-2]
 		}
 	}
+	switch {
+	case opt.Compact:
+		return x + "-" + y
+	case opt.French:
+		return x + " à " + y
+	}
 	return x + " - " + y
 }
 
@@ -153,6 +491,38 @@ func (r ClockRange) Overlaps(o ClockRange) bool {
 	return r.IsValid() && r.Start <= o.End && o.Start <= r.End
 }
 
+// Normalize returns the range with the start moved into the first day, the end
+// wrapped to the next day if it is before the start, and the end clamped to at
+// most a day after the start. If either side is invalid, both are set to -1.
+func (r ClockRange) Normalize() ClockRange {
+	if !r.Start.IsValid() || !r.End.IsValid() {
+		return ClockRange{-1, -1}
+	}
+	if d := r.Start / (24 * 60); d > 0 {
+		r.Start -= d * 24 * 60
+		r.End -= d * 24 * 60
+	}
+	if r.End < r.Start {
+		r.End += ((r.Start-r.End)/(24*60) + 1) * 24 * 60
+	}
+	r.End = min(r.End, r.Start+24*60)
+	return r
+}
+
+// SplitAtMidnight splits the normalized range into the portion on the day it
+// starts and the portion on the next day, if any. The next day portion is the
+// zero (invalid) ClockRange if the range doesn't cross midnight.
+func (r ClockRange) SplitAtMidnight() (same, next ClockRange) {
+	r = r.Normalize()
+	if !r.IsValid() {
+		return r, ClockRange{}
+	}
+	if r.End <= 24*60 {
+		return r, ClockRange{}
+	}
+	return ClockRange{r.Start, 24 * 60}, ClockRange{0, r.End - 24*60}
+}
+
 // Date represents any combination of Weekday/Year/Month/Day as an integer in
 // the form YYYYMMDDW, YYYY is the zero-padded year, MM is the zero-padded month
 // starting at Jan=1, DD is the zero-padded day, and W is the weekday starting
@@ -228,6 +598,100 @@ func (d Date) IsValid() bool {
 	return true
 }
 
+// Resolve converts d into midnight on that date in loc. The month and day must
+// be specified. If the year isn't specified, the one putting the date nearest
+// to reference (in loc) is used, considering only years where the weekday (if
+// specified) matches. It returns false if d can't be resolved to a valid date.
+func (d Date) Resolve(reference time.Time, loc *time.Location) (time.Time, bool) {
+	month, hasMonth := d.Month()
+	day, hasDay := d.Day()
+	if !hasMonth || !hasDay {
+		return time.Time{}, false
+	}
+	if _, hasYear := d.Year(); hasYear {
+		if !d.IsValid() {
+			return time.Time{}, false
+		}
+		year, _ := d.Year()
+		return time.Date(year, month, day, 0, 0, 0, 0, loc), true
+	}
+	wkday, hasWkday := d.Weekday()
+	if !hasWkday {
+		wkday = -1
+	}
+	reference = reference.In(loc)
+	var (
+		best     time.Time
+		bestDist time.Duration
+	)
+	for year := reference.Year() - 1; year <= reference.Year()+1; year++ {
+		if c := MakeDate(year, month, day, wkday); c.IsValid() {
+			t := time.Date(year, month, day, 0, 0, 0, 0, loc)
+			if dist := t.Sub(reference).Abs(); best.IsZero() || dist < bestDist {
+				best, bestDist = t, dist
+			}
+		}
+	}
+	return best, !best.IsZero()
+}
+
+// DateOf returns the date of t, including the weekday.
+func DateOf(t time.Time) Date {
+	return MakeDate(t.Year(), t.Month(), t.Day(), t.Weekday())
+}
+
+// AddDays returns the date n days after d (or before if negative), including
+// the weekday. The year, month, and day of d must be specified. It returns
+// false if d isn't a valid full date or the result is out of range.
+func (d Date) AddDays(n int) (Date, bool) {
+	t, ok := d.date()
+	if !ok {
+		return 0, false
+	}
+	return dateOfChecked(t.AddDate(0, 0, n))
+}
+
+// NextWeekday returns the first date on or after d which falls on the
+// specified weekday, including the weekday. The year, month, and day of d must
+// be specified.
+func (d Date) NextWeekday(wkday time.Weekday) (Date, bool) {
+	t, ok := d.date()
+	if !ok || wkday < time.Sunday || wkday > time.Saturday {
+		return 0, false
+	}
+	return dateOfChecked(t.AddDate(0, 0, (int(wkday)-int(t.Weekday())+7)%7))
+}
+
+// StartOfWeek returns the last date on or before d which falls on start (i.e.,
+// the first day of the week containing d), including the weekday. The year,
+// month, and day of d must be specified.
+func (d Date) StartOfWeek(start time.Weekday) (Date, bool) {
+	t, ok := d.date()
+	if !ok || start < time.Sunday || start > time.Saturday {
+		return 0, false
+	}
+	return dateOfChecked(t.AddDate(0, 0, -((int(t.Weekday()) - int(start) + 7) % 7)))
+}
+
+// date returns midnight UTC on d if it is a valid full date.
+func (d Date) date() (time.Time, bool) {
+	year, hasYear := d.Year()
+	month, hasMonth := d.Month()
+	day, hasDay := d.Day()
+	if !hasYear || !hasMonth || !hasDay || !d.IsValid() {
+		return time.Time{}, false
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC), true
+}
+
+// dateOfChecked is like DateOf, but returns false if the year is out of range.
+func dateOfChecked(t time.Time) (Date, bool) {
+	if t.Year() < 1 || t.Year() > 9999 {
+		return 0, false
+	}
+	return DateOf(t), true
+}
+
 func daysInMonth(year int, month time.Month) int {
 	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
 }
@@ -282,6 +746,31 @@ func (d Date) Weekday() (time.Weekday, bool) {
 }
 
 func (d Date) String() string {
+	return d.FormatWith(DateFormat{})
+}
+
+// DateFormat controls how dates are formatted. By default, dates are
+// formatted in english like "Monday, January 6, 2025", with missing components
+// omitted.
+type DateFormat struct {
+	// French uses the french style (e.g., "lundi 6 janvier 2025", "du 6
+	// janvier au 6 avril").
+	French bool
+
+	// Short abbreviates month and weekday names (e.g., "Mon, Jan 6, 2025" or
+	// "lun. 6 janv. 2025").
+	Short bool
+
+	// NoWeekday omits the weekday.
+	NoWeekday bool
+
+	// NoYear omits the year.
+	NoYear bool
+}
+
+// FormatWith formats d using the options. The day is only included if the
+// month is specified.
+func (d Date) FormatWith(opt DateFormat) string {
 	if d.IsZero() {
 		return ""
 	}
@@ -292,14 +781,37 @@ func (d Date) String() string {
 		month, hasMonth = d.Month()
 		day, hasDay     = d.Day()
 	)
+	hasWkday = hasWkday && !opt.NoWeekday
+	hasYear = hasYear && !opt.NoYear
+	hasDay = hasDay && hasMonth
+	if opt.French {
+		var parts []string
+		if hasWkday {
+			parts = append(parts, WeekdayName(wkday, "fr", opt.Short))
+		}
+		if hasDay {
+			if day == 1 {
+				parts = append(parts, "1er")
+			} else {
+				parts = append(parts, strconv.Itoa(day))
+			}
+		}
+		if hasMonth {
+			parts = append(parts, MonthName(month, "fr", opt.Short))
+		}
+		if hasYear {
+			parts = append(parts, strconv.Itoa(year))
+		}
+		return strings.Join(parts, " ")
+	}
 	if hasWkday {
-		b.WriteString(wkday.String())
+		b.WriteString(WeekdayName(wkday, "en", opt.Short))
 	}
 	if hasMonth {
 		if hasWkday {
 			b.WriteString(", ")
 		}
-		b.WriteString(month.String())
+		b.WriteString(MonthName(month, "en", opt.Short))
 		if hasDay || hasYear {
 			b.WriteString(" ")
 		}
@@ -316,6 +828,45 @@ func (d Date) String() string {
 	return b.String()
 }
 
+// Compare returns -1 if d is ordered before u, 0 if they are ordered the same,
+// and +1 if d is ordered after u. Dates are ordered by the earliest day they
+// could refer to, then by the latest, so a partial date like "January" is
+// after "January 1" but before "January 2". Dates without a year are ordered
+// before ones with a year, and missing months and days are treated as the
+// whole year or month. The weekday is only compared (with an unspecified one
+// first) if the year, month, and day aren't all specified, since it is implied
+// otherwise. The order is consistent for sorting, but it doesn't distinguish
+// between dates which differ only by an implied weekday.
+func (d Date) Compare(u Date) int {
+	return cmp.Or(
+		cmp.Compare(d.lowerBound(), u.lowerBound()),
+		cmp.Compare(d.upperBound(), u.upperBound()),
+		cmp.Compare(d.partialWeekday(), u.partialWeekday()),
+	)
+}
+
+// Before checks whether d is ordered before u (see Compare).
+func (d Date) Before(u Date) bool {
+	return d.Compare(u) < 0
+}
+
+// After checks whether d is ordered after u (see Compare).
+func (d Date) After(u Date) bool {
+	return d.Compare(u) > 0
+}
+
+// partialWeekday returns the weekday component for Compare, or -1 if it is
+// unspecified or implied.
+func (d Date) partialWeekday() int {
+	_, hasYear := d.Year()
+	_, hasMonth := d.Month()
+	_, hasDay := d.Day()
+	if wkday, ok := d.Weekday(); ok && (!hasYear || !hasMonth || !hasDay) {
+		return int(wkday)
+	}
+	return -1
+}
+
 func (d Date) GoString() string {
 	var b strings.Builder
 	b.WriteString(reflect.TypeOf(d).String())
@@ -349,30 +900,47 @@ type DateRange struct {
 }
 
 func (d DateRange) String() string {
+	return d.FormatWith(DateFormat{})
+}
+
+// FormatWith formats d using the options, like "January 6 to April 6",
+// "starting January 6", or "until April 6" (or "du 6 janvier au 6 avril",
+// "à partir du 6 janvier", or "jusqu'au 6 avril" if French).
+func (d DateRange) FormatWith(opt DateFormat) string {
 	var b strings.Builder
 	if hasFrom, hasTo := !d.From.IsZero(), !d.To.IsZero(); hasFrom || hasTo {
 		if d.From == d.To {
-			return d.From.String()
+			return d.From.FormatWith(opt)
 		}
 		switch {
+		case hasFrom && !hasTo && opt.French:
+			b.WriteString("à partir du ")
 		case hasFrom && !hasTo:
 			b.WriteString("starting ")
+		case !hasFrom && hasTo && opt.French:
+			b.WriteString("jusqu'au ")
 		case !hasFrom && hasTo:
 			b.WriteString("until ")
+		case opt.French:
+			b.WriteString("du ")
 		}
 		if hasFrom {
 			if d.From.IsValid() {
-				b.WriteString(d.From.String())
+				b.WriteString(d.From.FormatWith(opt))
 			} else {
 				b.WriteString("<invalid>")
 			}
 		}
 		if hasFrom && hasTo {
-			b.WriteString(" to ")
+			if opt.French {
+				b.WriteString(" au ")
+			} else {
+				b.WriteString(" to ")
+			}
 		}
 		if hasTo {
 			if d.To.IsValid() {
-				b.WriteString(d.To.String())
+				b.WriteString(d.To.FormatWith(opt))
 			} else {
 				b.WriteString("<invalid>")
 			}
@@ -381,6 +949,169 @@ func (d DateRange) String() string {
 	return b.String()
 }
 
+// Contains checks whether d, which must have a month and day, is within the
+// range. Open sides are unbounded, and partial sides cover every date they could
+// refer to (e.g., "to January" includes January 31). Sides without both a
+// month and year, and invalid sides, are treated as open. If d or a side of
+// the range doesn't have a year, only the month and day are compared, with
+// ranges like "December 20 to January 5" wrapping around the new year.
+func (d DateRange) Contains(x Date) bool {
+	if !x.IsValid() {
+		return false
+	}
+	_, hasMonth := x.Month()
+	_, hasDay := x.Day()
+	if !hasMonth || !hasDay {
+		return false
+	}
+	v := x.lowerBound()
+	lo, hi, yearly := d.bounds()
+	if _, hasYear := x.Year(); hasYear && yearly {
+		return (lo == -1 || lo <= v) && (hi == -1 || v <= hi)
+	}
+	for _, r := range annualSpans(lo, hi, yearly) {
+		if r[0] <= v%1_00_00 && v%1_00_00 <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsTime is like Contains, but for the date of t.
+func (d DateRange) ContainsTime(t time.Time) bool {
+	return d.Contains(DateOf(t))
+}
+
+// Overlaps checks whether any date could be in both ranges, using the same
+// semantics as Contains.
+func (d DateRange) Overlaps(o DateRange) bool {
+	lo1, hi1, yearly1 := d.bounds()
+	lo2, hi2, yearly2 := o.bounds()
+	if yearly1 && yearly2 {
+		if lo1 != -1 && hi1 != -1 && lo1 > hi1 || lo2 != -1 && hi2 != -1 && lo2 > hi2 {
+			return false
+		}
+		return (lo1 == -1 || hi2 == -1 || lo1 <= hi2) && (lo2 == -1 || hi1 == -1 || lo2 <= hi1)
+	}
+	for _, a := range annualSpans(lo1, hi1, yearly1) {
+		for _, b := range annualSpans(lo2, hi2, yearly2) {
+			if a[0] <= b[1] && b[0] <= a[1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bounds returns the earliest and latest dates (as YYYYMMDD, with the year
+// zero if unspecified) the sides of d could refer to, or -1 if open, and
+// whether all bounds have a year.
+func (d DateRange) bounds() (lo, hi int, yearly bool) {
+	bound := func(x Date, upper bool) (int, bool) {
+		_, hasYear := x.Year()
+		_, hasMonth := x.Month()
+		if !x.IsValid() || !hasYear && !hasMonth {
+			return -1, true
+		}
+		if upper {
+			return x.upperBound(), hasYear
+		}
+		return x.lowerBound(), hasYear
+	}
+	lo, loYear := bound(d.From, false)
+	hi, hiYear := bound(d.To, true)
+	return lo, hi, loYear && hiYear
+}
+
+// annualSpans converts bounds into inclusive MMDD ranges.
+func annualSpans(lo, hi int, yearly bool) [][2]int {
+	all := [][2]int{{1_01, 12_31}}
+	if yearly {
+		if lo == -1 || hi == -1 {
+			return all
+		}
+		if lo > hi {
+			return nil
+		}
+		if years := hi/1_00_00 - lo/1_00_00; years > 1 || years == 1 && hi%1_00_00 >= lo%1_00_00 {
+			return all
+		}
+	}
+	l, h := 1_01, 12_31
+	if lo != -1 {
+		l = lo % 1_00_00
+	}
+	if hi != -1 {
+		h = hi % 1_00_00
+	}
+	if l > h {
+		return [][2]int{{l, 12_31}, {1_01, h}}
+	}
+	return [][2]int{{l, h}}
+}
+
+// lowerBound returns the earliest date d could refer to as YYYYMMDD, with the
+// year zero if unspecified.
+func (d Date) lowerBound() int {
+	year, _ := d.Year()
+	month, ok := d.Month()
+	if !ok {
+		month = time.January
+	}
+	day, ok := d.Day()
+	if !ok {
+		day = 1
+	}
+	return year*1_00_00 + int(month)*1_00 + day
+}
+
+// upperBound returns the latest date d could refer to as YYYYMMDD, with the
+// year zero if unspecified.
+func (d Date) upperBound() int {
+	year, hasYear := d.Year()
+	month, ok := d.Month()
+	if !ok {
+		month = time.December
+	}
+	day, ok := d.Day()
+	if !ok {
+		if hasYear {
+			day = daysInMonth(year, month)
+		} else {
+			day = daysInMonth(2024, month) // leap year for max feb days
+		}
+	}
+	return year*1_00_00 + int(month)*1_00 + day
+}
+
+// MakeTimeRange makes a TimeRange with the label and parsed fields (see
+// SetXParsed).
+func MakeTimeRange(label string, w time.Weekday, r ClockRange) *TimeRange {
+	tr := TimeRange_builder{Label: label}.Build()
+	tr.SetXParsed(w, r)
+	return tr
+}
+
+// SetXParsed sets the parsed weekday if it is valid, and the parsed start and
+// end if both are valid, clearing them otherwise. It is the inverse of
+// AsXParsed.
+func (tr *TimeRange) SetXParsed(w time.Weekday, r ClockRange) {
+	if w >= time.Sunday && w <= time.Saturday {
+		tr.SetXWkday(ToWeekday(w))
+	} else {
+		tr.ClearXWkday()
+	}
+	if r.Start.IsValid() && r.End.IsValid() {
+		tr.SetXStart(int32(r.Start))
+		tr.SetXEnd(int32(r.End))
+	} else {
+		tr.ClearXStart()
+		tr.ClearXEnd()
+	}
+}
+
+// AsXParsed returns the parsed weekday and clock range, and whether all of
+// them are set. Unset clock times are -1.
 func (tr *TimeRange) AsXParsed() (w time.Weekday, r ClockRange, ok bool) {
 	ok = true
 	if tr.HasXWkday() {
@@ -77,12 +77,107 @@ func (x Weekday) Number() protoreflect.EnumNumber {
 	return protoreflect.EnumNumber(x)
 }
 
+type Diagnostic_Severity int32
+
+const (
+	Diagnostic_ERROR   Diagnostic_Severity = 0 // information is missing or may be incorrect
+	Diagnostic_WARNING Diagnostic_Severity = 1 // information may be incomplete or was parsed on a best-effort basis
+)
+
+// Enum value maps for Diagnostic_Severity.
+var (
+	Diagnostic_Severity_name = map[int32]string{
+		0: "ERROR",
+		1: "WARNING",
+	}
+	Diagnostic_Severity_value = map[string]int32{
+		"ERROR":   0,
+		"WARNING": 1,
+	}
+)
+
+func (x Diagnostic_Severity) Enum() *Diagnostic_Severity {
+	p := new(Diagnostic_Severity)
+	*p = x
+	return p
+}
+
+func (x Diagnostic_Severity) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Diagnostic_Severity) Descriptor() protoreflect.EnumDescriptor {
+	return file_schema_proto_enumTypes[1].Descriptor()
+}
+
+func (Diagnostic_Severity) Type() protoreflect.EnumType {
+	return &file_schema_proto_enumTypes[1]
+}
+
+func (x Diagnostic_Severity) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+type Diagnostic_Stage int32
+
+const (
+	Diagnostic_OTHER   Diagnostic_Stage = 0
+	Diagnostic_FETCH   Diagnostic_Stage = 1 // fetching the source page
+	Diagnostic_PARSE   Diagnostic_Stage = 2 // extracting information from the source page
+	Diagnostic_GEOCODE Diagnostic_Stage = 3 // resolving the address
+	Diagnostic_CHECK   Diagnostic_Stage = 4 // consistency checks after scraping
+)
+
+// Enum value maps for Diagnostic_Stage.
+var (
+	Diagnostic_Stage_name = map[int32]string{
+		0: "OTHER",
+		1: "FETCH",
+		2: "PARSE",
+		3: "GEOCODE",
+		4: "CHECK",
+	}
+	Diagnostic_Stage_value = map[string]int32{
+		"OTHER":   0,
+		"FETCH":   1,
+		"PARSE":   2,
+		"GEOCODE": 3,
+		"CHECK":   4,
+	}
+)
+
+func (x Diagnostic_Stage) Enum() *Diagnostic_Stage {
+	p := new(Diagnostic_Stage)
+	*p = x
+	return p
+}
+
+func (x Diagnostic_Stage) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Diagnostic_Stage) Descriptor() protoreflect.EnumDescriptor {
+	return file_schema_proto_enumTypes[2].Descriptor()
+}
+
+func (Diagnostic_Stage) Type() protoreflect.EnumType {
+	return &file_schema_proto_enumTypes[2]
+}
+
+func (x Diagnostic_Stage) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
 type Data struct {
-	state                  protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Facilities  *[]*Facility           `protobuf:"bytes,1,rep,name=facilities"`
-	xxx_hidden_Attribution []string               `protobuf:"bytes,2,rep,name=attribution"`
-	unknownFields          protoimpl.UnknownFields
-	sizeCache              protoimpl.SizeCache
+	state                    protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Facilities    *[]*Facility           `protobuf:"bytes,1,rep,name=facilities"`
+	xxx_hidden_Attribution   []string               `protobuf:"bytes,2,rep,name=attribution"`
+	xxx_hidden_Activities    *[]*ActivityInfo       `protobuf:"bytes,3,rep,name=activities"`
+	xxx_hidden_Alerts        *[]*Alert              `protobuf:"bytes,4,rep,name=alerts"`
+	xxx_hidden_XMeta         *Meta                  `protobuf:"bytes,5,opt,name=_meta"`
+	xxx_hidden_SchemaVersion int32                  `protobuf:"varint,6,opt,name=schema_version,json=schemaVersion"`
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
 }
 
 func (x *Data) Reset() {
@@ -126,6 +221,38 @@ func (x *Data) GetAttribution() []string {
 	return nil
 }
 
+func (x *Data) GetActivities() []*ActivityInfo {
+	if x != nil {
+		if x.xxx_hidden_Activities != nil {
+			return *x.xxx_hidden_Activities
+		}
+	}
+	return nil
+}
+
+func (x *Data) GetAlerts() []*Alert {
+	if x != nil {
+		if x.xxx_hidden_Alerts != nil {
+			return *x.xxx_hidden_Alerts
+		}
+	}
+	return nil
+}
+
+func (x *Data) GetXMeta() *Meta {
+	if x != nil {
+		return x.xxx_hidden_XMeta
+	}
+	return nil
+}
+
+func (x *Data) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.xxx_hidden_SchemaVersion
+	}
+	return 0
+}
+
 func (x *Data) SetFacilities(v []*Facility) {
 	x.xxx_hidden_Facilities = &v
 }
@@ -134,11 +261,42 @@ func (x *Data) SetAttribution(v []string) {
 	x.xxx_hidden_Attribution = v
 }
 
+func (x *Data) SetActivities(v []*ActivityInfo) {
+	x.xxx_hidden_Activities = &v
+}
+
+func (x *Data) SetAlerts(v []*Alert) {
+	x.xxx_hidden_Alerts = &v
+}
+
+func (x *Data) SetXMeta(v *Meta) {
+	x.xxx_hidden_XMeta = v
+}
+
+func (x *Data) SetSchemaVersion(v int32) {
+	x.xxx_hidden_SchemaVersion = v
+}
+
+func (x *Data) HasXMeta() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_XMeta != nil
+}
+
+func (x *Data) ClearXMeta() {
+	x.xxx_hidden_XMeta = nil
+}
+
 type Data_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Facilities  []*Facility
-	Attribution []string
+	Facilities    []*Facility
+	Attribution   []string
+	Activities    []*ActivityInfo
+	Alerts        []*Alert
+	XMeta         *Meta
+	SchemaVersion int32
 }
 
 func (b0 Data_builder) Build() *Data {
@@ -147,27 +305,488 @@ func (b0 Data_builder) Build() *Data {
 	_, _ = b, x
 	x.xxx_hidden_Facilities = &b.Facilities
 	x.xxx_hidden_Attribution = b.Attribution
+	x.xxx_hidden_Activities = &b.Activities
+	x.xxx_hidden_Alerts = &b.Alerts
+	x.xxx_hidden_XMeta = b.XMeta
+	x.xxx_hidden_SchemaVersion = b.SchemaVersion
+	return m0
+}
+
+type Meta struct {
+	state                     protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_ScraperVersion string                 `protobuf:"bytes,1,opt,name=scraper_version,json=scraperVersion"`
+	xxx_hidden_RunId          string                 `protobuf:"bytes,2,opt,name=run_id,json=runId"`
+	xxx_hidden_Started        *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=started"`
+	xxx_hidden_Finished       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=finished"`
+	xxx_hidden_ListingUrls    []string               `protobuf:"bytes,5,rep,name=listing_urls,json=listingUrls"`
+	xxx_hidden_Facilities     int32                  `protobuf:"varint,6,opt,name=facilities"`
+	xxx_hidden_Schedules      int32                  `protobuf:"varint,7,opt,name=schedules"`
+	xxx_hidden_TimeRanges     int32                  `protobuf:"varint,8,opt,name=time_ranges,json=timeRanges"`
+	xxx_hidden_Errors         int32                  `protobuf:"varint,9,opt,name=errors"`
+	xxx_hidden_Warnings       int32                  `protobuf:"varint,10,opt,name=warnings"`
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *Meta) Reset() {
+	*x = Meta{}
+	mi := &file_schema_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Meta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Meta) ProtoMessage() {}
+
+func (x *Meta) ProtoReflect() protoreflect.Message {
+	mi := &file_schema_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Meta) GetScraperVersion() string {
+	if x != nil {
+		return x.xxx_hidden_ScraperVersion
+	}
+	return ""
+}
+
+func (x *Meta) GetRunId() string {
+	if x != nil {
+		return x.xxx_hidden_RunId
+	}
+	return ""
+}
+
+func (x *Meta) GetStarted() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_Started
+	}
+	return nil
+}
+
+func (x *Meta) GetFinished() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_Finished
+	}
+	return nil
+}
+
+func (x *Meta) GetListingUrls() []string {
+	if x != nil {
+		return x.xxx_hidden_ListingUrls
+	}
+	return nil
+}
+
+func (x *Meta) GetFacilities() int32 {
+	if x != nil {
+		return x.xxx_hidden_Facilities
+	}
+	return 0
+}
+
+func (x *Meta) GetSchedules() int32 {
+	if x != nil {
+		return x.xxx_hidden_Schedules
+	}
+	return 0
+}
+
+func (x *Meta) GetTimeRanges() int32 {
+	if x != nil {
+		return x.xxx_hidden_TimeRanges
+	}
+	return 0
+}
+
+func (x *Meta) GetErrors() int32 {
+	if x != nil {
+		return x.xxx_hidden_Errors
+	}
+	return 0
+}
+
+func (x *Meta) GetWarnings() int32 {
+	if x != nil {
+		return x.xxx_hidden_Warnings
+	}
+	return 0
+}
+
+func (x *Meta) SetScraperVersion(v string) {
+	x.xxx_hidden_ScraperVersion = v
+}
+
+func (x *Meta) SetRunId(v string) {
+	x.xxx_hidden_RunId = v
+}
+
+func (x *Meta) SetStarted(v *timestamppb.Timestamp) {
+	x.xxx_hidden_Started = v
+}
+
+func (x *Meta) SetFinished(v *timestamppb.Timestamp) {
+	x.xxx_hidden_Finished = v
+}
+
+func (x *Meta) SetListingUrls(v []string) {
+	x.xxx_hidden_ListingUrls = v
+}
+
+func (x *Meta) SetFacilities(v int32) {
+	x.xxx_hidden_Facilities = v
+}
+
+func (x *Meta) SetSchedules(v int32) {
+	x.xxx_hidden_Schedules = v
+}
+
+func (x *Meta) SetTimeRanges(v int32) {
+	x.xxx_hidden_TimeRanges = v
+}
+
+func (x *Meta) SetErrors(v int32) {
+	x.xxx_hidden_Errors = v
+}
+
+func (x *Meta) SetWarnings(v int32) {
+	x.xxx_hidden_Warnings = v
+}
+
+func (x *Meta) HasStarted() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Started != nil
+}
+
+func (x *Meta) HasFinished() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Finished != nil
+}
+
+func (x *Meta) ClearStarted() {
+	x.xxx_hidden_Started = nil
+}
+
+func (x *Meta) ClearFinished() {
+	x.xxx_hidden_Finished = nil
+}
+
+type Meta_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	ScraperVersion string
+	RunId          string
+	Started        *timestamppb.Timestamp
+	Finished       *timestamppb.Timestamp
+	ListingUrls    []string
+	Facilities     int32
+	Schedules      int32
+	TimeRanges     int32
+	Errors         int32
+	Warnings       int32
+}
+
+func (b0 Meta_builder) Build() *Meta {
+	m0 := &Meta{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_ScraperVersion = b.ScraperVersion
+	x.xxx_hidden_RunId = b.RunId
+	x.xxx_hidden_Started = b.Started
+	x.xxx_hidden_Finished = b.Finished
+	x.xxx_hidden_ListingUrls = b.ListingUrls
+	x.xxx_hidden_Facilities = b.Facilities
+	x.xxx_hidden_Schedules = b.Schedules
+	x.xxx_hidden_TimeRanges = b.TimeRanges
+	x.xxx_hidden_Errors = b.Errors
+	x.xxx_hidden_Warnings = b.Warnings
+	return m0
+}
+
+type Alert struct {
+	state             protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Title  string                 `protobuf:"bytes,1,opt,name=title"`
+	xxx_hidden_Html   string                 `protobuf:"bytes,2,opt,name=html"`
+	xxx_hidden_Source *Source                `protobuf:"bytes,3,opt,name=source"`
+	xxx_hidden_XDate  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=_date"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Alert) Reset() {
+	*x = Alert{}
+	mi := &file_schema_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Alert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alert) ProtoMessage() {}
+
+func (x *Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_schema_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Alert) GetTitle() string {
+	if x != nil {
+		return x.xxx_hidden_Title
+	}
+	return ""
+}
+
+func (x *Alert) GetHtml() string {
+	if x != nil {
+		return x.xxx_hidden_Html
+	}
+	return ""
+}
+
+func (x *Alert) GetSource() *Source {
+	if x != nil {
+		return x.xxx_hidden_Source
+	}
+	return nil
+}
+
+func (x *Alert) GetXDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_XDate
+	}
+	return nil
+}
+
+func (x *Alert) SetTitle(v string) {
+	x.xxx_hidden_Title = v
+}
+
+func (x *Alert) SetHtml(v string) {
+	x.xxx_hidden_Html = v
+}
+
+func (x *Alert) SetSource(v *Source) {
+	x.xxx_hidden_Source = v
+}
+
+func (x *Alert) SetXDate(v *timestamppb.Timestamp) {
+	x.xxx_hidden_XDate = v
+}
+
+func (x *Alert) HasSource() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Source != nil
+}
+
+func (x *Alert) HasXDate() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_XDate != nil
+}
+
+func (x *Alert) ClearSource() {
+	x.xxx_hidden_Source = nil
+}
+
+func (x *Alert) ClearXDate() {
+	x.xxx_hidden_XDate = nil
+}
+
+type Alert_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Title  string
+	Html   string
+	Source *Source
+	XDate  *timestamppb.Timestamp
+}
+
+func (b0 Alert_builder) Build() *Alert {
+	m0 := &Alert{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Title = b.Title
+	x.xxx_hidden_Html = b.Html
+	x.xxx_hidden_Source = b.Source
+	x.xxx_hidden_XDate = b.XDate
+	return m0
+}
+
+type ActivityInfo struct {
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_XName        string                 `protobuf:"bytes,1,opt,name=_name"`
+	xxx_hidden_Title        string                 `protobuf:"bytes,2,opt,name=title"`
+	xxx_hidden_Description  string                 `protobuf:"bytes,3,opt,name=description,json=desc"`
+	xxx_hidden_Source       *Source                `protobuf:"bytes,4,opt,name=source"`
+	xxx_hidden_XDiagnostics *[]*Diagnostic         `protobuf:"bytes,6,rep,name=_diagnostics"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *ActivityInfo) Reset() {
+	*x = ActivityInfo{}
+	mi := &file_schema_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActivityInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActivityInfo) ProtoMessage() {}
+
+func (x *ActivityInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_schema_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ActivityInfo) GetXName() string {
+	if x != nil {
+		return x.xxx_hidden_XName
+	}
+	return ""
+}
+
+func (x *ActivityInfo) GetTitle() string {
+	if x != nil {
+		return x.xxx_hidden_Title
+	}
+	return ""
+}
+
+func (x *ActivityInfo) GetDescription() string {
+	if x != nil {
+		return x.xxx_hidden_Description
+	}
+	return ""
+}
+
+func (x *ActivityInfo) GetSource() *Source {
+	if x != nil {
+		return x.xxx_hidden_Source
+	}
+	return nil
+}
+
+func (x *ActivityInfo) GetXDiagnostics() []*Diagnostic {
+	if x != nil {
+		if x.xxx_hidden_XDiagnostics != nil {
+			return *x.xxx_hidden_XDiagnostics
+		}
+	}
+	return nil
+}
+
+func (x *ActivityInfo) SetXName(v string) {
+	x.xxx_hidden_XName = v
+}
+
+func (x *ActivityInfo) SetTitle(v string) {
+	x.xxx_hidden_Title = v
+}
+
+func (x *ActivityInfo) SetDescription(v string) {
+	x.xxx_hidden_Description = v
+}
+
+func (x *ActivityInfo) SetSource(v *Source) {
+	x.xxx_hidden_Source = v
+}
+
+func (x *ActivityInfo) SetXDiagnostics(v []*Diagnostic) {
+	x.xxx_hidden_XDiagnostics = &v
+}
+
+func (x *ActivityInfo) HasSource() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Source != nil
+}
+
+func (x *ActivityInfo) ClearSource() {
+	x.xxx_hidden_Source = nil
+}
+
+type ActivityInfo_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	XName        string
+	Title        string
+	Description  string
+	Source       *Source
+	XDiagnostics []*Diagnostic
+}
+
+func (b0 ActivityInfo_builder) Build() *ActivityInfo {
+	m0 := &ActivityInfo{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_XName = b.XName
+	x.xxx_hidden_Title = b.Title
+	x.xxx_hidden_Description = b.Description
+	x.xxx_hidden_Source = b.Source
+	x.xxx_hidden_XDiagnostics = &b.XDiagnostics
 	return m0
 }
 
 type Facility struct {
-	state                        protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Name              string                 `protobuf:"bytes,1,opt,name=name"`
-	xxx_hidden_Description       string                 `protobuf:"bytes,2,opt,name=description,json=desc"`
-	xxx_hidden_Source            *Source                `protobuf:"bytes,3,opt,name=source"`
-	xxx_hidden_Address           string                 `protobuf:"bytes,4,opt,name=address"`
-	xxx_hidden_XLnglat           *LngLat                `protobuf:"bytes,5,opt,name=_lnglat"`
-	xxx_hidden_NotificationsHtml string                 `protobuf:"bytes,6,opt,name=notifications_html,json=notificationsHtml"`
-	xxx_hidden_SpecialHoursHtml  string                 `protobuf:"bytes,7,opt,name=special_hours_html,json=specialHoursHtml"`
-	xxx_hidden_ScheduleGroups    *[]*ScheduleGroup      `protobuf:"bytes,8,rep,name=schedule_groups,json=scheduleGroups"`
-	xxx_hidden_XErrors           []string               `protobuf:"bytes,9,rep,name=_errors"`
-	unknownFields                protoimpl.UnknownFields
-	sizeCache                    protoimpl.SizeCache
+	state                         protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Name               string                 `protobuf:"bytes,1,opt,name=name"`
+	xxx_hidden_Description        string                 `protobuf:"bytes,2,opt,name=description,json=desc"`
+	xxx_hidden_Source             *Source                `protobuf:"bytes,3,opt,name=source"`
+	xxx_hidden_Address            string                 `protobuf:"bytes,4,opt,name=address"`
+	xxx_hidden_XLnglat            *LngLat                `protobuf:"bytes,5,opt,name=_lnglat"`
+	xxx_hidden_NotificationsHtml  string                 `protobuf:"bytes,6,opt,name=notifications_html,json=notificationsHtml"`
+	xxx_hidden_SpecialHoursHtml   string                 `protobuf:"bytes,7,opt,name=special_hours_html,json=specialHoursHtml"`
+	xxx_hidden_ScheduleGroups     *[]*ScheduleGroup      `protobuf:"bytes,8,rep,name=schedule_groups,json=scheduleGroups"`
+	xxx_hidden_XDiagnostics       *[]*Diagnostic         `protobuf:"bytes,11,rep,name=_diagnostics"`
+	xxx_hidden_XAliases           []string               `protobuf:"bytes,10,rep,name=_aliases"`
+	xxx_hidden_XId                string                 `protobuf:"bytes,12,opt,name=_id"`
+	xxx_hidden_XTranslation       string                 `protobuf:"bytes,13,opt,name=_translation"`
+	xxx_hidden_XNotificationsHtml string                 `protobuf:"bytes,14,opt,name=_notifications_html"`
+	xxx_hidden_XSpecialHoursHtml  string                 `protobuf:"bytes,15,opt,name=_special_hours_html"`
+	unknownFields                 protoimpl.UnknownFields
+	sizeCache                     protoimpl.SizeCache
 }
 
 func (x *Facility) Reset() {
 	*x = Facility{}
-	mi := &file_schema_proto_msgTypes[1]
+	mi := &file_schema_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -179,7 +798,7 @@ func (x *Facility) String() string {
 func (*Facility) ProtoMessage() {}
 
 func (x *Facility) ProtoReflect() protoreflect.Message {
-	mi := &file_schema_proto_msgTypes[1]
+	mi := &file_schema_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -218,41 +837,78 @@ func (x *Facility) GetAddress() string {
 	return ""
 }
 
-func (x *Facility) GetXLnglat() *LngLat {
+func (x *Facility) GetXLnglat() *LngLat {
+	if x != nil {
+		return x.xxx_hidden_XLnglat
+	}
+	return nil
+}
+
+func (x *Facility) GetNotificationsHtml() string {
+	if x != nil {
+		return x.xxx_hidden_NotificationsHtml
+	}
+	return ""
+}
+
+func (x *Facility) GetSpecialHoursHtml() string {
+	if x != nil {
+		return x.xxx_hidden_SpecialHoursHtml
+	}
+	return ""
+}
+
+func (x *Facility) GetScheduleGroups() []*ScheduleGroup {
+	if x != nil {
+		if x.xxx_hidden_ScheduleGroups != nil {
+			return *x.xxx_hidden_ScheduleGroups
+		}
+	}
+	return nil
+}
+
+func (x *Facility) GetXDiagnostics() []*Diagnostic {
+	if x != nil {
+		if x.xxx_hidden_XDiagnostics != nil {
+			return *x.xxx_hidden_XDiagnostics
+		}
+	}
+	return nil
+}
+
+func (x *Facility) GetXAliases() []string {
 	if x != nil {
-		return x.xxx_hidden_XLnglat
+		return x.xxx_hidden_XAliases
 	}
 	return nil
 }
 
-func (x *Facility) GetNotificationsHtml() string {
+func (x *Facility) GetXId() string {
 	if x != nil {
-		return x.xxx_hidden_NotificationsHtml
+		return x.xxx_hidden_XId
 	}
 	return ""
 }
 
-func (x *Facility) GetSpecialHoursHtml() string {
+func (x *Facility) GetXTranslation() string {
 	if x != nil {
-		return x.xxx_hidden_SpecialHoursHtml
+		return x.xxx_hidden_XTranslation
 	}
 	return ""
 }
 
-func (x *Facility) GetScheduleGroups() []*ScheduleGroup {
+func (x *Facility) GetXNotificationsHtml() string {
 	if x != nil {
-		if x.xxx_hidden_ScheduleGroups != nil {
-			return *x.xxx_hidden_ScheduleGroups
-		}
+		return x.xxx_hidden_XNotificationsHtml
 	}
-	return nil
+	return ""
 }
 
-func (x *Facility) GetXErrors() []string {
+func (x *Facility) GetXSpecialHoursHtml() string {
 	if x != nil {
-		return x.xxx_hidden_XErrors
+		return x.xxx_hidden_XSpecialHoursHtml
 	}
-	return nil
+	return ""
 }
 
 func (x *Facility) SetName(v string) {
@@ -287,8 +943,28 @@ func (x *Facility) SetScheduleGroups(v []*ScheduleGroup) {
 	x.xxx_hidden_ScheduleGroups = &v
 }
 
-func (x *Facility) SetXErrors(v []string) {
-	x.xxx_hidden_XErrors = v
+func (x *Facility) SetXDiagnostics(v []*Diagnostic) {
+	x.xxx_hidden_XDiagnostics = &v
+}
+
+func (x *Facility) SetXAliases(v []string) {
+	x.xxx_hidden_XAliases = v
+}
+
+func (x *Facility) SetXId(v string) {
+	x.xxx_hidden_XId = v
+}
+
+func (x *Facility) SetXTranslation(v string) {
+	x.xxx_hidden_XTranslation = v
+}
+
+func (x *Facility) SetXNotificationsHtml(v string) {
+	x.xxx_hidden_XNotificationsHtml = v
+}
+
+func (x *Facility) SetXSpecialHoursHtml(v string) {
+	x.xxx_hidden_XSpecialHoursHtml = v
 }
 
 func (x *Facility) HasSource() bool {
@@ -316,15 +992,20 @@ func (x *Facility) ClearXLnglat() {
 type Facility_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Name              string
-	Description       string
-	Source            *Source
-	Address           string
-	XLnglat           *LngLat
-	NotificationsHtml string
-	SpecialHoursHtml  string
-	ScheduleGroups    []*ScheduleGroup
-	XErrors           []string
+	Name               string
+	Description        string
+	Source             *Source
+	Address            string
+	XLnglat            *LngLat
+	NotificationsHtml  string
+	SpecialHoursHtml   string
+	ScheduleGroups     []*ScheduleGroup
+	XDiagnostics       []*Diagnostic
+	XAliases           []string
+	XId                string
+	XTranslation       string
+	XNotificationsHtml string
+	XSpecialHoursHtml  string
 }
 
 func (b0 Facility_builder) Build() *Facility {
@@ -339,21 +1020,131 @@ func (b0 Facility_builder) Build() *Facility {
 	x.xxx_hidden_NotificationsHtml = b.NotificationsHtml
 	x.xxx_hidden_SpecialHoursHtml = b.SpecialHoursHtml
 	x.xxx_hidden_ScheduleGroups = &b.ScheduleGroups
-	x.xxx_hidden_XErrors = b.XErrors
+	x.xxx_hidden_XDiagnostics = &b.XDiagnostics
+	x.xxx_hidden_XAliases = b.XAliases
+	x.xxx_hidden_XId = b.XId
+	x.xxx_hidden_XTranslation = b.XTranslation
+	x.xxx_hidden_XNotificationsHtml = b.XNotificationsHtml
+	x.xxx_hidden_XSpecialHoursHtml = b.XSpecialHoursHtml
+	return m0
+}
+
+type Diagnostic struct {
+	state               protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Severity Diagnostic_Severity    `protobuf:"varint,1,opt,name=severity,enum=ottrec.v1.Diagnostic_Severity"`
+	xxx_hidden_Stage    Diagnostic_Stage       `protobuf:"varint,2,opt,name=stage,enum=ottrec.v1.Diagnostic_Stage"`
+	xxx_hidden_Message  string                 `protobuf:"bytes,3,opt,name=message"`
+	xxx_hidden_Context  string                 `protobuf:"bytes,4,opt,name=context"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Diagnostic) Reset() {
+	*x = Diagnostic{}
+	mi := &file_schema_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Diagnostic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Diagnostic) ProtoMessage() {}
+
+func (x *Diagnostic) ProtoReflect() protoreflect.Message {
+	mi := &file_schema_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Diagnostic) GetSeverity() Diagnostic_Severity {
+	if x != nil {
+		return x.xxx_hidden_Severity
+	}
+	return Diagnostic_ERROR
+}
+
+func (x *Diagnostic) GetStage() Diagnostic_Stage {
+	if x != nil {
+		return x.xxx_hidden_Stage
+	}
+	return Diagnostic_OTHER
+}
+
+func (x *Diagnostic) GetMessage() string {
+	if x != nil {
+		return x.xxx_hidden_Message
+	}
+	return ""
+}
+
+func (x *Diagnostic) GetContext() string {
+	if x != nil {
+		return x.xxx_hidden_Context
+	}
+	return ""
+}
+
+func (x *Diagnostic) SetSeverity(v Diagnostic_Severity) {
+	x.xxx_hidden_Severity = v
+}
+
+func (x *Diagnostic) SetStage(v Diagnostic_Stage) {
+	x.xxx_hidden_Stage = v
+}
+
+func (x *Diagnostic) SetMessage(v string) {
+	x.xxx_hidden_Message = v
+}
+
+func (x *Diagnostic) SetContext(v string) {
+	x.xxx_hidden_Context = v
+}
+
+type Diagnostic_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Severity Diagnostic_Severity
+	Stage    Diagnostic_Stage
+	Message  string
+	Context  string
+}
+
+func (b0 Diagnostic_builder) Build() *Diagnostic {
+	m0 := &Diagnostic{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Severity = b.Severity
+	x.xxx_hidden_Stage = b.Stage
+	x.xxx_hidden_Message = b.Message
+	x.xxx_hidden_Context = b.Context
 	return m0
 }
 
 type Source struct {
-	state            protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Url   string                 `protobuf:"bytes,1,opt,name=url"`
-	xxx_hidden_XDate *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=_date"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	state               protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Url      string                 `protobuf:"bytes,1,opt,name=url"`
+	xxx_hidden_XDate    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=_date"`
+	xxx_hidden_XStatus  int32                  `protobuf:"varint,3,opt,name=_status"`
+	xxx_hidden_XHash    string                 `protobuf:"bytes,4,opt,name=_hash"`
+	xxx_hidden_XCached  bool                   `protobuf:"varint,5,opt,name=_cached"`
+	xxx_hidden_XChannel string                 `protobuf:"bytes,6,opt,name=_channel"`
+	xxx_hidden_XLang    string                 `protobuf:"bytes,7,opt,name=_lang"`
+	xxx_hidden_XUrl     string                 `protobuf:"bytes,8,opt,name=_url"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *Source) Reset() {
 	*x = Source{}
-	mi := &file_schema_proto_msgTypes[2]
+	mi := &file_schema_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -365,7 +1156,7 @@ func (x *Source) String() string {
 func (*Source) ProtoMessage() {}
 
 func (x *Source) ProtoReflect() protoreflect.Message {
-	mi := &file_schema_proto_msgTypes[2]
+	mi := &file_schema_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -390,6 +1181,48 @@ func (x *Source) GetXDate() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Source) GetXStatus() int32 {
+	if x != nil {
+		return x.xxx_hidden_XStatus
+	}
+	return 0
+}
+
+func (x *Source) GetXHash() string {
+	if x != nil {
+		return x.xxx_hidden_XHash
+	}
+	return ""
+}
+
+func (x *Source) GetXCached() bool {
+	if x != nil {
+		return x.xxx_hidden_XCached
+	}
+	return false
+}
+
+func (x *Source) GetXChannel() string {
+	if x != nil {
+		return x.xxx_hidden_XChannel
+	}
+	return ""
+}
+
+func (x *Source) GetXLang() string {
+	if x != nil {
+		return x.xxx_hidden_XLang
+	}
+	return ""
+}
+
+func (x *Source) GetXUrl() string {
+	if x != nil {
+		return x.xxx_hidden_XUrl
+	}
+	return ""
+}
+
 func (x *Source) SetUrl(v string) {
 	x.xxx_hidden_Url = v
 }
@@ -398,6 +1231,30 @@ func (x *Source) SetXDate(v *timestamppb.Timestamp) {
 	x.xxx_hidden_XDate = v
 }
 
+func (x *Source) SetXStatus(v int32) {
+	x.xxx_hidden_XStatus = v
+}
+
+func (x *Source) SetXHash(v string) {
+	x.xxx_hidden_XHash = v
+}
+
+func (x *Source) SetXCached(v bool) {
+	x.xxx_hidden_XCached = v
+}
+
+func (x *Source) SetXChannel(v string) {
+	x.xxx_hidden_XChannel = v
+}
+
+func (x *Source) SetXLang(v string) {
+	x.xxx_hidden_XLang = v
+}
+
+func (x *Source) SetXUrl(v string) {
+	x.xxx_hidden_XUrl = v
+}
+
 func (x *Source) HasXDate() bool {
 	if x == nil {
 		return false
@@ -412,8 +1269,14 @@ func (x *Source) ClearXDate() {
 type Source_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Url   string
-	XDate *timestamppb.Timestamp
+	Url      string
+	XDate    *timestamppb.Timestamp
+	XStatus  int32
+	XHash    string
+	XCached  bool
+	XChannel string
+	XLang    string
+	XUrl     string
 }
 
 func (b0 Source_builder) Build() *Source {
@@ -422,6 +1285,12 @@ func (b0 Source_builder) Build() *Source {
 	_, _ = b, x
 	x.xxx_hidden_Url = b.Url
 	x.xxx_hidden_XDate = b.XDate
+	x.xxx_hidden_XStatus = b.XStatus
+	x.xxx_hidden_XHash = b.XHash
+	x.xxx_hidden_XCached = b.XCached
+	x.xxx_hidden_XChannel = b.XChannel
+	x.xxx_hidden_XLang = b.XLang
+	x.xxx_hidden_XUrl = b.XUrl
 	return m0
 }
 
@@ -435,7 +1304,7 @@ type LngLat struct {
 
 func (x *LngLat) Reset() {
 	*x = LngLat{}
-	mi := &file_schema_proto_msgTypes[3]
+	mi := &file_schema_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -447,7 +1316,7 @@ func (x *LngLat) String() string {
 func (*LngLat) ProtoMessage() {}
 
 func (x *LngLat) ProtoReflect() protoreflect.Message {
-	mi := &file_schema_proto_msgTypes[3]
+	mi := &file_schema_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -497,20 +1366,22 @@ func (b0 LngLat_builder) Build() *LngLat {
 }
 
 type ScheduleGroup struct {
-	state                          protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Label               string                 `protobuf:"bytes,1,opt,name=label"`
-	xxx_hidden_XTitle              string                 `protobuf:"bytes,2,opt,name=_title"`
-	xxx_hidden_ScheduleChangesHtml string                 `protobuf:"bytes,3,opt,name=schedule_changes_html,json=scheduleChangesHtml"`
-	xxx_hidden_Schedules           *[]*Schedule           `protobuf:"bytes,4,rep,name=schedules"`
-	xxx_hidden_ReservationLinks    *[]*ReservationLink    `protobuf:"bytes,5,rep,name=reservation_links,json=reservationLinks"`
-	xxx_hidden_XNoresv             bool                   `protobuf:"varint,6,opt,name=_noresv"`
-	unknownFields                  protoimpl.UnknownFields
-	sizeCache                      protoimpl.SizeCache
+	state                           protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Label                string                 `protobuf:"bytes,1,opt,name=label"`
+	xxx_hidden_XTitle               string                 `protobuf:"bytes,2,opt,name=_title"`
+	xxx_hidden_ScheduleChangesHtml  string                 `protobuf:"bytes,3,opt,name=schedule_changes_html,json=scheduleChangesHtml"`
+	xxx_hidden_XScheduleChangesHtml string                 `protobuf:"bytes,8,opt,name=_schedule_changes_html"`
+	xxx_hidden_Schedules            *[]*Schedule           `protobuf:"bytes,4,rep,name=schedules"`
+	xxx_hidden_ReservationLinks     *[]*ReservationLink    `protobuf:"bytes,5,rep,name=reservation_links,json=reservationLinks"`
+	xxx_hidden_XNoresv              bool                   `protobuf:"varint,6,opt,name=_noresv"`
+	xxx_hidden_XHolidays            []string               `protobuf:"bytes,7,rep,name=_holidays"`
+	unknownFields                   protoimpl.UnknownFields
+	sizeCache                       protoimpl.SizeCache
 }
 
 func (x *ScheduleGroup) Reset() {
 	*x = ScheduleGroup{}
-	mi := &file_schema_proto_msgTypes[4]
+	mi := &file_schema_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -522,7 +1393,7 @@ func (x *ScheduleGroup) String() string {
 func (*ScheduleGroup) ProtoMessage() {}
 
 func (x *ScheduleGroup) ProtoReflect() protoreflect.Message {
-	mi := &file_schema_proto_msgTypes[4]
+	mi := &file_schema_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -554,6 +1425,13 @@ func (x *ScheduleGroup) GetScheduleChangesHtml() string {
 	return ""
 }
 
+func (x *ScheduleGroup) GetXScheduleChangesHtml() string {
+	if x != nil {
+		return x.xxx_hidden_XScheduleChangesHtml
+	}
+	return ""
+}
+
 func (x *ScheduleGroup) GetSchedules() []*Schedule {
 	if x != nil {
 		if x.xxx_hidden_Schedules != nil {
@@ -579,6 +1457,13 @@ func (x *ScheduleGroup) GetXNoresv() bool {
 	return false
 }
 
+func (x *ScheduleGroup) GetXHolidays() []string {
+	if x != nil {
+		return x.xxx_hidden_XHolidays
+	}
+	return nil
+}
+
 func (x *ScheduleGroup) SetLabel(v string) {
 	x.xxx_hidden_Label = v
 }
@@ -591,6 +1476,10 @@ func (x *ScheduleGroup) SetScheduleChangesHtml(v string) {
 	x.xxx_hidden_ScheduleChangesHtml = v
 }
 
+func (x *ScheduleGroup) SetXScheduleChangesHtml(v string) {
+	x.xxx_hidden_XScheduleChangesHtml = v
+}
+
 func (x *ScheduleGroup) SetSchedules(v []*Schedule) {
 	x.xxx_hidden_Schedules = &v
 }
@@ -603,15 +1492,21 @@ func (x *ScheduleGroup) SetXNoresv(v bool) {
 	x.xxx_hidden_XNoresv = v
 }
 
+func (x *ScheduleGroup) SetXHolidays(v []string) {
+	x.xxx_hidden_XHolidays = v
+}
+
 type ScheduleGroup_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Label               string
-	XTitle              string
-	ScheduleChangesHtml string
-	Schedules           []*Schedule
-	ReservationLinks    []*ReservationLink
-	XNoresv             bool
+	Label                string
+	XTitle               string
+	ScheduleChangesHtml  string
+	XScheduleChangesHtml string
+	Schedules            []*Schedule
+	ReservationLinks     []*ReservationLink
+	XNoresv              bool
+	XHolidays            []string
 }
 
 func (b0 ScheduleGroup_builder) Build() *ScheduleGroup {
@@ -621,9 +1516,11 @@ func (b0 ScheduleGroup_builder) Build() *ScheduleGroup {
 	x.xxx_hidden_Label = b.Label
 	x.xxx_hidden_XTitle = b.XTitle
 	x.xxx_hidden_ScheduleChangesHtml = b.ScheduleChangesHtml
+	x.xxx_hidden_XScheduleChangesHtml = b.XScheduleChangesHtml
 	x.xxx_hidden_Schedules = &b.Schedules
 	x.xxx_hidden_ReservationLinks = &b.ReservationLinks
 	x.xxx_hidden_XNoresv = b.XNoresv
+	x.xxx_hidden_XHolidays = b.XHolidays
 	return m0
 }
 
@@ -636,6 +1533,7 @@ type Schedule struct {
 	xxx_hidden_XTo         int32                  `protobuf:"varint,7,opt,name=_to"`
 	xxx_hidden_Days        []string               `protobuf:"bytes,3,rep,name=days"`
 	xxx_hidden_XDaydates   []int32                `protobuf:"varint,8,rep,packed,name=_daydates"`
+	xxx_hidden_XHoliday    string                 `protobuf:"bytes,9,opt,name=_holiday"`
 	xxx_hidden_Activities  *[]*Schedule_Activity  `protobuf:"bytes,4,rep,name=activities"`
 	XXX_raceDetectHookData protoimpl.RaceDetectHookData
 	XXX_presence           [1]uint32
@@ -645,7 +1543,7 @@ type Schedule struct {
 
 func (x *Schedule) Reset() {
 	*x = Schedule{}
-	mi := &file_schema_proto_msgTypes[5]
+	mi := &file_schema_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -657,7 +1555,7 @@ func (x *Schedule) String() string {
 func (*Schedule) ProtoMessage() {}
 
 func (x *Schedule) ProtoReflect() protoreflect.Message {
-	mi := &file_schema_proto_msgTypes[5]
+	mi := &file_schema_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -717,6 +1615,13 @@ func (x *Schedule) GetXDaydates() []int32 {
 	return nil
 }
 
+func (x *Schedule) GetXHoliday() string {
+	if x != nil {
+		return x.xxx_hidden_XHoliday
+	}
+	return ""
+}
+
 func (x *Schedule) GetActivities() []*Schedule_Activity {
 	if x != nil {
 		if x.xxx_hidden_Activities != nil {
@@ -740,12 +1645,12 @@ func (x *Schedule) SetXDate(v string) {
 
 func (x *Schedule) SetXFrom(v int32) {
 	x.xxx_hidden_XFrom = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 8)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 9)
 }
 
 func (x *Schedule) SetXTo(v int32) {
 	x.xxx_hidden_XTo = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 8)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 9)
 }
 
 func (x *Schedule) SetDays(v []string) {
@@ -756,6 +1661,10 @@ func (x *Schedule) SetXDaydates(v []int32) {
 	x.xxx_hidden_XDaydates = v
 }
 
+func (x *Schedule) SetXHoliday(v string) {
+	x.xxx_hidden_XHoliday = v
+}
+
 func (x *Schedule) SetActivities(v []*Schedule_Activity) {
 	x.xxx_hidden_Activities = &v
 }
@@ -794,6 +1703,7 @@ type Schedule_builder struct {
 	XTo        *int32
 	Days       []string
 	XDaydates  []int32
+	XHoliday   string
 	Activities []*Schedule_Activity
 }
 
@@ -805,15 +1715,16 @@ func (b0 Schedule_builder) Build() *Schedule {
 	x.xxx_hidden_XName = b.XName
 	x.xxx_hidden_XDate = b.XDate
 	if b.XFrom != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 8)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 9)
 		x.xxx_hidden_XFrom = *b.XFrom
 	}
 	if b.XTo != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 8)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 9)
 		x.xxx_hidden_XTo = *b.XTo
 	}
 	x.xxx_hidden_Days = b.Days
 	x.xxx_hidden_XDaydates = b.XDaydates
+	x.xxx_hidden_XHoliday = b.XHoliday
 	x.xxx_hidden_Activities = &b.Activities
 	return m0
 }
@@ -832,7 +1743,7 @@ type TimeRange struct {
 
 func (x *TimeRange) Reset() {
 	*x = TimeRange{}
-	mi := &file_schema_proto_msgTypes[6]
+	mi := &file_schema_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -844,7 +1755,7 @@ func (x *TimeRange) String() string {
 func (*TimeRange) ProtoMessage() {}
 
 func (x *TimeRange) ProtoReflect() protoreflect.Message {
-	mi := &file_schema_proto_msgTypes[6]
+	mi := &file_schema_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -979,7 +1890,7 @@ type ReservationLink struct {
 
 func (x *ReservationLink) Reset() {
 	*x = ReservationLink{}
-	mi := &file_schema_proto_msgTypes[7]
+	mi := &file_schema_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -991,7 +1902,7 @@ func (x *ReservationLink) String() string {
 func (*ReservationLink) ProtoMessage() {}
 
 func (x *ReservationLink) ProtoReflect() protoreflect.Message {
-	mi := &file_schema_proto_msgTypes[7]
+	mi := &file_schema_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1040,6 +1951,77 @@ func (b0 ReservationLink_builder) Build() *ReservationLink {
 	return m0
 }
 
+type Link struct {
+	state            protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Label string                 `protobuf:"bytes,1,opt,name=label"`
+	xxx_hidden_Url   string                 `protobuf:"bytes,2,opt,name=url"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Link) Reset() {
+	*x = Link{}
+	mi := &file_schema_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Link) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Link) ProtoMessage() {}
+
+func (x *Link) ProtoReflect() protoreflect.Message {
+	mi := &file_schema_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Link) GetLabel() string {
+	if x != nil {
+		return x.xxx_hidden_Label
+	}
+	return ""
+}
+
+func (x *Link) GetUrl() string {
+	if x != nil {
+		return x.xxx_hidden_Url
+	}
+	return ""
+}
+
+func (x *Link) SetLabel(v string) {
+	x.xxx_hidden_Label = v
+}
+
+func (x *Link) SetUrl(v string) {
+	x.xxx_hidden_Url = v
+}
+
+type Link_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Label string
+	Url   string
+}
+
+func (b0 Link_builder) Build() *Link {
+	m0 := &Link{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Label = b.Label
+	x.xxx_hidden_Url = b.Url
+	return m0
+}
+
 type Schedule_ActivityDay struct {
 	state            protoimpl.MessageState `protogen:"opaque.v1"`
 	xxx_hidden_Times *[]*TimeRange          `protobuf:"bytes,1,rep,name=times"`
@@ -1049,7 +2031,7 @@ type Schedule_ActivityDay struct {
 
 func (x *Schedule_ActivityDay) Reset() {
 	*x = Schedule_ActivityDay{}
-	mi := &file_schema_proto_msgTypes[8]
+	mi := &file_schema_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1061,7 +2043,7 @@ func (x *Schedule_ActivityDay) String() string {
 func (*Schedule_ActivityDay) ProtoMessage() {}
 
 func (x *Schedule_ActivityDay) ProtoReflect() protoreflect.Message {
-	mi := &file_schema_proto_msgTypes[8]
+	mi := &file_schema_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1103,8 +2085,10 @@ type Schedule_Activity struct {
 	state                  protoimpl.MessageState   `protogen:"opaque.v1"`
 	xxx_hidden_Label       string                   `protobuf:"bytes,1,opt,name=label"`
 	xxx_hidden_XName       string                   `protobuf:"bytes,2,opt,name=_name"`
+	xxx_hidden_XVenue      string                   `protobuf:"bytes,6,opt,name=_venue"`
 	xxx_hidden_XResv       bool                     `protobuf:"varint,4,opt,name=_resv"`
 	xxx_hidden_Days        *[]*Schedule_ActivityDay `protobuf:"bytes,3,rep,name=days"`
+	xxx_hidden_Links       *[]*Link                 `protobuf:"bytes,5,rep,name=links"`
 	XXX_raceDetectHookData protoimpl.RaceDetectHookData
 	XXX_presence           [1]uint32
 	unknownFields          protoimpl.UnknownFields
@@ -1113,7 +2097,7 @@ type Schedule_Activity struct {
 
 func (x *Schedule_Activity) Reset() {
 	*x = Schedule_Activity{}
-	mi := &file_schema_proto_msgTypes[9]
+	mi := &file_schema_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1125,7 +2109,7 @@ func (x *Schedule_Activity) String() string {
 func (*Schedule_Activity) ProtoMessage() {}
 
 func (x *Schedule_Activity) ProtoReflect() protoreflect.Message {
-	mi := &file_schema_proto_msgTypes[9]
+	mi := &file_schema_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1150,6 +2134,13 @@ func (x *Schedule_Activity) GetXName() string {
 	return ""
 }
 
+func (x *Schedule_Activity) GetXVenue() string {
+	if x != nil {
+		return x.xxx_hidden_XVenue
+	}
+	return ""
+}
+
 func (x *Schedule_Activity) GetXResv() bool {
 	if x != nil {
 		return x.xxx_hidden_XResv
@@ -1166,6 +2157,15 @@ func (x *Schedule_Activity) GetDays() []*Schedule_ActivityDay {
 	return nil
 }
 
+func (x *Schedule_Activity) GetLinks() []*Link {
+	if x != nil {
+		if x.xxx_hidden_Links != nil {
+			return *x.xxx_hidden_Links
+		}
+	}
+	return nil
+}
+
 func (x *Schedule_Activity) SetLabel(v string) {
 	x.xxx_hidden_Label = v
 }
@@ -1174,34 +2174,44 @@ func (x *Schedule_Activity) SetXName(v string) {
 	x.xxx_hidden_XName = v
 }
 
+func (x *Schedule_Activity) SetXVenue(v string) {
+	x.xxx_hidden_XVenue = v
+}
+
 func (x *Schedule_Activity) SetXResv(v bool) {
 	x.xxx_hidden_XResv = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 6)
 }
 
 func (x *Schedule_Activity) SetDays(v []*Schedule_ActivityDay) {
 	x.xxx_hidden_Days = &v
 }
 
+func (x *Schedule_Activity) SetLinks(v []*Link) {
+	x.xxx_hidden_Links = &v
+}
+
 func (x *Schedule_Activity) HasXResv() bool {
 	if x == nil {
 		return false
 	}
-	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
 }
 
 func (x *Schedule_Activity) ClearXResv() {
-	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
 	x.xxx_hidden_XResv = false
 }
 
 type Schedule_Activity_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Label string
-	XName string
-	XResv *bool
-	Days  []*Schedule_ActivityDay
+	Label  string
+	XName  string
+	XVenue string
+	XResv  *bool
+	Days   []*Schedule_ActivityDay
+	Links  []*Link
 }
 
 func (b0 Schedule_Activity_builder) Build() *Schedule_Activity {
@@ -1210,11 +2220,13 @@ func (b0 Schedule_Activity_builder) Build() *Schedule_Activity {
 	_, _ = b, x
 	x.xxx_hidden_Label = b.Label
 	x.xxx_hidden_XName = b.XName
+	x.xxx_hidden_XVenue = b.XVenue
 	if b.XResv != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 6)
 		x.xxx_hidden_XResv = *b.XResv
 	}
 	x.xxx_hidden_Days = &b.Days
+	x.xxx_hidden_Links = &b.Links
 	return m0
 }
 
@@ -1222,12 +2234,44 @@ var File_schema_proto protoreflect.FileDescriptor
 
 const file_schema_proto_rawDesc = "" +
 	"\n" +
-	"\fschema.proto\x12\tottrec.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"]\n" +
+	"\fschema.proto\x12\tottrec.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8e\x02\n" +
 	"\x04Data\x123\n" +
 	"\n" +
 	"facilities\x18\x01 \x03(\v2\x13.ottrec.v1.FacilityR\n" +
 	"facilities\x12 \n" +
-	"\vattribution\x18\x02 \x03(\tR\vattribution\"\xec\x02\n" +
+	"\vattribution\x18\x02 \x03(\tR\vattribution\x127\n" +
+	"\n" +
+	"activities\x18\x03 \x03(\v2\x17.ottrec.v1.ActivityInfoR\n" +
+	"activities\x12(\n" +
+	"\x06alerts\x18\x04 \x03(\v2\x10.ottrec.v1.AlertR\x06alerts\x12%\n" +
+	"\x05_meta\x18\x05 \x01(\v2\x0f.ottrec.v1.MetaR\x05_meta\x12%\n" +
+	"\x0eschema_version\x18\x06 \x01(\x05R\rschemaVersion\"\xf8\x02\n" +
+	"\x04Meta\x12'\n" +
+	"\x0fscraper_version\x18\x01 \x01(\tR\x0escraperVersion\x12\x15\n" +
+	"\x06run_id\x18\x02 \x01(\tR\x05runId\x12;\n" +
+	"\astarted\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampB\x05\xaa\x01\x02\b\x01R\astarted\x12=\n" +
+	"\bfinished\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampB\x05\xaa\x01\x02\b\x01R\bfinished\x12!\n" +
+	"\flisting_urls\x18\x05 \x03(\tR\vlistingUrls\x12\x1e\n" +
+	"\n" +
+	"facilities\x18\x06 \x01(\x05R\n" +
+	"facilities\x12\x1c\n" +
+	"\tschedules\x18\a \x01(\x05R\tschedules\x12\x1f\n" +
+	"\vtime_ranges\x18\b \x01(\x05R\n" +
+	"timeRanges\x12\x16\n" +
+	"\x06errors\x18\t \x01(\x05R\x06errors\x12\x1a\n" +
+	"\bwarnings\x18\n" +
+	" \x01(\x05R\bwarnings\"\x95\x01\n" +
+	"\x05Alert\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x12\n" +
+	"\x04html\x18\x02 \x01(\tR\x04html\x12)\n" +
+	"\x06source\x18\x03 \x01(\v2\x11.ottrec.v1.SourceR\x06source\x127\n" +
+	"\x05_date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampB\x05\xaa\x01\x02\b\x01R\x05_date\"\xca\x01\n" +
+	"\fActivityInfo\x12\x14\n" +
+	"\x05_name\x18\x01 \x01(\tR\x05_name\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x19\n" +
+	"\vdescription\x18\x03 \x01(\tR\x04desc\x12)\n" +
+	"\x06source\x18\x04 \x01(\v2\x11.ottrec.v1.SourceR\x06source\x129\n" +
+	"\f_diagnostics\x18\x06 \x03(\v2\x15.ottrec.v1.DiagnosticR\f_diagnosticsJ\x04\b\x05\x10\x06R\a_errors\"\xd2\x04\n" +
 	"\bFacility\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x19\n" +
 	"\vdescription\x18\x02 \x01(\tR\x04desc\x12)\n" +
@@ -1236,21 +2280,51 @@ const file_schema_proto_rawDesc = "" +
 	"\a_lnglat\x18\x05 \x01(\v2\x11.ottrec.v1.LngLatB\x05\xaa\x01\x02\b\x01R\a_lnglat\x12-\n" +
 	"\x12notifications_html\x18\x06 \x01(\tR\x11notificationsHtml\x12,\n" +
 	"\x12special_hours_html\x18\a \x01(\tR\x10specialHoursHtml\x12A\n" +
-	"\x0fschedule_groups\x18\b \x03(\v2\x18.ottrec.v1.ScheduleGroupR\x0escheduleGroups\x12\x18\n" +
-	"\a_errors\x18\t \x03(\tR\a_errors\"S\n" +
+	"\x0fschedule_groups\x18\b \x03(\v2\x18.ottrec.v1.ScheduleGroupR\x0escheduleGroups\x129\n" +
+	"\f_diagnostics\x18\v \x03(\v2\x15.ottrec.v1.DiagnosticR\f_diagnostics\x12\x1a\n" +
+	"\b_aliases\x18\n" +
+	" \x03(\tR\b_aliases\x12\x10\n" +
+	"\x03_id\x18\f \x01(\tR\x03_id\x12\"\n" +
+	"\f_translation\x18\r \x01(\tR\f_translation\x120\n" +
+	"\x13_notifications_html\x18\x0e \x01(\tR\x13_notifications_html\x120\n" +
+	"\x13_special_hours_html\x18\x0f \x01(\tR\x13_special_hours_htmlJ\x04\b\t\x10\n" +
+	"R\a_errors\"\x95\x02\n" +
+	"\n" +
+	"Diagnostic\x12:\n" +
+	"\bseverity\x18\x01 \x01(\x0e2\x1e.ottrec.v1.Diagnostic.SeverityR\bseverity\x121\n" +
+	"\x05stage\x18\x02 \x01(\x0e2\x1b.ottrec.v1.Diagnostic.StageR\x05stage\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x18\n" +
+	"\acontext\x18\x04 \x01(\tR\acontext\"\"\n" +
+	"\bSeverity\x12\t\n" +
+	"\x05ERROR\x10\x00\x12\v\n" +
+	"\aWARNING\x10\x01\"@\n" +
+	"\x05Stage\x12\t\n" +
+	"\x05OTHER\x10\x00\x12\t\n" +
+	"\x05FETCH\x10\x01\x12\t\n" +
+	"\x05PARSE\x10\x02\x12\v\n" +
+	"\aGEOCODE\x10\x03\x12\t\n" +
+	"\x05CHECK\x10\x04\"\xe3\x01\n" +
 	"\x06Source\x12\x10\n" +
 	"\x03url\x18\x01 \x01(\tR\x03url\x127\n" +
-	"\x05_date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampB\x05\xaa\x01\x02\b\x01R\x05_date\",\n" +
+	"\x05_date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampB\x05\xaa\x01\x02\b\x01R\x05_date\x12\x18\n" +
+	"\a_status\x18\x03 \x01(\x05R\a_status\x12\x14\n" +
+	"\x05_hash\x18\x04 \x01(\tR\x05_hash\x12\x18\n" +
+	"\a_cached\x18\x05 \x01(\bR\a_cached\x12\x1a\n" +
+	"\b_channel\x18\x06 \x01(\tR\b_channel\x12\x14\n" +
+	"\x05_lang\x18\a \x01(\tR\x05_lang\x12\x12\n" +
+	"\x04_url\x18\b \x01(\tR\x04_url\",\n" +
 	"\x06LngLat\x12\x10\n" +
 	"\x03lng\x18\x01 \x01(\x02R\x03lng\x12\x10\n" +
-	"\x03lat\x18\x02 \x01(\x02R\x03lat\"\x87\x02\n" +
+	"\x03lat\x18\x02 \x01(\x02R\x03lat\"\xdd\x02\n" +
 	"\rScheduleGroup\x12\x14\n" +
 	"\x05label\x18\x01 \x01(\tR\x05label\x12\x16\n" +
 	"\x06_title\x18\x02 \x01(\tR\x06_title\x122\n" +
-	"\x15schedule_changes_html\x18\x03 \x01(\tR\x13scheduleChangesHtml\x121\n" +
+	"\x15schedule_changes_html\x18\x03 \x01(\tR\x13scheduleChangesHtml\x126\n" +
+	"\x16_schedule_changes_html\x18\b \x01(\tR\x16_schedule_changes_html\x121\n" +
 	"\tschedules\x18\x04 \x03(\v2\x13.ottrec.v1.ScheduleR\tschedules\x12G\n" +
 	"\x11reservation_links\x18\x05 \x03(\v2\x1a.ottrec.v1.ReservationLinkR\x10reservationLinks\x12\x18\n" +
-	"\a_noresv\x18\x06 \x01(\bR\a_noresv\"\xbc\x03\n" +
+	"\a_noresv\x18\x06 \x01(\bR\a_noresv\x12\x1c\n" +
+	"\t_holidays\x18\a \x03(\tR\t_holidays\"\x97\x04\n" +
 	"\bSchedule\x12\x18\n" +
 	"\acaption\x18\x01 \x01(\tR\acaption\x12\x14\n" +
 	"\x05_name\x18\x02 \x01(\tR\x05_name\x12\x14\n" +
@@ -1258,17 +2332,20 @@ const file_schema_proto_rawDesc = "" +
 	"\x05_from\x18\x06 \x01(\x05B\x05\xaa\x01\x02\b\x01R\x05_from\x12\x17\n" +
 	"\x03_to\x18\a \x01(\x05B\x05\xaa\x01\x02\b\x01R\x03_to\x12\x12\n" +
 	"\x04days\x18\x03 \x03(\tR\x04days\x12\x1c\n" +
-	"\t_daydates\x18\b \x03(\x05R\t_daydates\x12<\n" +
+	"\t_daydates\x18\b \x03(\x05R\t_daydates\x12\x1a\n" +
+	"\b_holiday\x18\t \x01(\tR\b_holiday\x12<\n" +
 	"\n" +
 	"activities\x18\x04 \x03(\v2\x1c.ottrec.v1.Schedule.ActivityR\n" +
 	"activities\x1a9\n" +
 	"\vActivityDay\x12*\n" +
-	"\x05times\x18\x01 \x03(\v2\x14.ottrec.v1.TimeRangeR\x05times\x1a\x88\x01\n" +
+	"\x05times\x18\x01 \x03(\v2\x14.ottrec.v1.TimeRangeR\x05times\x1a\xc7\x01\n" +
 	"\bActivity\x12\x14\n" +
 	"\x05label\x18\x01 \x01(\tR\x05label\x12\x14\n" +
-	"\x05_name\x18\x02 \x01(\tR\x05_name\x12\x1b\n" +
+	"\x05_name\x18\x02 \x01(\tR\x05_name\x12\x16\n" +
+	"\x06_venue\x18\x06 \x01(\tR\x06_venue\x12\x1b\n" +
 	"\x05_resv\x18\x04 \x01(\bB\x05\xaa\x01\x02\b\x01R\x05_resv\x123\n" +
-	"\x04days\x18\x03 \x03(\v2\x1f.ottrec.v1.Schedule.ActivityDayR\x04days\"\x8e\x01\n" +
+	"\x04days\x18\x03 \x03(\v2\x1f.ottrec.v1.Schedule.ActivityDayR\x04days\x12%\n" +
+	"\x05links\x18\x05 \x03(\v2\x0f.ottrec.v1.LinkR\x05links\"\x8e\x01\n" +
 	"\tTimeRange\x12\x14\n" +
 	"\x05label\x18\x01 \x01(\tR\x05label\x12\x1d\n" +
 	"\x06_start\x18\x02 \x01(\x05B\x05\xaa\x01\x02\b\x01R\x06_start\x12\x19\n" +
@@ -1276,6 +2353,9 @@ const file_schema_proto_rawDesc = "" +
 	"\x06_wkday\x18\x04 \x01(\x0e2\x12.ottrec.v1.WeekdayB\x05\xaa\x01\x02\b\x01R\x06_wkday\"9\n" +
 	"\x0fReservationLink\x12\x14\n" +
 	"\x05label\x18\x01 \x01(\tR\x05label\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\".\n" +
+	"\x04Link\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x10\n" +
 	"\x03url\x18\x02 \x01(\tR\x03url*k\n" +
 	"\aWeekday\x12\n" +
 	"\n" +
@@ -1289,39 +2369,59 @@ const file_schema_proto_rawDesc = "" +
 	"\x06FRIDAY\x10\x05\x12\f\n" +
 	"\bSATURDAY\x10\x06\x1a\x04:\x02\x10\x02B\x05\x92\x03\x02\b\x02b\beditionsp\xe8\a"
 
-var file_schema_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_schema_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_schema_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_schema_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_schema_proto_goTypes = []any{
 	(Weekday)(0),                  // 0: ottrec.v1.Weekday
-	(*Data)(nil),                  // 1: ottrec.v1.Data
-	(*Facility)(nil),              // 2: ottrec.v1.Facility
-	(*Source)(nil),                // 3: ottrec.v1.Source
-	(*LngLat)(nil),                // 4: ottrec.v1.LngLat
-	(*ScheduleGroup)(nil),         // 5: ottrec.v1.ScheduleGroup
-	(*Schedule)(nil),              // 6: ottrec.v1.Schedule
-	(*TimeRange)(nil),             // 7: ottrec.v1.TimeRange
-	(*ReservationLink)(nil),       // 8: ottrec.v1.ReservationLink
-	(*Schedule_ActivityDay)(nil),  // 9: ottrec.v1.Schedule.ActivityDay
-	(*Schedule_Activity)(nil),     // 10: ottrec.v1.Schedule.Activity
-	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+	(Diagnostic_Severity)(0),      // 1: ottrec.v1.Diagnostic.Severity
+	(Diagnostic_Stage)(0),         // 2: ottrec.v1.Diagnostic.Stage
+	(*Data)(nil),                  // 3: ottrec.v1.Data
+	(*Meta)(nil),                  // 4: ottrec.v1.Meta
+	(*Alert)(nil),                 // 5: ottrec.v1.Alert
+	(*ActivityInfo)(nil),          // 6: ottrec.v1.ActivityInfo
+	(*Facility)(nil),              // 7: ottrec.v1.Facility
+	(*Diagnostic)(nil),            // 8: ottrec.v1.Diagnostic
+	(*Source)(nil),                // 9: ottrec.v1.Source
+	(*LngLat)(nil),                // 10: ottrec.v1.LngLat
+	(*ScheduleGroup)(nil),         // 11: ottrec.v1.ScheduleGroup
+	(*Schedule)(nil),              // 12: ottrec.v1.Schedule
+	(*TimeRange)(nil),             // 13: ottrec.v1.TimeRange
+	(*ReservationLink)(nil),       // 14: ottrec.v1.ReservationLink
+	(*Link)(nil),                  // 15: ottrec.v1.Link
+	(*Schedule_ActivityDay)(nil),  // 16: ottrec.v1.Schedule.ActivityDay
+	(*Schedule_Activity)(nil),     // 17: ottrec.v1.Schedule.Activity
+	(*timestamppb.Timestamp)(nil), // 18: google.protobuf.Timestamp
 }
 var file_schema_proto_depIdxs = []int32{
-	2,  // 0: ottrec.v1.Data.facilities:type_name -> ottrec.v1.Facility
-	3,  // 1: ottrec.v1.Facility.source:type_name -> ottrec.v1.Source
-	4,  // 2: ottrec.v1.Facility._lnglat:type_name -> ottrec.v1.LngLat
-	5,  // 3: ottrec.v1.Facility.schedule_groups:type_name -> ottrec.v1.ScheduleGroup
-	11, // 4: ottrec.v1.Source._date:type_name -> google.protobuf.Timestamp
-	6,  // 5: ottrec.v1.ScheduleGroup.schedules:type_name -> ottrec.v1.Schedule
-	8,  // 6: ottrec.v1.ScheduleGroup.reservation_links:type_name -> ottrec.v1.ReservationLink
-	10, // 7: ottrec.v1.Schedule.activities:type_name -> ottrec.v1.Schedule.Activity
-	0,  // 8: ottrec.v1.TimeRange._wkday:type_name -> ottrec.v1.Weekday
-	7,  // 9: ottrec.v1.Schedule.ActivityDay.times:type_name -> ottrec.v1.TimeRange
-	9,  // 10: ottrec.v1.Schedule.Activity.days:type_name -> ottrec.v1.Schedule.ActivityDay
-	11, // [11:11] is the sub-list for method output_type
-	11, // [11:11] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	7,  // 0: ottrec.v1.Data.facilities:type_name -> ottrec.v1.Facility
+	6,  // 1: ottrec.v1.Data.activities:type_name -> ottrec.v1.ActivityInfo
+	5,  // 2: ottrec.v1.Data.alerts:type_name -> ottrec.v1.Alert
+	4,  // 3: ottrec.v1.Data._meta:type_name -> ottrec.v1.Meta
+	18, // 4: ottrec.v1.Meta.started:type_name -> google.protobuf.Timestamp
+	18, // 5: ottrec.v1.Meta.finished:type_name -> google.protobuf.Timestamp
+	9,  // 6: ottrec.v1.Alert.source:type_name -> ottrec.v1.Source
+	18, // 7: ottrec.v1.Alert._date:type_name -> google.protobuf.Timestamp
+	9,  // 8: ottrec.v1.ActivityInfo.source:type_name -> ottrec.v1.Source
+	8,  // 9: ottrec.v1.ActivityInfo._diagnostics:type_name -> ottrec.v1.Diagnostic
+	9,  // 10: ottrec.v1.Facility.source:type_name -> ottrec.v1.Source
+	10, // 11: ottrec.v1.Facility._lnglat:type_name -> ottrec.v1.LngLat
+	11, // 12: ottrec.v1.Facility.schedule_groups:type_name -> ottrec.v1.ScheduleGroup
+	8,  // 13: ottrec.v1.Facility._diagnostics:type_name -> ottrec.v1.Diagnostic
+	1,  // 14: ottrec.v1.Diagnostic.severity:type_name -> ottrec.v1.Diagnostic.Severity
+	2,  // 15: ottrec.v1.Diagnostic.stage:type_name -> ottrec.v1.Diagnostic.Stage
+	18, // 16: ottrec.v1.Source._date:type_name -> google.protobuf.Timestamp
+	12, // 17: ottrec.v1.ScheduleGroup.schedules:type_name -> ottrec.v1.Schedule
+	14, // 18: ottrec.v1.ScheduleGroup.reservation_links:type_name -> ottrec.v1.ReservationLink
+	17, // 19: ottrec.v1.Schedule.activities:type_name -> ottrec.v1.Schedule.Activity
+	0,  // 20: ottrec.v1.TimeRange._wkday:type_name -> ottrec.v1.Weekday
+	13, // 21: ottrec.v1.Schedule.ActivityDay.times:type_name -> ottrec.v1.TimeRange
+	16, // 22: ottrec.v1.Schedule.Activity.days:type_name -> ottrec.v1.Schedule.ActivityDay
+	15, // 23: ottrec.v1.Schedule.Activity.links:type_name -> ottrec.v1.Link
+	24, // [24:24] is the sub-list for method output_type
+	24, // [24:24] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
 }
 
 func init() { file_schema_proto_init() }
@@ -1334,8 +2434,8 @@ func file_schema_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_schema_proto_rawDesc), len(file_schema_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   10,
+			NumEnums:      3,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
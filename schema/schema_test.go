@@ -1,9 +1,24 @@
 package schema
 
 import (
+	"cmp"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestClockTime(t *testing.T) {
@@ -46,6 +61,227 @@ func TestClockTime(t *testing.T) {
 	}
 }
 
+func TestClockFormat(t *testing.T) {
+	for _, tc := range []struct {
+		Range  ClockRange
+		Opt    ClockFormat
+		Result string
+	}{
+		{MakeClockRange(9, 0, 10, 30), ClockFormat{}, "09:00 - 10:30"},
+		{MakeClockRange(9, 0, 10, 30), ClockFormat{Compact: true}, "9:00-10:30"},
+		{MakeClockRange(9, 0, 10, 30), ClockFormat{AMPM: true}, "9:00 - 10:30am"},
+		{MakeClockRange(9, 0, 10, 30), ClockFormat{AMPM: true, Compact: true}, "9-10:30am"},
+		{MakeClockRange(11, 0, 12, 0), ClockFormat{AMPM: true, Compact: true}, "11am-12pm"},
+		{MakeClockRange(18, 0, 20, 30), ClockFormat{French: true}, "18 h 00 à 20 h 30"},
+		{MakeClockRange(18, 0, 20, 30), ClockFormat{French: true, AMPM: true}, "18 h 00 à 20 h 30"},
+		{MakeClockRange(18, 0, 20, 30), ClockFormat{French: true, Compact: true}, "18h-20h30"},
+		{MakeClockRange(22, 0, 1, 0), ClockFormat{French: true}, "22 h 00 à 1 h 00"},
+		{ClockRange{44 * 60, 45 * 60}, ClockFormat{French: true, Compact: true}, ">20h->21h"},
+		{ClockRange{}, ClockFormat{French: true}, "invalid"},
+	} {
+		act := tc.Range.FormatWith(tc.Opt)
+		if act != tc.Result {
+			t.Errorf("%#v %+v: expected %q, got %q", tc.Range, tc.Opt, tc.Result, act)
+			continue
+		}
+		if tc.Range.IsValid() && tc.Range.Start < 24*60 {
+			if r, ok := ParseClockRange(act, ParseOptions{French: tc.Opt.French}); !ok || r != tc.Range {
+				t.Errorf("%#v %+v: %q doesn't round-trip (got %#v)", tc.Range, tc.Opt, act, r)
+			}
+		}
+	}
+	if a, b := MakeClockRange(9, 0, 10, 0).Format(true), MakeClockRange(9, 0, 10, 0).FormatWith(ClockFormat{AMPM: true}); a != b {
+		t.Errorf("format: expected %q, got %q", a, b)
+	}
+}
+
+func TestClockTimeArithmetic(t *testing.T) {
+	if act := MakeClockTimeFromTime(time.Date(2025, time.January, 6, 18, 30, 59, 0, time.UTC)); act != 60*18+30 {
+		t.Errorf("from time: got %#v", act)
+	}
+	for _, tc := range []struct {
+		In     ClockTime
+		Add    time.Duration
+		Result ClockTime
+	}{
+		{60*18 + 30, 90 * time.Minute, 60*20 + 0},
+		{60*23 + 30, time.Hour, 60*24 + 30},
+		{60 * 1, -time.Hour, 0},
+		{60 * 1, -2 * time.Hour, -1},
+		{60 * 1, 90 * time.Second, 60*1 + 1},
+		{-1, time.Hour, -1},
+	} {
+		if act := tc.In.Add(tc.Add); act != tc.Result {
+			t.Errorf("%#v + %s: expected %#v, got %#v", tc.In, tc.Add, tc.Result, act)
+		}
+	}
+	if act := ClockTime(60 * 20).Sub(60*18 + 30); act != 90*time.Minute {
+		t.Errorf("sub: got %s", act)
+	}
+	if act := ClockTime(-1).Sub(60); act != 0 {
+		t.Errorf("sub invalid: got %s", act)
+	}
+	for _, tc := range []struct {
+		In, Result ClockTime
+	}{
+		{60*18 + 7, 60*18 + 0},
+		{60*18 + 8, 60*18 + 15},
+		{60*18 + 53, 60*19 + 0},
+		{60*18 + 45, 60*18 + 45},
+		{-1, -1},
+	} {
+		if act := tc.In.RoundTo(15 * time.Minute); act != tc.Result {
+			t.Errorf("round %#v: expected %#v, got %#v", tc.In, tc.Result, act)
+		}
+	}
+	if a, b := ClockTime(60), ClockTime(120); !a.Before(b) || a.After(b) || b.Compare(a) != 1 || a.Compare(a) != 0 || !ClockTime(-5).Before(0) || ClockTime(-5).Compare(-1) != 0 {
+		t.Errorf("compare: unexpected result")
+	}
+}
+
+func TestClockRangeSplitAtMidnight(t *testing.T) {
+	for _, tc := range []struct {
+		In         ClockRange
+		Norm       ClockRange
+		Same, Next ClockRange
+	}{
+		{MakeClockRange(9, 0, 10, 0), MakeClockRange(9, 0, 10, 0), MakeClockRange(9, 0, 10, 0), ClockRange{}},
+		{MakeClockRange(22, 0, 2, 0), ClockRange{22 * 60, 26 * 60}, ClockRange{22 * 60, 24 * 60}, ClockRange{0, 2 * 60}},
+		{MakeClockRange(22, 0, 0, 0), ClockRange{22 * 60, 24 * 60}, ClockRange{22 * 60, 24 * 60}, ClockRange{}},
+		{ClockRange{44 * 60, 50 * 60}, ClockRange{20 * 60, 26 * 60}, ClockRange{20 * 60, 24 * 60}, ClockRange{0, 2 * 60}},
+		{ClockRange{10 * 60, 8 * 60}, ClockRange{10 * 60, 32 * 60}, ClockRange{10 * 60, 24 * 60}, ClockRange{0, 8 * 60}},
+		{ClockRange{10 * 60, 60 * 60}, ClockRange{10 * 60, 34 * 60}, ClockRange{10 * 60, 24 * 60}, ClockRange{0, 10 * 60}},
+		{ClockRange{-5, 60}, ClockRange{-1, -1}, ClockRange{-1, -1}, ClockRange{}},
+	} {
+		if act := tc.In.Normalize(); act != tc.Norm {
+			t.Errorf("%#v: expected normalized %#v, got %#v", tc.In, tc.Norm, act)
+		}
+		if same, next := tc.In.SplitAtMidnight(); same != tc.Same || next != tc.Next {
+			t.Errorf("%#v: expected split %#v %#v, got %#v %#v", tc.In, tc.Same, tc.Next, same, next)
+		}
+	}
+}
+
+func TestWeekdaySet(t *testing.T) {
+	for _, tc := range []struct {
+		In     string
+		Result string
+	}{
+		{"Monday", "Mon"},
+		{"MONDAY", "Mon"},
+		{"Mondays", "Mon"},
+		{"Thurs.", "Thu"},
+		{"Sat & Sun", "Sun, Sat"},
+		{"Mon - Fri", "Mon-Fri"},
+		{"Monday–Friday", "Mon-Fri"},
+		{"Tues to Thurs", "Tue-Thu"},
+		{"Fri-Mon", "Sun, Mon, Fri, Sat"},
+		{"Mon, Wed - Fri", "Mon, Wed-Fri"},
+		{"Mon, Jan 6 - Wed", "Mon, Wed"}, // not a range
+		{"Sunday (Dimanche)", "Sun"},
+		{"Lundi", "Mon"},
+		{"Lun. au ven.", "Mon-Fri"},
+		{"Mo", ""},
+		{"", ""},
+	} {
+		set, ok := ParseWeekdaySet(tc.In)
+		if ok != (tc.Result != "") {
+			t.Errorf("%q: expected ok=%t", tc.In, !ok)
+		}
+		if act := set.String(); act != tc.Result {
+			t.Errorf("%q: expected %q, got %q", tc.In, tc.Result, act)
+		}
+	}
+	s := MakeWeekdaySet(time.Monday, time.Tuesday, time.Wednesday, 7)
+	if s.Len() != 3 || !s.Has(time.Monday) || s.Has(time.Sunday) || s.Has(7) {
+		t.Errorf("unexpected set %s", s)
+	}
+	if act := s.Remove(time.Tuesday).String(); act != "Mon, Wed" {
+		t.Errorf("remove: got %q", act)
+	}
+	if act := s.Union(MakeWeekdaySet(time.Saturday)).String(); act != "Mon-Wed, Sat" {
+		t.Errorf("union: got %q", act)
+	}
+	if act := s.Intersect(MakeWeekdaySet(time.Wednesday, time.Thursday)).String(); act != "Wed" {
+		t.Errorf("intersect: got %q", act)
+	}
+	if act := AllWeekdays.Difference(s).String(); act != "Sun, Thu-Sat" {
+		t.Errorf("difference: got %q", act)
+	}
+	if act := slices.Collect(s.All()); !slices.Equal(act, []time.Weekday{time.Monday, time.Tuesday, time.Wednesday}) {
+		t.Errorf("all: got %v", act)
+	}
+}
+
+func TestParseNames(t *testing.T) {
+	for _, tc := range []struct {
+		In    string
+		Month time.Month
+	}{
+		{"January", time.January},
+		{"jan", time.January},
+		{"Sep.", time.September},
+		{"janvier", time.January},
+		{"Févr.", time.February},
+		{"déc", time.December},
+		{"mai", time.May},
+		{"mar", time.March},
+		{"janu", 0},
+		{"", 0},
+	} {
+		if m, ok := ParseMonth(tc.In); ok != (tc.Month != 0) || m != tc.Month {
+			t.Errorf("month %q: expected %v, got %v", tc.In, tc.Month, m)
+		}
+	}
+	for _, tc := range []struct {
+		In      string
+		Weekday time.Weekday
+	}{
+		{"Sunday", time.Sunday},
+		{"SAT", time.Saturday},
+		{"dimanche", time.Sunday},
+		{"Mer.", time.Wednesday},
+		{"mardi", time.Tuesday},
+		{"mar", -1},
+		{"tues", -1},
+	} {
+		if w, ok := ParseWeekday(tc.In); ok != (tc.Weekday != -1) || (ok && w != tc.Weekday) {
+			t.Errorf("weekday %q: expected %v, got %v (ok=%t)", tc.In, tc.Weekday, w, ok)
+		}
+	}
+	for _, lang := range []string{"en", "fr"} {
+		for _, x := range MonthNames(lang) {
+			if _, ok := ParseMonth(x); !ok {
+				t.Errorf("month name %q (%s) not accepted", x, lang)
+			}
+		}
+		for _, x := range WeekdayNames(lang) {
+			if _, ok := ParseWeekday(x); !ok {
+				t.Errorf("weekday name %q (%s) not accepted", x, lang)
+			}
+		}
+	}
+	if n := len(MonthNames("en")); n != 23 {
+		t.Errorf("expected 23 english month names, got %d", n)
+	}
+	for w := Weekday_SUNDAY; w <= Weekday_SATURDAY; w++ {
+		for _, lang := range []string{"en", "fr"} {
+			if x, ok := ParseWeekday(w.Name(lang)); !ok || x != w.AsWeekday() {
+				t.Errorf("weekday name %q (%s) doesn't round-trip", w.Name(lang), lang)
+			}
+		}
+		if act := w.Code(); len(act) != 2 || !strings.HasPrefix(strings.ToLower(w.Name("en")), act) {
+			t.Errorf("unexpected code %q for %s", act, w.Name("en"))
+		}
+	}
+	if act := Weekday_TUESDAY.Name("fr") + " " + Weekday_TUESDAY.Abbrev("fr") + " " + Weekday_TUESDAY.Abbrev("en"); act != "mardi mar. Tue" {
+		t.Errorf("unexpected weekday names %q", act)
+	}
+	if Weekday(7).Name("en") != "" || Weekday(7).Code() != "" {
+		t.Errorf("expected empty name for invalid weekday")
+	}
+}
+
 func TestDate(t *testing.T) {
 	tmp := Date(2222_11_21_3)
 	if x, ok := tmp.Year(); !ok || x != 2222 {
@@ -108,3 +344,1475 @@ func TestDate(t *testing.T) {
 		}
 	}
 }
+
+func TestDateFormat(t *testing.T) {
+	for _, tc := range []struct {
+		Range  DateRange
+		Opt    DateFormat
+		Result string
+	}{
+		{DateRange{2025_01_06_2, 2025_01_06_2}, DateFormat{}, "Monday, January 6, 2025"},
+		{DateRange{2025_01_06_2, 2025_01_06_2}, DateFormat{Short: true}, "Mon, Jan 6, 2025"},
+		{DateRange{2025_01_06_2, 2025_01_06_2}, DateFormat{NoWeekday: true, NoYear: true}, "January 6"},
+		{DateRange{2025_01_06_2, 2025_01_06_2}, DateFormat{French: true}, "lundi 6 janvier 2025"},
+		{DateRange{2025_02_01_0, 2025_02_01_0}, DateFormat{French: true, Short: true}, "1er févr. 2025"},
+		{DateRange{2025_01_00_0, 2025_01_00_0}, DateFormat{French: true}, "janvier 2025"},
+		{DateRange{1_06_0, 4_06_0}, DateFormat{}, "January 6 to April 6"},
+		{DateRange{1_06_0, 4_06_0}, DateFormat{French: true}, "du 6 janvier au 6 avril"},
+		{DateRange{1_06_0, 0}, DateFormat{French: true}, "à partir du 6 janvier"},
+		{DateRange{0, 4_06_0}, DateFormat{French: true}, "jusqu'au 6 avril"},
+		{DateRange{0, 4_06_0}, DateFormat{Short: true}, "until Apr 6"},
+		{DateRange{2025_01_06_3, 0}, DateFormat{French: true}, "à partir du <invalid>"},
+		{DateRange{}, DateFormat{French: true}, ""},
+	} {
+		if act := tc.Range.FormatWith(tc.Opt); act != tc.Result {
+			t.Errorf("%#v %+v: expected %q, got %q", tc.Range, tc.Opt, tc.Result, act)
+		}
+		if act := tc.Range.String(); act != tc.Range.FormatWith(DateFormat{}) {
+			t.Errorf("%#v: string %q doesn't match default format", tc.Range, act)
+		}
+	}
+	for _, lang := range []string{"en", "fr"} {
+		for m := time.January; m <= time.December; m++ {
+			for _, short := range []bool{false, true} {
+				if x, ok := ParseMonth(MonthName(m, lang, short)); !ok || x != m {
+					t.Errorf("month name %q (%s) doesn't round-trip", MonthName(m, lang, short), lang)
+				}
+			}
+		}
+	}
+	if MonthName(13, "en", false) != "" || WeekdayName(time.Monday, "de", false) != "" {
+		t.Errorf("expected empty name for invalid month or language")
+	}
+}
+
+func TestDateResolve(t *testing.T) {
+	loc, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	ref := time.Date(2025, time.December, 15, 12, 0, 0, 0, loc)
+	for _, tc := range []struct {
+		In     Date
+		Result string
+	}{
+		{2025_01_06_0, "2025-01-06T00:00:00-05:00"},
+		{2025_07_01_3, "2025-07-01T00:00:00-04:00"},
+		{2025_07_01_4, ""},                     // wrong weekday
+		{2025_02_30_0, ""},                     // invalid day
+		{1_06_0, "2026-01-06T00:00:00-05:00"},  // nearest is next year
+		{11_20_0, "2025-11-20T00:00:00-05:00"}, // nearest is this year
+		{6_20_0, "2025-06-20T00:00:00-04:00"},  // nearest is this year
+		{1_06_3, "2026-01-06T00:00:00-05:00"},  // tuesday in 2026
+		{1_06_2, "2025-01-06T00:00:00-05:00"},  // monday in 2025
+		{1_06_7, "2024-01-06T00:00:00-05:00"},  // saturday in 2024
+		{1_06_4, ""},                           // not a wednesday in any nearby year
+		{2_29_0, "2024-02-29T00:00:00-05:00"},  // only leap year nearby
+		{2025_01_00_0, ""},                     // no day
+		{0, ""},
+	} {
+		res, ok := tc.In.Resolve(ref, loc)
+		var act string
+		if ok {
+			act = res.Format(time.RFC3339)
+		}
+		if act != tc.Result {
+			t.Errorf("%#v: expected %q, got %q", tc.In, tc.Result, act)
+		}
+	}
+}
+
+func TestDateArithmetic(t *testing.T) {
+	for _, tc := range []struct {
+		In     Date
+		Fn     func(Date) (Date, bool)
+		Result Date
+	}{
+		{2025_01_06_0, func(d Date) (Date, bool) { return d.AddDays(1) }, 2025_01_07_3},
+		{2025_01_06_2, func(d Date) (Date, bool) { return d.AddDays(-6) }, 2024_12_31_3},
+		{2024_02_28_0, func(d Date) (Date, bool) { return d.AddDays(1) }, 2024_02_29_5},
+		{2025_02_28_0, func(d Date) (Date, bool) { return d.AddDays(1) }, 2025_03_01_7},
+		{1_06_0, func(d Date) (Date, bool) { return d.AddDays(1) }, 0},       // no year
+		{2025_01_06_3, func(d Date) (Date, bool) { return d.AddDays(1) }, 0}, // wrong weekday
+		{9999_12_31_0, func(d Date) (Date, bool) { return d.AddDays(1) }, 0}, // out of range
+		{2025_01_06_0, func(d Date) (Date, bool) { return d.NextWeekday(time.Monday) }, 2025_01_06_2},
+		{2025_01_06_0, func(d Date) (Date, bool) { return d.NextWeekday(time.Sunday) }, 2025_01_12_1},
+		{2025_12_31_0, func(d Date) (Date, bool) { return d.NextWeekday(time.Friday) }, 2026_01_02_6},
+		{2025_01_06_0, func(d Date) (Date, bool) { return d.NextWeekday(7) }, 0},
+		{2025_01_08_0, func(d Date) (Date, bool) { return d.StartOfWeek(time.Sunday) }, 2025_01_05_1},
+		{2025_01_08_0, func(d Date) (Date, bool) { return d.StartOfWeek(time.Monday) }, 2025_01_06_2},
+		{2025_01_05_0, func(d Date) (Date, bool) { return d.StartOfWeek(time.Monday) }, 2024_12_30_2},
+		{2025_01_05_0, func(d Date) (Date, bool) { return d.StartOfWeek(time.Sunday) }, 2025_01_05_1},
+		{2025_01_00_0, func(d Date) (Date, bool) { return d.StartOfWeek(time.Sunday) }, 0}, // no day
+	} {
+		act, ok := tc.Fn(tc.In)
+		if ok != !tc.Result.IsZero() || act != tc.Result {
+			t.Errorf("%#v: expected %#v, got %#v (ok=%t)", tc.In, tc.Result, act, ok)
+		}
+	}
+	if act, exp := DateOf(time.Date(2025, time.January, 6, 23, 0, 0, 0, time.UTC)), Date(2025_01_06_2); act != exp {
+		t.Errorf("DateOf: expected %#v, got %#v", exp, act)
+	}
+}
+
+func TestDateCompare(t *testing.T) {
+	sorted := []Date{
+		1_00_0,       // january
+		2,            // monday
+		1_06_0,       // january 6
+		12_00_0,      // december
+		12_31_0,      // december 31
+		2024_00_00_0, // 2024
+		2025_00_00_0, // 2025
+		2025_00_00_2, // monday, 2025
+		2025_01_06_0, // january 6, 2025
+		2025_01_07_0, // january 7, 2025
+		2025_02_01_0, // february 1, 2025
+		2025_02_00_0, // february 2025
+		2025_02_02_0, // february 2, 2025
+	}
+	for i, a := range sorted {
+		for j, b := range sorted {
+			if act, exp := a.Compare(b), cmp.Compare(i, j); act != exp {
+				t.Errorf("%#v compare %#v: expected %d, got %d", a, b, exp, act)
+			}
+		}
+	}
+	if a, b := Date(2025_01_06_0), Date(2025_01_06_2); a.Compare(b) != 0 || a.Before(b) || a.After(b) {
+		t.Errorf("implied weekday should not affect order")
+	}
+	if a, b := Date(1_06_0), Date(1_06_2); !a.Before(b) || !b.After(a) {
+		t.Errorf("unimplied weekday should affect order")
+	}
+}
+
+func TestDateRangeContains(t *testing.T) {
+	for _, tc := range []struct {
+		Range  DateRange
+		In     Date
+		Result bool
+	}{
+		{DateRange{2025_01_06_0, 2025_03_01_0}, 2025_02_01_0, true},
+		{DateRange{2025_01_06_0, 2025_03_01_0}, 2025_01_06_0, true},
+		{DateRange{2025_01_06_0, 2025_03_01_0}, 2025_03_01_0, true},
+		{DateRange{2025_01_06_0, 2025_03_01_0}, 2025_03_02_0, false},
+		{DateRange{2025_01_06_0, 2025_03_01_0}, 2024_02_01_0, false},
+		{DateRange{2025_01_06_0, 2025_03_01_0}, 2_01_0, true},  // no year
+		{DateRange{2025_01_06_0, 2025_03_01_0}, 4_01_0, false}, // no year
+		{DateRange{2025_01_06_0, 0}, 2030_01_01_0, true},       // open end
+		{DateRange{0, 2025_03_01_0}, 2020_01_01_0, true},       // open start
+		{DateRange{0, 0}, 2020_01_01_0, true},                  // open
+		{DateRange{2025_01_00_0, 2025_02_00_0}, 2025_02_28_0, true},
+		{DateRange{2025_01_00_0, 2025_02_00_0}, 2025_03_01_0, false},
+		{DateRange{12_20_0, 1_05_0}, 2025_12_25_0, true}, // wraps
+		{DateRange{12_20_0, 1_05_0}, 2026_01_02_0, true}, // wraps
+		{DateRange{12_20_0, 1_05_0}, 2026_01_06_0, false},
+		{DateRange{6_01_0, 9_00_0}, 2025_09_30_0, true},
+		{DateRange{2024_06_01_0, 2025_09_01_0}, 3_01_0, true}, // over a year
+		{DateRange{2025_01_06_0, 2025_03_01_0}, 2025_00_00_0, false},
+		{DateRange{2025_01_06_0, 2025_03_01_0}, 0, false},
+	} {
+		if act := tc.Range.Contains(tc.In); act != tc.Result {
+			t.Errorf("%s: %#v: expected %t, got %t", tc.Range, tc.In, tc.Result, act)
+		}
+	}
+}
+
+func TestDateRangeOverlaps(t *testing.T) {
+	for _, tc := range []struct {
+		A, B   DateRange
+		Result bool
+	}{
+		{DateRange{2025_01_06_0, 2025_03_01_0}, DateRange{2025_03_01_0, 2025_04_01_0}, true},
+		{DateRange{2025_01_06_0, 2025_03_01_0}, DateRange{2025_03_02_0, 2025_04_01_0}, false},
+		{DateRange{2025_01_06_0, 2025_03_01_0}, DateRange{2024_01_06_0, 2024_03_01_0}, false},
+		{DateRange{2025_01_06_0, 0}, DateRange{2030_01_01_0, 2030_02_01_0}, true},
+		{DateRange{0, 2025_01_01_0}, DateRange{2025_01_02_0, 0}, false},
+		{DateRange{0, 0}, DateRange{2025_01_02_0, 2025_01_03_0}, true},
+		{DateRange{2025_01_00_0, 2025_01_00_0}, DateRange{2025_01_31_0, 2025_02_01_0}, true},
+		{DateRange{12_20_0, 1_05_0}, DateRange{2026_01_01_0, 2026_02_01_0}, true},
+		{DateRange{12_20_0, 1_05_0}, DateRange{1_06_0, 12_19_0}, false},
+		{DateRange{12_20_0, 1_05_0}, DateRange{11_01_0, 1_01_0}, true},
+		{DateRange{2025_03_01_0, 2025_01_01_0}, DateRange{0, 0}, false}, // reversed
+	} {
+		if act := tc.A.Overlaps(tc.B); act != tc.Result {
+			t.Errorf("%s / %s: expected %t, got %t", tc.A, tc.B, tc.Result, act)
+		}
+		if act := tc.B.Overlaps(tc.A); act != tc.Result {
+			t.Errorf("%s / %s: expected %t, got %t", tc.B, tc.A, tc.Result, act)
+		}
+	}
+}
+
+func TestTextMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		In   any
+		Text string
+	}{
+		{ClockTime(60*18 + 30), "18:30"},
+		{ClockTime(60*9 + 5), "09:05"},
+		{ClockTime(60*25 + 0), ">01:00"},
+		{ClockTime(-1), ""},
+		{MakeClockRange(18, 30, 20, 0), "18:30 - 20:00"},
+		{MakeClockRange(22, 0, 25, 0), "22:00 - 01:00"},
+		{MakeClockRange(9, 0, 33, 0), "09:00 - >09:00"},
+		{ClockRange{-1, -1}, ""},
+		{ClockRange{60 * 10, 60 * 9}, "!"},
+		{Date(2025_01_06_2), "Monday, January 6, 2025"},
+		{Date(1_06_0), "January 6"},
+		{Date(2025_01_00_0), "January 2025"},
+		{Date(2025_00_00_0), "2025"},
+		{Date(2), "Monday"},
+		{Date(0), ""},
+		{Date(6_0), "!"},          // day without month
+		{Date(2025_02_30_0), "!"}, // invalid
+		{DateRange{1_06_0, 4_06_0}, "January 6 to April 6"},
+		{DateRange{2025_01_06_0, 2025_04_06_0}, "January 6, 2025 to April 6, 2025"},
+		{DateRange{1_06_0, 0}, "starting January 6"},
+		{DateRange{0, 4_06_0}, "until April 6"},
+		{DateRange{1_06_0, 1_06_0}, "January 6"},
+		{DateRange{}, ""},
+		{DateRange{2025_02_30_0, 0}, "!"},
+	} {
+		m := tc.In.(encoding.TextMarshaler)
+		b, err := m.MarshalText()
+		if tc.Text == "!" {
+			if err == nil {
+				t.Errorf("%#v: expected error, got %q", tc.In, b)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%#v: unexpected error: %v", tc.In, err)
+			continue
+		}
+		if string(b) != tc.Text {
+			t.Errorf("%#v: expected %q, got %q", tc.In, tc.Text, b)
+		}
+		u := reflect.New(reflect.TypeOf(tc.In))
+		if err := u.Interface().(encoding.TextUnmarshaler).UnmarshalText(b); err != nil {
+			t.Errorf("%#v: unmarshal %q: unexpected error: %v", tc.In, b, err)
+		} else if act := u.Elem().Interface(); act != tc.In {
+			t.Errorf("%#v: unmarshal %q: got %#v", tc.In, b, act)
+		}
+	}
+	for _, tc := range []struct {
+		In  string
+		Out any // pointer to the expected value, or a nil pointer if invalid
+	}{
+		{`" 18:30 "`, ptrTo(ClockTime(60*18 + 30))},
+		{`1110`, ptrTo(ClockTime(60*18 + 30))},
+		{`null`, ptrTo(ClockTime(0))},
+		{`"24:00"`, (*ClockTime)(nil)},
+		{`"18:60"`, (*ClockTime)(nil)},
+		{`"noon"`, (*ClockTime)(nil)},
+		{`"18:30-20:00"`, ptrTo(MakeClockRange(18, 30, 20, 0))},
+		{`{"Start":1110,"End":1200}`, ptrTo(MakeClockRange(18, 30, 20, 0))},
+		{`"18:30"`, (*ClockRange)(nil)},
+		{`1110`, (*ClockRange)(nil)},
+		{`"monday, JANUARY 6, 2025"`, ptrTo(Date(2025_01_06_2))},
+		{`202501062`, ptrTo(Date(2025_01_06_2))},
+		{`"Tuesday, January 6, 2025"`, (*Date)(nil)},
+		{`"January 6 2025"`, ptrTo(Date(2025_01_06_0))},
+		{`"6 January"`, (*Date)(nil)},
+		{`"Smarch 6"`, (*Date)(nil)},
+		{`"January 6 to April 6"`, ptrTo(DateRange{1_06_0, 4_06_0})},
+		{`{"From":1060,"To":4060}`, ptrTo(DateRange{1_06_0, 4_06_0})},
+		{`"January 6 to Smarch 6"`, (*DateRange)(nil)},
+	} {
+		exp := reflect.ValueOf(tc.Out)
+		act := reflect.New(exp.Type().Elem())
+		err := json.Unmarshal([]byte(tc.In), act.Interface())
+		if exp.IsNil() {
+			if err == nil {
+				t.Errorf("%s: expected error, got %#v", tc.In, act.Elem().Interface())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.In, err)
+		} else if act.Elem().Interface() != exp.Elem().Interface() {
+			t.Errorf("%s: expected %#v, got %#v", tc.In, exp.Elem().Interface(), act.Elem().Interface())
+		}
+	}
+	if b, err := json.Marshal(struct {
+		Time  ClockRange
+		Dates DateRange
+	}{MakeClockRange(18, 30, 20, 0), DateRange{1_06_0, 4_06_0}}); err != nil {
+		t.Errorf("marshal json: unexpected error: %v", err)
+	} else if act, exp := string(b), `{"Time":"18:30 - 20:00","Dates":"January 6 to April 6"}`; act != exp {
+		t.Errorf("marshal json: expected %s, got %s", exp, act)
+	}
+}
+
+func TestSQL(t *testing.T) {
+	for _, tc := range []struct {
+		In    driver.Valuer
+		Value driver.Value
+	}{
+		{ClockTime(60*18 + 30), int64(1110)},
+		{ClockTime(-1), nil},
+		{Date(2025_01_06_2), int64(202501062)},
+		{Date(0), nil},
+		{Weekday_SATURDAY, int64(6)},
+	} {
+		v, err := tc.In.Value()
+		if err != nil {
+			t.Errorf("%#v: unexpected error: %v", tc.In, err)
+			continue
+		}
+		if v != tc.Value {
+			t.Errorf("%#v: expected %#v, got %#v", tc.In, tc.Value, v)
+		}
+		u := reflect.New(reflect.TypeOf(tc.In))
+		if err := u.Interface().(sql.Scanner).Scan(v); err != nil {
+			t.Errorf("%#v: scan %#v: unexpected error: %v", tc.In, v, err)
+		} else if act := u.Elem().Interface(); act != tc.In {
+			t.Errorf("%#v: scan %#v: got %#v", tc.In, v, act)
+		}
+	}
+	if _, err := Weekday(7).Value(); err == nil {
+		t.Errorf("expected error for invalid weekday")
+	}
+	var d Date
+	if err := d.Scan([]byte("202501062")); err != nil || d != 2025_01_06_2 {
+		t.Errorf("scan bytes: got %#v (err=%v)", d, err)
+	}
+	var w Weekday
+	for _, src := range []any{nil, int64(7), "x", int64(1) << 40, 1.5} {
+		if err := w.Scan(src); err == nil {
+			t.Errorf("scan %#v: expected error", src)
+		}
+	}
+}
+
+func TestTimeRangeParsed(t *testing.T) {
+	for _, tc := range []struct {
+		Weekday time.Weekday
+		Range   ClockRange
+		OK      bool
+	}{
+		{time.Monday, MakeClockRange(7, 0, 8, 0), true},
+		{-1, MakeClockRange(7, 0, 8, 0), false},
+		{time.Monday, ClockRange{-1, -1}, false},
+		{time.Monday, ClockRange{420, -1}, false},
+	} {
+		tr := MakeTimeRange("label", tc.Weekday, tc.Range)
+		if tr.GetLabel() != "label" {
+			t.Errorf("%v %v: label not set", tc.Weekday, tc.Range)
+		}
+		if tr.HasXWkday() != (tc.Weekday != -1) || tr.HasXStart() != tc.Range.End.IsValid() || tr.HasXStart() != tr.HasXEnd() {
+			t.Errorf("%v %#v: unexpected fields set: %v", tc.Weekday, tc.Range, tr)
+		}
+		if w, r, ok := tr.AsXParsed(); ok != tc.OK || (ok && (w != tc.Weekday || r != tc.Range)) {
+			t.Errorf("%v %#v: got %v %#v %t", tc.Weekday, tc.Range, w, r, ok)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if issues := Validate(Data_builder{}.Build()); len(issues) != 1 || issues[0].String() != "no facilities" {
+		t.Errorf("empty data: unexpected issues %q", issues)
+	}
+	pb := Data_builder{
+		Facilities: []*Facility{Facility_builder{
+			Name:   "A Pool",
+			Source: Source_builder{Url: "https://example.com/a"}.Build(),
+			ScheduleGroups: []*ScheduleGroup{ScheduleGroup_builder{
+				Label: "Swimming",
+				Schedules: []*Schedule{Schedule_builder{
+					Caption: "Swim",
+					XFrom:   ptrTo(int32(2025_09_01_0)),
+					XTo:     ptrTo(int32(2025_08_01_0)),
+					Days:    []string{"Monday", "Tuesday"},
+					Activities: []*Schedule_Activity{Schedule_Activity_builder{
+						Label: "Lane swim",
+						Days: []*Schedule_ActivityDay{Schedule_ActivityDay_builder{
+							Times: []*TimeRange{TimeRange_builder{
+								Label:  "7 - 8 am",
+								XWkday: ptrTo(Weekday(7)),
+								XStart: ptrTo(int32(480)),
+								XEnd:   ptrTo(int32(420)),
+							}.Build()},
+						}.Build()},
+					}.Build()},
+				}.Build()},
+			}.Build()},
+		}.Build()},
+	}.Build()
+	var act []string
+	for _, issue := range Validate(pb) {
+		act = append(act, fmt.Sprintf("%d %d %d %d %s", issue.Facility, issue.Group, issue.Schedule, issue.Activity, issue))
+	}
+	exp := []string{
+		`0 0 0 -1 facility "A Pool" > group "Swimming" > schedule "Swim": date range ends before it starts`,
+		`0 0 0 0 facility "A Pool" > group "Swimming" > schedule "Swim" > activity "Lane swim": 1 activity days for 2 schedule days`,
+		`0 0 0 0 facility "A Pool" > group "Swimming" > schedule "Swim" > activity "Lane swim": invalid weekday 7 for "7 - 8 am"`,
+		`0 0 0 0 facility "A Pool" > group "Swimming" > schedule "Swim" > activity "Lane swim": invalid time range 8:00am - 7:00am for "7 - 8 am"`,
+	}
+	if !slices.Equal(act, exp) {
+		t.Errorf("expected issues:\n%s\ngot:\n%s", strings.Join(exp, "\n"), strings.Join(act, "\n"))
+	}
+}
+
+func TestDerived(t *testing.T) {
+	pb := Data_builder{
+		Attribution: []string{"test"},
+		Facilities: []*Facility{
+			Facility_builder{
+				Name:     "A Pool",
+				Source:   Source_builder{Url: "https://example.com/a", XDate: timestamppb.New(time.Unix(1, 0)), XStatus: 200}.Build(),
+				XLnglat:  LngLat_builder{Lng: -75.5, Lat: 45.25}.Build(),
+				XAliases: []string{"The Pool"},
+				ScheduleGroups: []*ScheduleGroup{ScheduleGroup_builder{
+					Label: "Swimming",
+				}.Build()},
+			}.Build(),
+			Facility_builder{
+				Name: "B Pool",
+			}.Build(),
+		},
+	}.Build()
+	orig := proto.Clone(pb)
+
+	raw := StripDerived(pb)
+	if exp := (Data_builder{
+		Attribution: []string{"test"},
+		Facilities: []*Facility{
+			Facility_builder{
+				Name:   "A Pool",
+				Source: Source_builder{Url: "https://example.com/a"}.Build(),
+				ScheduleGroups: []*ScheduleGroup{ScheduleGroup_builder{
+					Label: "Swimming",
+				}.Build()},
+			}.Build(),
+			Facility_builder{
+				Name: "B Pool",
+			}.Build(),
+		},
+	}.Build()); !proto.Equal(raw, exp) {
+		t.Errorf("strip derived: expected %v, got %v", exp, raw)
+	}
+	derived := OnlyDerived(pb)
+	if exp := (Data_builder{
+		Facilities: []*Facility{
+			Facility_builder{
+				Source:   Source_builder{XDate: timestamppb.New(time.Unix(1, 0)), XStatus: 200}.Build(),
+				XLnglat:  LngLat_builder{Lng: -75.5, Lat: 45.25}.Build(),
+				XAliases: []string{"The Pool"},
+			}.Build(),
+			Facility_builder{}.Build(),
+		},
+	}.Build()); !proto.Equal(derived, exp) {
+		t.Errorf("only derived: expected %v, got %v", exp, derived)
+	}
+	if !proto.Equal(pb, orig) {
+		t.Errorf("original data was modified")
+	}
+}
+
+func TestEqualHash(t *testing.T) {
+	mk := func(date int64, name string) *Facility {
+		return Facility_builder{
+			Name:         name,
+			Source:       Source_builder{Url: "https://example.com/a", XDate: timestamppb.New(time.Unix(date, 0))}.Build(),
+			XDiagnostics: []*Diagnostic{Diagnostic_builder{Message: strconv.FormatInt(date, 10)}.Build()},
+		}.Build()
+	}
+	a, b, c := mk(1, "A Pool"), mk(2, "A Pool"), mk(1, "B Pool")
+	if !Equal(a, b) || Hash(a) != Hash(b) {
+		t.Errorf("expected facilities differing only in derived fields to be equal")
+	}
+	if Equal(a, c) || Hash(a) == Hash(c) {
+		t.Errorf("expected facilities with different names to differ")
+	}
+	if proto.Equal(a, b) {
+		t.Errorf("original facilities were modified")
+	}
+	d1 := Data_builder{Facilities: []*Facility{a}}.Build()
+	d2 := Data_builder{Facilities: []*Facility{b}}.Build()
+	if !Equal(d1, d2) || Hash(d1) != Hash(d2) {
+		t.Errorf("expected data to be equal")
+	}
+	if Equal(d1, nil) || !Equal[*Data](nil, nil) {
+		t.Errorf("unexpected nil equality")
+	}
+}
+
+func TestMeta(t *testing.T) {
+	pb := Data_builder{
+		Facilities: []*Facility{
+			Facility_builder{
+				Name: "A Pool",
+				ScheduleGroups: []*ScheduleGroup{ScheduleGroup_builder{
+					Schedules: []*Schedule{
+						Schedule_builder{
+							Activities: []*Schedule_Activity{Schedule_Activity_builder{
+								Days: []*Schedule_ActivityDay{
+									Schedule_ActivityDay_builder{Times: []*TimeRange{{}, {}}}.Build(),
+									Schedule_ActivityDay_builder{Times: []*TimeRange{{}}}.Build(),
+								},
+							}.Build()},
+						}.Build(),
+						Schedule_builder{}.Build(),
+					},
+				}.Build()},
+				XDiagnostics: []*Diagnostic{
+					Diagnostic_builder{Severity: Diagnostic_ERROR}.Build(),
+					Diagnostic_builder{Severity: Diagnostic_WARNING}.Build(),
+				},
+			}.Build(),
+			Facility_builder{Name: "B Arena"}.Build(),
+		},
+		Activities: []*ActivityInfo{ActivityInfo_builder{
+			XDiagnostics: []*Diagnostic{Diagnostic_builder{Severity: Diagnostic_WARNING}.Build()},
+		}.Build()},
+	}.Build()
+
+	m := Meta_builder{RunId: "1"}.Build()
+	m.SetCounts(pb)
+	if m.GetFacilities() != 2 || m.GetSchedules() != 2 || m.GetTimeRanges() != 3 || m.GetErrors() != 1 || m.GetWarnings() != 2 {
+		t.Errorf("incorrect counts: %v", m)
+	}
+	if _, ok := m.Duration(); ok {
+		t.Errorf("expected no duration without start and finish times")
+	}
+	m.SetStarted(timestamppb.New(time.Unix(100, 0)))
+	m.SetFinished(timestamppb.New(time.Unix(160, 0)))
+	if d, ok := m.Duration(); !ok || d != time.Minute {
+		t.Errorf("incorrect duration: %v %v", d, ok)
+	}
+
+	pb.SetXMeta(m)
+	if !IsDerived(pb.ProtoReflect().Descriptor().Fields().ByName("_meta")) {
+		t.Errorf("expected meta to be derived")
+	}
+	if StripDerived(pb).HasXMeta() {
+		t.Errorf("expected meta to be stripped")
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	var ds []*Diagnostic
+	ds = AppendDiagnostics(ds,
+		DiagError(Diagnostic_FETCH, "", "failed to fetch data: %v", "timeout"),
+		DiagWarning(Diagnostic_PARSE, `group "Swimming"`, "failed to parse time range %q", "noon"),
+		DiagWarning(Diagnostic_GEOCODE, "", "no results"),
+	)
+	ds = AppendDiagnostics(ds, DiagWarning(Diagnostic_GEOCODE, "", "no results"))
+	if len(ds) != 3 {
+		t.Fatalf("expected duplicate diagnostic to be skipped, got %d", len(ds))
+	}
+	if exp, act := []string{
+		"failed to fetch data: timeout",
+		`warning: group "Swimming": failed to parse time range "noon"`,
+		"warning: no results",
+	}, DiagnosticStrings(ds); !slices.Equal(exp, act) {
+		t.Errorf("expected %q, got %q", exp, act)
+	}
+	if n := len(WithSeverity(ds, Diagnostic_WARNING)); n != 2 {
+		t.Errorf("expected 2 warnings, got %d", n)
+	}
+	if n := len(WithStage(ds, Diagnostic_FETCH, Diagnostic_PARSE)); n != 2 {
+		t.Errorf("expected 2 fetch or parse diagnostics, got %d", n)
+	}
+	if x := WithoutStage(ds, Diagnostic_GEOCODE); len(x) != 2 || len(ds) != 3 {
+		t.Errorf("expected geocode diagnostic to be removed without modifying the original")
+	}
+}
+
+func TestLightweight(t *testing.T) {
+	pb := Data_builder{
+		Facilities: []*Facility{Facility_builder{
+			Name:              "A Pool",
+			Description:       "A long description.",
+			NotificationsHtml: "<p>Closed</p>",
+			ScheduleGroups: []*ScheduleGroup{ScheduleGroup_builder{
+				Label:               "Swimming",
+				ScheduleChangesHtml: "<p>Changed</p>",
+			}.Build()},
+		}.Build()},
+		Activities: []*ActivityInfo{ActivityInfo_builder{
+			XName:       "lane swim",
+			Title:       "Lane swim",
+			Description: "Swimming in lanes.",
+		}.Build()},
+		Alerts: []*Alert{Alert_builder{
+			Title: "Closure",
+			Html:  "<p>Closed</p>",
+		}.Build()},
+	}.Build()
+	exp := Data_builder{
+		Facilities: []*Facility{Facility_builder{
+			Name: "A Pool",
+			ScheduleGroups: []*ScheduleGroup{ScheduleGroup_builder{
+				Label: "Swimming",
+			}.Build()},
+		}.Build()},
+		Activities: []*ActivityInfo{ActivityInfo_builder{
+			XName: "lane swim",
+			Title: "Lane swim",
+		}.Build()},
+		Alerts: []*Alert{Alert_builder{
+			Title: "Closure",
+		}.Build()},
+	}.Build()
+	if act := Lightweight(pb); !proto.Equal(act, exp) {
+		t.Errorf("expected %v, got %v", exp, act)
+	}
+	if pb.GetFacilities()[0].GetDescription() == "" {
+		t.Errorf("original data was modified")
+	}
+}
+
+func TestIter(t *testing.T) {
+	tr := func(label string) *TimeRange {
+		return TimeRange_builder{Label: label}.Build()
+	}
+	pb := Data_builder{
+		Facilities: []*Facility{
+			Facility_builder{
+				Name: "A",
+				ScheduleGroups: []*ScheduleGroup{ScheduleGroup_builder{
+					Schedules: []*Schedule{
+						Schedule_builder{
+							Days: []string{"Mon", "Tue"},
+							Activities: []*Schedule_Activity{
+								Schedule_Activity_builder{
+									Label: "Swim",
+									Days: []*Schedule_ActivityDay{
+										Schedule_ActivityDay_builder{Times: []*TimeRange{tr("1"), tr("2")}}.Build(),
+										Schedule_ActivityDay_builder{}.Build(),
+										Schedule_ActivityDay_builder{Times: []*TimeRange{tr("3")}}.Build(),
+									},
+								}.Build(),
+								Schedule_Activity_builder{Label: "Skate"}.Build(),
+							},
+						}.Build(),
+						Schedule_builder{}.Build(),
+					},
+				}.Build()},
+			}.Build(),
+			Facility_builder{Name: "B"}.Build(),
+			Facility_builder{
+				Name: "C",
+				ScheduleGroups: []*ScheduleGroup{ScheduleGroup_builder{
+					Schedules: []*Schedule{Schedule_builder{
+						Activities: []*Schedule_Activity{Schedule_Activity_builder{
+							Label: "Gym",
+							Days: []*Schedule_ActivityDay{
+								Schedule_ActivityDay_builder{Times: []*TimeRange{tr("4")}}.Build(),
+							},
+						}.Build()},
+					}.Build()},
+				}.Build()},
+			}.Build(),
+		},
+	}.Build()
+
+	var schedules int
+	for range pb.AllSchedules() {
+		schedules++
+	}
+	if schedules != 3 {
+		t.Errorf("expected 3 schedules, got %d", schedules)
+	}
+	var activities []string
+	for x := range pb.AllActivities() {
+		activities = append(activities, x.Facility.GetName()+"/"+x.Activity.GetLabel())
+	}
+	if exp := []string{"A/Swim", "A/Skate", "C/Gym"}; !slices.Equal(activities, exp) {
+		t.Errorf("expected activities %q, got %q", exp, activities)
+	}
+	var times []string
+	for x := range pb.AllTimeRanges() {
+		times = append(times, fmt.Sprintf("%s/%s/%d/%s/%s", x.Facility.GetName(), x.Activity.GetLabel(), x.DayIndex, x.Day, x.Time.GetLabel()))
+	}
+	if exp := []string{"A/Swim/0/Mon/1", "A/Swim/0/Mon/2", "A/Swim/2//3", "C/Gym/0//4"}; !slices.Equal(times, exp) {
+		t.Errorf("expected time ranges %q, got %q", exp, times)
+	}
+	for range pb.AllTimeRanges() {
+		break // must not panic
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tr := func(w Weekday) *TimeRange {
+		return TimeRange_builder{XWkday: &w}.Build()
+	}
+	facility := func(name string) *Facility {
+		return Facility_builder{
+			Name: name,
+			ScheduleGroups: []*ScheduleGroup{ScheduleGroup_builder{
+				Schedules: []*Schedule{Schedule_builder{
+					Activities: []*Schedule_Activity{
+						Schedule_Activity_builder{
+							Label: "Swim",
+							Days: []*Schedule_ActivityDay{
+								Schedule_ActivityDay_builder{Times: []*TimeRange{tr(Weekday_MONDAY)}}.Build(),
+								Schedule_ActivityDay_builder{Times: []*TimeRange{tr(Weekday_TUESDAY)}}.Build(),
+							},
+						}.Build(),
+						Schedule_Activity_builder{
+							Label: "Skate",
+							Days: []*Schedule_ActivityDay{
+								Schedule_ActivityDay_builder{Times: []*TimeRange{tr(Weekday_MONDAY)}}.Build(),
+							},
+						}.Build(),
+					},
+				}.Build()},
+			}.Build()},
+		}.Build()
+	}
+	pb := Data_builder{Facilities: []*Facility{facility("A"), facility("B")}}.Build()
+	orig := DeepClone(pb)
+
+	if out := (Filter{}).Apply(pb); !proto.Equal(out, pb) || out == pb {
+		t.Errorf("empty filter: expected an identical copy")
+	}
+	out := Filter{
+		Facility: func(f *Facility) bool {
+			return f.GetName() == "B"
+		},
+		TimeRange: func(t *TimeRange) bool {
+			return t.GetXWkday() == Weekday_TUESDAY
+		},
+	}.Apply(pb)
+	var act []string
+	for x := range out.AllTimeRanges() {
+		act = append(act, fmt.Sprintf("%s/%s/%d", x.Facility.GetName(), x.Activity.GetLabel(), x.DayIndex))
+	}
+	if exp := []string{"B/Swim/1"}; !slices.Equal(act, exp) {
+		t.Errorf("expected %q, got %q", exp, act)
+	}
+	if n := len(out.GetFacilities()[0].GetScheduleGroups()[0].GetSchedules()[0].GetActivities()[0].GetDays()); n != 2 {
+		t.Errorf("expected activity days to be kept, got %d", n)
+	}
+	if !proto.Equal(pb, orig) {
+		t.Errorf("original data was modified")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := Data_builder{
+		Facilities:  []*Facility{Facility_builder{Name: "A"}.Build()},
+		Attribution: []string{"x", "y"},
+		Activities: []*ActivityInfo{
+			ActivityInfo_builder{XName: "swim"}.Build(),
+			ActivityInfo_builder{XName: "gym"}.Build(),
+		},
+		Alerts: []*Alert{Alert_builder{Title: "Closed"}.Build()},
+		XMeta:  Meta_builder{RunId: "1"}.Build(),
+	}.Build()
+	b := Data_builder{
+		Facilities:  []*Facility{Facility_builder{Name: "A"}.Build(), Facility_builder{Name: "B"}.Build()},
+		Attribution: []string{"y", "z"},
+		Activities:  []*ActivityInfo{ActivityInfo_builder{XName: "skate"}.Build(), ActivityInfo_builder{XName: "swim"}.Build()},
+		Alerts:      []*Alert{Alert_builder{Title: "Closed"}.Build(), Alert_builder{Title: "Open"}.Build()},
+	}.Build()
+	m := Merge(a, b)
+	var facilities, activities []string
+	for _, f := range m.GetFacilities() {
+		facilities = append(facilities, f.GetName())
+	}
+	for _, x := range m.GetActivities() {
+		activities = append(activities, x.GetXName())
+	}
+	if exp := []string{"A", "A", "B"}; !slices.Equal(facilities, exp) {
+		t.Errorf("expected facilities %q, got %q", exp, facilities)
+	}
+	if exp := []string{"gym", "skate", "swim"}; !slices.Equal(activities, exp) {
+		t.Errorf("expected activities %q, got %q", exp, activities)
+	}
+	if exp := []string{"x", "y", "z"}; !slices.Equal(m.GetAttribution(), exp) {
+		t.Errorf("expected attribution %q, got %q", exp, m.GetAttribution())
+	}
+	if n := len(m.GetAlerts()); n != 2 {
+		t.Errorf("expected 2 alerts, got %d", n)
+	}
+	if m.HasXMeta() {
+		t.Errorf("expected metadata to be dropped")
+	}
+	if v := m.GetSchemaVersion(); v != SchemaVersion {
+		t.Errorf("expected schema version %d, got %d", SchemaVersion, v)
+	}
+	if m.GetFacilities()[0] == a.GetFacilities()[0] {
+		t.Errorf("expected facilities to be copied")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	exp := Data_builder{
+		SchemaVersion: SchemaVersion,
+		Facilities: []*Facility{Facility_builder{
+			Name:   "A Pool",
+			Source: Source_builder{Url: "https://ottawa.ca/en/place-listing/a-pool"}.Build(),
+			XId:    "a-pool",
+			XDiagnostics: []*Diagnostic{
+				Diagnostic_builder{Stage: Diagnostic_FETCH, Message: "failed to fetch data: timeout"}.Build(),
+				Diagnostic_builder{Severity: Diagnostic_WARNING, Message: `failed to parse time range "noon"`}.Build(),
+			},
+		}.Build()},
+		Activities: []*ActivityInfo{ActivityInfo_builder{
+			XName:        "swim",
+			XDiagnostics: []*Diagnostic{Diagnostic_builder{Message: "no description"}.Build()},
+		}.Build()},
+	}.Build()
+
+	legacyJSON := `{
+		"facilities": [{
+			"name": "A Pool",
+			"source": {"url": "https://ottawa.ca/en/place-listing/a-pool"},
+			"_errors": ["failed to fetch data: timeout", "warning: failed to parse time range \"noon\""]
+		}],
+		"activities": [{"_name": "swim", "_errors": ["no description"]}]
+	}`
+	if pb, err := Unmarshal([]byte(legacyJSON), "json"); err != nil {
+		t.Errorf("json: unexpected error: %v", err)
+	} else if !proto.Equal(pb, exp) {
+		t.Errorf("json: expected %v, got %v", exp, pb)
+	}
+
+	legacy := Data_builder{
+		Facilities: []*Facility{Facility_builder{
+			Name:   "A Pool",
+			Source: Source_builder{Url: "https://ottawa.ca/en/place-listing/a-pool"}.Build(),
+		}.Build()},
+		Activities: []*ActivityInfo{ActivityInfo_builder{XName: "swim"}.Build()},
+	}.Build()
+	appendUnknownStrings(legacy.GetFacilities()[0], facilityErrorsField, []string{"failed to fetch data: timeout", `warning: failed to parse time range "noon"`})
+	appendUnknownStrings(legacy.GetActivities()[0], activityErrorsField, []string{"no description"})
+	buf, err := proto.Marshal(legacy)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if pb, err := Unmarshal(buf, "binpb"); err != nil {
+		t.Errorf("binpb: unexpected error: %v", err)
+	} else if !proto.Equal(pb, exp) {
+		t.Errorf("binpb: expected %v, got %v", exp, pb)
+	}
+
+	cur := DeepClone(exp)
+	if err := Migrate(cur); err != nil || !proto.Equal(cur, exp) {
+		t.Errorf("expected current version to be unchanged")
+	}
+	if err := Migrate(Data_builder{SchemaVersion: SchemaVersion + 1}.Build()); err == nil {
+		t.Errorf("expected error for newer version")
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	sched := func(caption string, from int32) *Schedule {
+		return Schedule_builder{Caption: caption, XFrom: &from}.Build()
+	}
+	group := func(label string, schedules ...*Schedule) *ScheduleGroup {
+		return ScheduleGroup_builder{Label: label, Schedules: schedules}.Build()
+	}
+	mk := func(reverse bool) *Data {
+		fs := []*Facility{
+			Facility_builder{
+				Name: "B",
+				ScheduleGroups: []*ScheduleGroup{
+					group("Swim", sched("Winter", 2026_01_05_0), sched("Fall", 2025_12_01_0), sched("Fall", 2025_09_01_0)),
+					group("Skate"),
+				},
+			}.Build(),
+			Facility_builder{Name: "A", Source: Source_builder{Url: "https://example.com/2"}.Build()}.Build(),
+			Facility_builder{Name: "A", Source: Source_builder{Url: "https://example.com/1"}.Build()}.Build(),
+			Facility_builder{Name: "A", Source: Source_builder{Url: "https://example.com/1"}.Build()}.Build(),
+		}
+		attrib := []string{"y", "x", "y"}
+		if reverse {
+			slices.Reverse(fs)
+			slices.Reverse(attrib)
+			for _, g := range fs[0].GetScheduleGroups() {
+				slices.Reverse(g.GetSchedules())
+			}
+			slices.Reverse(fs[0].GetScheduleGroups())
+		}
+		return Data_builder{Facilities: fs, Attribution: attrib}.Build()
+	}
+	a, b := mk(false), mk(true)
+	Canonicalize(a)
+	Canonicalize(b)
+	if !proto.Equal(a, b) {
+		t.Errorf("expected canonicalized data to be equal:\n%v\n%v", a, b)
+	}
+	var act []string
+	for _, f := range a.GetFacilities() {
+		act = append(act, f.GetName()+" "+f.GetSource().GetUrl())
+		for x := range f.AllSchedules() {
+			act = append(act, fmt.Sprintf("  %s %s %d", x.Group.GetLabel(), x.Schedule.GetCaption(), x.Schedule.GetXFrom()))
+		}
+	}
+	if exp := []string{
+		"A https://example.com/1",
+		"A https://example.com/2",
+		"B ",
+		"  Swim Fall 202509010",
+		"  Swim Fall 202512010",
+		"  Swim Winter 202601050",
+	}; !slices.Equal(act, exp) {
+		t.Errorf("expected %q, got %q", exp, act)
+	}
+	if exp := []string{"x", "y"}; !slices.Equal(a.GetAttribution(), exp) {
+		t.Errorf("expected attribution %q, got %q", exp, a.GetAttribution())
+	}
+	if n := len(a.GetFacilities()[2].GetScheduleGroups()); n != 2 || a.GetFacilities()[2].GetScheduleGroups()[0].GetLabel() != "Skate" {
+		t.Errorf("expected schedule groups to be sorted by label")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	facility := func(name, notif string, slots ...string) *Facility {
+		var days []*Schedule_ActivityDay
+		for _, x := range slots {
+			days = append(days, Schedule_ActivityDay_builder{Times: []*TimeRange{TimeRange_builder{Label: x}.Build()}}.Build())
+		}
+		return Facility_builder{
+			Name:              name,
+			NotificationsHtml: notif,
+			Source:            Source_builder{Url: "https://example.com/" + strings.ToLower(name)}.Build(),
+			ScheduleGroups: []*ScheduleGroup{ScheduleGroup_builder{
+				Label: "Swimming",
+				Schedules: []*Schedule{Schedule_builder{
+					Caption: "Fall",
+					Days:    []string{"Mon", "Tue"},
+					Activities: []*Schedule_Activity{Schedule_Activity_builder{
+						Label: "Swim",
+						Days:  days,
+					}.Build()},
+				}.Build()},
+			}.Build()},
+		}.Build()
+	}
+	a := Data_builder{Facilities: []*Facility{facility("A", "", "7am", "8am"), facility("B", "")}}.Build()
+	b := Data_builder{Facilities: []*Facility{facility("C", ""), facility("A", "<p>Closed</p>", "7am", "9am")}}.Build()
+
+	var act []string
+	for _, c := range Diff(a, b) {
+		act = append(act, fmt.Sprintf("%s %s %q %q %q %q %q", c.Kind, c.Facility.GetName(), c.Field, c.Old, c.New, c.Schedule, c.Slot))
+	}
+	if exp := []string{
+		`FacilityRemoved B "" "" "" "" ""`,
+		`FacilityAdded C "" "" "" "" ""`,
+		`NotificationChanged A "notifications" "" "<p>Closed</p>" "" ""`,
+		`ScheduleChanged A "" "" "" "Swimming > Fall" ""`,
+		`TimeSlotAdded A "" "" "" "Swimming > Fall" "Swim: Tue 9am"`,
+		`TimeSlotRemoved A "" "" "" "Swimming > Fall" "Swim: Tue 8am"`,
+	}; !slices.Equal(act, exp) {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(exp, "\n"), strings.Join(act, "\n"))
+	}
+	if d := Diff(a, a); len(d) != 0 {
+		t.Errorf("expected no changes, got %v", d)
+	}
+}
+
+func TestParseClockRange(t *testing.T) {
+	for _, tc := range []struct {
+		A   string
+		Opt ParseOptions
+		B   string
+	}{
+		{"6:30-8 pm", ParseOptions{}, "18:30 - 20:00"},
+		{"11-1 pm", ParseOptions{}, "11:00 - 13:00"},
+		{"Noon to 1:30PM", ParseOptions{}, "12:00 - 13:30"},
+		{"10pm - 1am", ParseOptions{}, "22:00 - 01:00"},
+		{"1:00 am am - noon", ParseOptions{}, "01:00 - 12:00"},
+		{"1:00 am am - noon", ParseOptions{Strict: true}, ""},
+		{"9 - - 10 am", ParseOptions{}, "09:00 - 10:00"},
+		{"9 - - 10 am", ParseOptions{Strict: true}, ""},
+		{"de 18 h à 20 h 30", ParseOptions{}, ""},
+		{"de 18 h à 20 h 30", ParseOptions{French: true}, "18:00 - 20:30"},
+		{"midi - 13 h", ParseOptions{French: true}, "12:00 - 13:00"},
+		{"midi - 13 h", ParseOptions{}, ""},
+		{"9am", ParseOptions{}, ""},
+	} {
+		r, ok := ParseClockRange(tc.A, tc.Opt)
+		if tc.B == "" {
+			if ok {
+				t.Errorf("parse %q %+v: expected error, got %q", tc.A, tc.Opt, r.Format(false))
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("parse %q %+v: unexpected error", tc.A, tc.Opt)
+		} else if s := r.Format(false); s != tc.B {
+			t.Errorf("parse %q %+v: expected %q, got %q", tc.A, tc.Opt, tc.B, s)
+		}
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	for _, tc := range []struct {
+		A        string
+		Opt      ParseOptions
+		From, To Date
+		OK       bool
+	}{
+		{"January 6 to April 20", ParseOptions{}, 1_06_0, 4_20_0, true},
+		{"Jan 6 to 20, 2025", ParseOptions{}, 0, 0, false},
+		{"January 6, 2025 to 20", ParseOptions{}, 2025_01_06_0, 2025_01_20_0, true},
+		{"Starting Monday, January 6, 2025", ParseOptions{}, 2025_01_06_2, 0, true},
+		{"until April 20", ParseOptions{}, 0, 4_20_0, true},
+		{"December 24 and 25", ParseOptions{}, 12_24_0, 12_25_0, true},
+		{"December 24 and 25", ParseOptions{Strict: true}, 0, 0, false},
+		{"du 6 au 20 janvier", ParseOptions{}, 0, 0, false},
+		{"du 6 au 20 janvier", ParseOptions{French: true}, 1_06_0, 1_20_0, true},
+		{"à partir du 1er février", ParseOptions{French: true}, 2_01_0, 0, true},
+		{"6 janvier", ParseOptions{}, 0, 0, false},
+		{"January", ParseOptions{}, 0, 0, false},
+	} {
+		r, ok := ParseDateRange(tc.A, tc.Opt)
+		if ok != tc.OK {
+			t.Errorf("parse %q %+v: expected ok=%t, got %t", tc.A, tc.Opt, tc.OK, ok)
+		} else if ok && (r.From != tc.From || r.To != tc.To) {
+			t.Errorf("parse %q %+v: expected %d-%d, got %d-%d", tc.A, tc.Opt, tc.From, tc.To, r.From, r.To)
+		}
+	}
+	for _, tc := range []struct {
+		A             string
+		Opt           ParseOptions
+		Prefix, Dates string
+	}{
+		{"Lane swim - January 6 to April 20", ParseOptions{}, "Lane swim", "January 6 to April 20"},
+		{"Lane swim - starting January 6", ParseOptions{}, "Lane swim", "starting January 6"},
+		{"Bain libre - du 6 au 20 janvier", ParseOptions{}, "", ""},
+		{"Bain libre - du 6 au 20 janvier", ParseOptions{French: true}, "Bain libre", "du 6 au 20 janvier"},
+		{"Lane swim", ParseOptions{}, "", ""},
+	} {
+		prefix, dates, ok := CutDateRange(tc.A, tc.Opt)
+		if ok != (tc.Dates != "") {
+			t.Errorf("cut %q %+v: expected ok=%t, got %t", tc.A, tc.Opt, tc.Dates != "", ok)
+		} else if ok && (prefix != tc.Prefix || dates != tc.Dates) {
+			t.Errorf("cut %q %+v: expected %q %q, got %q %q", tc.A, tc.Opt, tc.Prefix, tc.Dates, prefix, dates)
+		}
+	}
+}
+
+func TestFacilityID(t *testing.T) {
+	for _, tc := range []struct {
+		url string
+		id  string
+	}{
+		{"https://ottawa.ca/en/recreation-and-parks/facilities/place-listing/routhier-community-centre", "routhier-community-centre"},
+		{"https://ottawa.ca/en/recreation-and-parks/facilities/place-listing/routhier-community-centre/?foo=bar#top", "routhier-community-centre"},
+		{"http://example.com/Place%20Listing/Jack_Purcell", "jack-purcell"},
+		{"https://ottawa.ca/fr/loisirs-et-parcs/installations/liste-des-lieux/centre-communautaire-élisabeth-bruyère", "centre-communautaire-élisabeth-bruyère"},
+		{"https://ottawa.ca/", ""},
+		{"https://ottawa.ca", ""},
+		{"", ""},
+		{"://", ""},
+	} {
+		if id := FacilityID(tc.url); id != tc.id {
+			t.Errorf("%q: expected %q, got %q", tc.url, tc.id, id)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	pb := Data_builder{
+		Facilities: []*Facility{
+			Facility_builder{
+				Name:     "Pool",
+				XAliases: []string{"Old Pool"},
+				XId:      "pool",
+				ScheduleGroups: []*ScheduleGroup{ScheduleGroup_builder{
+					Label: "Swimming",
+					Schedules: []*Schedule{
+						Schedule_builder{
+							Caption: "Fall",
+							XFrom:   ptrTo(int32(2025_09_01_0)),
+							XTo:     ptrTo(int32(2025_12_21_0)),
+							Activities: []*Schedule_Activity{Schedule_Activity_builder{
+								Label: "Lane swim",
+								XName: "lane swim",
+							}.Build()},
+						}.Build(),
+						Schedule_builder{
+							Caption:  "Thanksgiving",
+							XHoliday: "Thanksgiving",
+						}.Build(),
+						Schedule_builder{
+							Caption: "Winter",
+							XFrom:   ptrTo(int32(2026_01_05_0)),
+						}.Build(),
+					},
+				}.Build()},
+			}.Build(),
+			Facility_builder{
+				Name: "Pool!",
+				ScheduleGroups: []*ScheduleGroup{ScheduleGroup_builder{
+					Schedules: []*Schedule{Schedule_builder{
+						Caption: "Schedule",
+						Activities: []*Schedule_Activity{Schedule_Activity_builder{
+							Label: "Lane swim (25m)",
+							XName: "lane swim",
+						}.Build()},
+					}.Build()},
+				}.Build()},
+			}.Build(),
+		},
+		Activities: []*ActivityInfo{ActivityInfo_builder{
+			XName: "lane swim",
+			Title: "Lane Swim",
+		}.Build()},
+	}.Build()
+	idx := NewIndex(pb)
+	a, b := pb.GetFacilities()[0], pb.GetFacilities()[1]
+
+	if f, ok := idx.Facility("old POOL"); !ok || f != a {
+		t.Errorf("facility by alias: got %v", f)
+	}
+	if _, ok := idx.Facility("nope"); ok {
+		t.Errorf("facility: unexpected match")
+	}
+	if slug, _ := idx.FacilitySlug(b); slug != "pool-2" {
+		t.Errorf("facility slug: got %q", slug)
+	}
+	if f, ok := idx.FacilityBySlug("pool-2"); !ok || f != b {
+		t.Errorf("facility by slug: got %v", f)
+	}
+	if f, ok := idx.FacilityByID("pool"); !ok || f != a {
+		t.Errorf("facility by id: got %v", f)
+	}
+	if refs := idx.Activities("lane swim"); len(refs) != 2 || refs[0].Facility != a || refs[1].Activity.GetLabel() != "Lane swim (25m)" {
+		t.Errorf("activities: got %v", refs)
+	}
+	if info, ok := idx.ActivityInfo("lane swim"); !ok || info.GetTitle() != "Lane Swim" {
+		t.Errorf("activity info: got %v", info)
+	}
+	for _, tc := range []struct {
+		Date      time.Time
+		Schedules []string
+	}{
+		{time.Date(2025, time.October, 1, 12, 0, 0, 0, time.UTC), []string{"Fall", "Schedule"}},
+		{time.Date(2025, time.October, 13, 12, 0, 0, 0, time.UTC), []string{"Fall", "Thanksgiving", "Schedule"}},
+		{time.Date(2026, time.February, 1, 12, 0, 0, 0, time.UTC), []string{"Winter", "Schedule"}},
+	} {
+		var act []string
+		for _, ref := range idx.SchedulesOn(tc.Date) {
+			act = append(act, ref.Schedule.GetCaption())
+		}
+		if !slices.Equal(act, tc.Schedules) {
+			t.Errorf("schedules on %s: expected %q, got %q", tc.Date.Format(time.DateOnly), tc.Schedules, act)
+		}
+	}
+	if act := Slug("Île de la Cité – Pool (25m)"); act != "île-de-la-cité-pool-25m" {
+		t.Errorf("slug: got %q", act)
+	}
+}
+
+func TestGeo(t *testing.T) {
+	ll := func(lng, lat float32) *LngLat {
+		return LngLat_builder{Lng: lng, Lat: lat}.Build()
+	}
+	var (
+		parliament = ll(-75.6999, 45.4236)
+		toronto    = ll(-79.3832, 43.6532)
+	)
+	for _, tc := range []struct {
+		A, B *LngLat
+		Dist float64 // metres
+	}{
+		{parliament, parliament, 0},
+		{parliament, toronto, 352_000},
+		{ll(0, 0), ll(180, 0), math.Pi * 6371e3},
+		{ll(0, 0), ll(0, 1), 111_195},
+	} {
+		if act := tc.A.DistanceTo(tc.B); math.Abs(act-tc.Dist) > max(tc.Dist*0.005, 1) {
+			t.Errorf("distance %v to %v: expected %.0f, got %.0f", tc.A, tc.B, tc.Dist, act)
+		}
+		if act := tc.B.DistanceTo(tc.A); math.Abs(act-tc.Dist) > max(tc.Dist*0.005, 1) {
+			t.Errorf("distance %v to %v: expected %.0f, got %.0f", tc.B, tc.A, tc.Dist, act)
+		}
+	}
+
+	b := BoundsAround(parliament, 5000)
+	for _, tc := range []struct {
+		Point  *LngLat
+		Within bool
+	}{
+		{parliament, true},
+		{ll(-75.6999, 45.4236+0.04), true},  // ~4.4 km north
+		{ll(-75.6999, 45.4236+0.05), false}, // ~5.6 km north
+		{ll(-75.6999+0.06, 45.4236), true},  // ~4.7 km east
+		{ll(-75.6999+0.07, 45.4236), false}, // ~5.5 km east
+		{toronto, false},
+		{nil, false},
+	} {
+		if act := tc.Point.Within(b); act != tc.Within {
+			t.Errorf("%v within %v: expected %t, got %t", tc.Point, b, tc.Within, act)
+		}
+	}
+	if b, ok := BoundsOf(parliament, nil, toronto); !ok || b != (GeoBounds{float64(toronto.GetLng()), float64(toronto.GetLat()), float64(parliament.GetLng()), float64(parliament.GetLat())}) {
+		t.Errorf("bounds of: got %v", b)
+	} else if c := b.Center(); !c.Within(b) {
+		t.Errorf("center %v not within %v", c, b)
+	}
+	if _, ok := BoundsOf(); ok {
+		t.Errorf("bounds of nothing: expected false")
+	}
+}
+
+func TestGeohash(t *testing.T) {
+	ll := LngLat_builder{Lng: 10.40744, Lat: 57.64911}.Build()
+	if h := ll.Geohash(9); h != "u4pruydqq" {
+		t.Errorf("expected u4pruydqq, got %q", h)
+	}
+	if h := ll.Geohash(0); h != "u" {
+		t.Errorf("expected precision to be clamped, got %q", h)
+	}
+	for _, p := range []int{1, 5, 8} {
+		h := ll.Geohash(p)
+		b, ok := GeohashBounds(strings.ToUpper(h))
+		if !ok || !b.Contains(ll) {
+			t.Errorf("%q: expected bounds %v to contain the point", h, b)
+		}
+		if c := GeohashDecode(h); c.Geohash(p) != h {
+			t.Errorf("%q: expected center to have the same geohash, got %q", h, c.Geohash(p))
+		}
+	}
+	for _, h := range []string{"", "u4a", "u4p!"} {
+		if _, ok := GeohashBounds(h); ok {
+			t.Errorf("%q: expected invalid geohash", h)
+		}
+		if GeohashDecode(h) != nil {
+			t.Errorf("%q: expected nil", h)
+		}
+	}
+}
+func TestOccurrences(t *testing.T) {
+	monday := func(label string, start, end int32) *Schedule_Activity {
+		return Schedule_Activity_builder{
+			Label: label,
+			Days: []*Schedule_ActivityDay{Schedule_ActivityDay_builder{
+				Times: []*TimeRange{
+					TimeRange_builder{XWkday: ptrTo(Weekday_MONDAY), XStart: ptrTo(start), XEnd: ptrTo(end)}.Build(),
+					TimeRange_builder{Label: "unparsed"}.Build(),
+				},
+			}.Build()},
+		}.Build()
+	}
+	date := func(y int, m time.Month, d int) int32 {
+		return int32(MakeDate(y, m, d, -1))
+	}
+	f := Facility_builder{
+		Name: "A Pool",
+		ScheduleGroups: []*ScheduleGroup{
+			ScheduleGroup_builder{
+				Schedules: []*Schedule{
+					Schedule_builder{
+						XFrom:      ptrTo(date(2025, time.September, 1)),
+						XTo:        ptrTo(date(2025, time.October, 13)),
+						Days:       []string{"Monday"},
+						Activities: []*Schedule_Activity{monday("Lane swim", 9*60, 10*60)},
+					}.Build(),
+					Schedule_builder{
+						XHoliday:   "Labour Day",
+						Days:       []string{"Monday"},
+						Activities: []*Schedule_Activity{monday("Holiday swim", 10*60, 12*60)},
+					}.Build(),
+				},
+			}.Build(),
+			ScheduleGroup_builder{
+				Schedules: []*Schedule{
+					Schedule_builder{
+						Days:       []string{"Monday September 8"},
+						XDaydates:  []int32{date(2025, time.September, 8)},
+						Activities: []*Schedule_Activity{monday("Aquafit", 18*60, 19*60)},
+					}.Build(),
+				},
+			}.Build(),
+		},
+	}.Build()
+
+	loc, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	type occ struct {
+		Activity string
+		Date     string
+		Range    string
+		Holiday  string
+	}
+	var got []occ
+	for o := range f.Occurrences(time.Date(2025, time.August, 25, 12, 0, 0, 0, time.UTC), time.Date(2025, time.October, 20, 0, 0, 0, 0, time.UTC), loc) {
+		x := occ{o.Activity.GetLabel(), o.Date.Format(time.DateOnly), o.Range.String(), ""}
+		if exp := time.Date(o.Date.Year(), o.Date.Month(), o.Date.Day(), 0, int(o.Range.Start), 0, 0, loc); !o.Start.Equal(exp) || o.Start.Location() != loc {
+			t.Errorf("unexpected start time %s for %s", o.Start, o.Date.Format(time.DateOnly))
+		}
+		if o.Holiday != nil {
+			x.Holiday = o.Holiday.Name
+		}
+		got = append(got, x)
+	}
+	exp := []occ{
+		{"Lane swim", "2025-09-08", "9:00 - 10:00am", ""},
+		{"Lane swim", "2025-09-15", "9:00 - 10:00am", ""},
+		{"Lane swim", "2025-09-22", "9:00 - 10:00am", ""},
+		{"Lane swim", "2025-09-29", "9:00 - 10:00am", ""},
+		{"Lane swim", "2025-10-06", "9:00 - 10:00am", ""},
+		{"Lane swim", "2025-10-13", "9:00 - 10:00am", "Thanksgiving"},
+		{"Holiday swim", "2025-09-01", "10:00am - 12:00pm", ""},
+		{"Aquafit", "2025-09-08", "6:00 - 7:00pm", ""},
+	}
+	if !slices.Equal(got, exp) {
+		t.Errorf("unexpected occurrences:\n\tgot: %q\n\texp: %q", got, exp)
+	}
+}
+
+func TestLocalTime(t *testing.T) {
+	loc, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	for _, tc := range []struct {
+		Date  time.Time
+		Range ClockRange
+		Start string
+		End   string
+	}{
+		{time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), MakeClockRange(9, 0, 10, 30), "2025-07-01T09:00:00-04:00", "2025-07-01T10:30:00-04:00"},
+		{time.Date(2025, time.January, 6, 0, 0, 0, 0, time.UTC), MakeClockRange(23, 0, 25, 0), "2025-01-06T23:00:00-05:00", "2025-01-07T01:00:00-05:00"},
+		{time.Date(2025, time.November, 2, 0, 0, 0, 0, time.UTC), MakeClockRange(0, 30, 3, 0), "2025-11-02T00:30:00-04:00", "2025-11-02T03:00:00-05:00"}, // dst ends
+		{time.Date(2025, time.March, 9, 0, 0, 0, 0, time.UTC), MakeClockRange(1, 0, 2, 30), "2025-03-09T01:00:00-05:00", "2025-03-09T03:30:00-04:00"},    // dst starts
+	} {
+		start, end := LocalTime(tc.Date, tc.Range.Start, loc), LocalTime(tc.Date, tc.Range.End, loc)
+		if act := start.Format(time.RFC3339); act != tc.Start {
+			t.Errorf("%s %s: expected start %s, got %s", tc.Date.Format(time.DateOnly), tc.Range, tc.Start, act)
+		}
+		if act := end.Format(time.RFC3339); act != tc.End {
+			t.Errorf("%s %s: expected end %s, got %s", tc.Date.Format(time.DateOnly), tc.Range, tc.End, act)
+		}
+	}
+}
+
+func TestRecurrence(t *testing.T) {
+	loc, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	for _, tc := range []struct {
+		Weekday time.Weekday
+		Range   ClockRange
+		Dates   DateRange
+		Loc     *time.Location
+		Result  string
+	}{
+		{time.Monday, MakeClockRange(7, 0, 8, 0), DateRange{2025_09_01_0, 2025_12_21_0}, loc, "DTSTART;TZID=America/Toronto:20250901T070000 DTEND;TZID=America/Toronto:20250901T080000 RRULE:FREQ=WEEKLY;BYDAY=MO;UNTIL=20251222T045959Z"},
+		{time.Friday, MakeClockRange(22, 0, 25, 0), DateRange{2025_09_01_0, 0}, loc, "DTSTART;TZID=America/Toronto:20250905T220000 DTEND;TZID=America/Toronto:20250906T010000 RRULE:FREQ=WEEKLY;BYDAY=FR"},
+		{time.Sunday, MakeClockRange(9, 30, 10, 0), DateRange{2025_06_01_0, 2025_06_30_0}, time.UTC, "DTSTART:20250601T093000Z DTEND:20250601T100000Z RRULE:FREQ=WEEKLY;BYDAY=SU;UNTIL=20250630T235959Z"},
+		{time.Monday, MakeClockRange(7, 0, 8, 0), DateRange{2025_09_02_0, 2025_09_07_0}, loc, ""}, // no occurrences
+		{time.Monday, MakeClockRange(7, 0, 8, 0), DateRange{9_01_0, 12_21_0}, loc, ""},            // no year
+		{time.Monday, MakeClockRange(7, 0, 8, 0), DateRange{2025_09_01_0, 2025_12_00_0}, loc, ""}, // partial end
+		{time.Monday, ClockRange{-1, -1}, DateRange{2025_09_01_0, 2025_12_21_0}, loc, ""},         // no time
+		{-1, MakeClockRange(7, 0, 8, 0), DateRange{2025_09_01_0, 2025_12_21_0}, loc, ""},          // no weekday
+	} {
+		var act string
+		if rec, ok := MakeRecurrence(tc.Weekday, tc.Range, tc.Dates, tc.Loc); ok {
+			act = rec.DTStart + " " + rec.DTEnd + " " + rec.RRule
+		}
+		if act != tc.Result {
+			t.Errorf("%v %v %v: expected %q, got %q", tc.Weekday, tc.Range, tc.Dates, tc.Result, act)
+		}
+	}
+	s := Schedule_builder{XFrom: ptrTo(int32(2025_09_01_0))}.Build()
+	if rec, ok := MakeTimeRange("", time.Tuesday, MakeClockRange(7, 0, 8, 0)).AsXRecurrence(s, loc); !ok || rec.DTStart != "DTSTART;TZID=America/Toronto:20250902T070000" {
+		t.Errorf("time range recurrence: got %v", rec)
+	}
+}
+
+func ptrTo[T any](x T) *T {
+	return &x
+}
+
+func TestHolidays(t *testing.T) {
+	for _, tc := range []struct {
+		Year  int
+		Dates []Date
+	}{
+		{2025, []Date{2025_01_01_4, 2025_02_17_2, 2025_04_18_6, 2025_05_19_2, 2025_07_01_3, 2025_08_04_2, 2025_09_01_2, 2025_10_13_2, 2025_12_25_5, 2025_12_26_6}},
+		{2026, []Date{2026_01_01_5, 2026_02_16_2, 2026_04_03_6, 2026_05_18_2, 2026_07_01_4, 2026_08_03_2, 2026_09_07_2, 2026_10_12_2, 2026_12_25_6, 2026_12_26_7}},
+		{2027, []Date{2027_01_01_6, 2027_02_15_2, 2027_03_26_6, 2027_05_24_2, 2027_07_01_5, 2027_08_02_2, 2027_09_06_2, 2027_10_11_2, 2027_12_25_7, 2027_12_26_1}},
+	} {
+		if len(tc.Dates) != len(Holidays()) {
+			panic("invalid test case")
+		}
+		for i, h := range Holidays() {
+			if d := h.Date(tc.Year); d != tc.Dates[i] {
+				t.Errorf("%s %d: expected %#v, got %#v", h.Name, tc.Year, tc.Dates[i], d)
+			} else if !d.IsValid() {
+				t.Errorf("%s %d: invalid date %#v", h.Name, tc.Year, d)
+			}
+			year, _ := tc.Dates[i].Year()
+			month, _ := tc.Dates[i].Month()
+			day, _ := tc.Dates[i].Day()
+			if x, ok := HolidayOn(year, month, day); !ok || x != h {
+				t.Errorf("%s %d: holiday on %#v not found", h.Name, tc.Year, tc.Dates[i])
+			}
+		}
+	}
+	for _, tc := range []struct {
+		H string
+		T time.Time
+		D Date
+	}{
+		{"labour day", time.Date(2025, 10, 7, 0, 0, 0, 0, time.UTC), 2026_09_07_2},
+		{"labour day", time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC), 2025_09_01_2},
+		{"labour day", time.Date(2025, 4, 7, 0, 0, 0, 0, time.UTC), 2025_09_01_2},
+		{"labour day", time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), 2025_09_01_2},
+		{"labour day", time.Date(2025, 9, 8, 23, 0, 0, 0, time.UTC), 2025_09_01_2},
+		{"labour day", time.Date(2025, 9, 9, 0, 0, 0, 0, time.UTC), 2026_09_07_2},
+		{"thanksgiving", time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), 2026_10_12_2},
+		{"new year's day", time.Date(2025, 12, 28, 0, 0, 0, 0, time.UTC), 2026_01_01_5},
+		{"new year's day", time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), 2026_01_01_5},
+		{"christmas day", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 2025_12_25_5},
+		{"christmas day", time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), 2026_12_25_6},
+	} {
+		h, _ := ParseHoliday(tc.H)
+		if d := h.Nearest(tc.T); d != tc.D {
+			t.Errorf("nearest %s to %s: expected %#v, got %#v", h.Name, tc.T, tc.D, d)
+		}
+	}
+	for _, tc := range []struct {
+		S, H string
+	}{
+		{"Ray Friel Recreation Complex - skating - Labour Day", "Labour Day"},
+		{"Closed on Christmas Day and Boxing Day", "Christmas Day"},
+		{"Boxing Day and Christmas Day", "Boxing Day"},
+		{"New Year’s Day schedule", "New Year's Day"},
+		{"Fête du Canada", "Canada Day"},
+		{"Thanksgivings", ""},
+		{"Christmas Eve", ""},
+		{"Action de grâces", "Thanksgiving"},
+		{"", ""},
+	} {
+		h, ok := FindHoliday(tc.S)
+		if tc.H == "" {
+			if ok {
+				t.Errorf("find %q: expected none, got %q", tc.S, h.Name)
+			}
+			continue
+		}
+		if !ok || h.Name != tc.H {
+			t.Errorf("find %q: expected %q, got %v", tc.S, tc.H, h)
+		}
+	}
+}
+
+func TestDescriptor(t *testing.T) {
+	files, err := protodesc.NewFiles(Descriptor())
+	if err != nil {
+		t.Fatalf("invalid descriptor set: %v", err)
+	}
+	d, err := files.FindDescriptorByName("ottrec.v1.Data")
+	if err != nil {
+		t.Fatalf("find data message: %v", err)
+	}
+	if d.(protoreflect.MessageDescriptor).Fields().ByName("facilities") == nil {
+		t.Errorf("missing facilities field")
+	}
+}
@@ -0,0 +1,113 @@
+package schema
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+)
+
+// The scalar types are stored in databases as their integer representations,
+// with unspecified clock times and dates as NULL. Use [sql.Null] for nullable
+// weekday columns.
+
+var (
+	_ driver.Valuer = ClockTime(0)
+	_ sql.Scanner   = (*ClockTime)(nil)
+	_ driver.Valuer = Date(0)
+	_ sql.Scanner   = (*Date)(nil)
+	_ driver.Valuer = Weekday(0)
+	_ sql.Scanner   = (*Weekday)(nil)
+)
+
+// Value returns the number of minutes since midnight, or nil if t is invalid.
+func (t ClockTime) Value() (driver.Value, error) {
+	if !t.IsValid() {
+		return nil, nil
+	}
+	return int64(t), nil
+}
+
+// Scan is the inverse of Value.
+func (t *ClockTime) Scan(src any) error {
+	if src == nil {
+		*t = -1
+		return nil
+	}
+	n, err := scanInt(src, "ClockTime")
+	if err != nil {
+		return err
+	}
+	*t = ClockTime(n).Norm()
+	return nil
+}
+
+// Value returns the integer representation of d, or nil if it is zero.
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return int64(d), nil
+}
+
+// Scan is the inverse of Value.
+func (d *Date) Scan(src any) error {
+	if src == nil {
+		*d = 0
+		return nil
+	}
+	n, err := scanInt(src, "Date")
+	if err != nil {
+		return err
+	}
+	*d = Date(n)
+	return nil
+}
+
+// Value returns the weekday number starting at Sunday=0.
+func (w Weekday) Value() (driver.Value, error) {
+	if w < Weekday_SUNDAY || w > Weekday_SATURDAY {
+		return nil, fmt.Errorf("invalid weekday %d", w)
+	}
+	return int64(w), nil
+}
+
+// Scan is the inverse of Value.
+func (w *Weekday) Scan(src any) error {
+	n, err := scanInt(src, "Weekday")
+	if err != nil {
+		return err
+	}
+	if x := Weekday(n); x < Weekday_SUNDAY || x > Weekday_SATURDAY {
+		return fmt.Errorf("scan Weekday: invalid weekday %d", n)
+	}
+	*w = Weekday(n)
+	return nil
+}
+
+// scanInt converts a database integer or string value within the int32 range.
+func scanInt(src any, typ string) (int64, error) {
+	var n int64
+	switch src := src.(type) {
+	case int64:
+		n = src
+	case []byte:
+		x, err := strconv.ParseInt(string(src), 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("scan %s: %w", typ, err)
+		}
+		n = x
+	case string:
+		x, err := strconv.ParseInt(src, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("scan %s: %w", typ, err)
+		}
+		n = x
+	default:
+		return 0, fmt.Errorf("scan %s: unsupported type %T", typ, src)
+	}
+	if int64(int32(n)) != n {
+		return 0, fmt.Errorf("scan %s: value %d out of range", typ, n)
+	}
+	return n, nil
+}
@@ -0,0 +1,339 @@
+package schema
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The scalar types are marshaled as human-readable strings for hand-written
+// json and other text formats. The zero value (or -1 for clock times) is an
+// empty string. For backwards compatibility, json numbers (and objects for the
+// range types) are also accepted when unmarshaling.
+
+var (
+	_ encoding.TextMarshaler   = ClockTime(0)
+	_ encoding.TextUnmarshaler = (*ClockTime)(nil)
+	_ json.Marshaler           = ClockTime(0)
+	_ json.Unmarshaler         = (*ClockTime)(nil)
+	_ encoding.TextMarshaler   = ClockRange{}
+	_ encoding.TextUnmarshaler = (*ClockRange)(nil)
+	_ json.Marshaler           = ClockRange{}
+	_ json.Unmarshaler         = (*ClockRange)(nil)
+	_ encoding.TextMarshaler   = Date(0)
+	_ encoding.TextUnmarshaler = (*Date)(nil)
+	_ json.Marshaler           = Date(0)
+	_ json.Unmarshaler         = (*Date)(nil)
+	_ encoding.TextMarshaler   = DateRange{}
+	_ encoding.TextUnmarshaler = (*DateRange)(nil)
+	_ json.Marshaler           = DateRange{}
+	_ json.Unmarshaler         = (*DateRange)(nil)
+)
+
+// MarshalText formats t like "18:30", with a ">" for each day past the first.
+// Invalid times are formatted as an empty string.
+func (t ClockTime) MarshalText() ([]byte, error) {
+	if !t.IsValid() {
+		return []byte{}, nil
+	}
+	return []byte(t.Format(false)), nil
+}
+
+// UnmarshalText is the inverse of MarshalText.
+func (t *ClockTime) UnmarshalText(b []byte) error {
+	x, err := parseClockTimeText(string(b))
+	if err != nil {
+		return err
+	}
+	*t = x
+	return nil
+}
+
+func (t ClockTime) MarshalJSON() ([]byte, error) {
+	return marshalJSONText(t)
+}
+
+func (t *ClockTime) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONText(b, t, func(n int64) error {
+		*t = ClockTime(n).Norm()
+		return nil
+	}, nil)
+}
+
+func parseClockTimeText(s string) (ClockTime, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return -1, nil
+	}
+	x := strings.TrimLeft(s, ">")
+	d := len(s) - len(x)
+	hh, mm, ok := strings.Cut(x, ":")
+	if ok && len(mm) == 2 {
+		h, err1 := strconv.Atoi(hh)
+		m, err2 := strconv.Atoi(mm)
+		if err1 == nil && err2 == nil && h >= 0 && h < 24 && m >= 0 && m < 60 {
+			return ClockTime(d*24*60 + h*60 + m), nil
+		}
+	}
+	return -1, fmt.Errorf("invalid clock time %q (expected HH:MM)", s)
+}
+
+// MarshalText formats r like "18:30 - 20:00". Ranges with an invalid side are
+// formatted as an empty string, and other invalid ranges are an error.
+func (r ClockRange) MarshalText() ([]byte, error) {
+	if !r.Start.IsValid() || !r.End.IsValid() {
+		return []byte{}, nil
+	}
+	if !r.IsValid() {
+		return nil, fmt.Errorf("cannot marshal invalid clock range %#v", r)
+	}
+	return []byte(r.Format(false)), nil
+}
+
+// UnmarshalText is the inverse of MarshalText. If the end is before the start,
+// it is assumed to be on the next day.
+func (r *ClockRange) UnmarshalText(b []byte) error {
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		*r = ClockRange{-1, -1}
+		return nil
+	}
+	x, y, ok := strings.Cut(s, "-")
+	if !ok {
+		return fmt.Errorf("invalid clock range %q (expected HH:MM - HH:MM)", s)
+	}
+	start, err := parseClockTimeText(x)
+	if err != nil {
+		return fmt.Errorf("invalid clock range %q: start: %w", s, err)
+	}
+	end, err := parseClockTimeText(y)
+	if err != nil {
+		return fmt.Errorf("invalid clock range %q: end: %w", s, err)
+	}
+	if !start.IsValid() || !end.IsValid() {
+		return fmt.Errorf("invalid clock range %q (expected HH:MM - HH:MM)", s)
+	}
+	if end <= start && !strings.HasPrefix(strings.TrimSpace(y), ">") {
+		end += 24 * 60
+	}
+	*r = ClockRange{start, end}
+	return nil
+}
+
+func (r ClockRange) MarshalJSON() ([]byte, error) {
+	return marshalJSONText(r)
+}
+
+func (r *ClockRange) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONText(b, r, nil, func(b []byte) error {
+		type plain ClockRange
+		return json.Unmarshal(b, (*plain)(r))
+	})
+}
+
+// MarshalText formats d like String (e.g., "Monday, January 6, 2025"). It
+// returns an error if d is invalid or has a day without a month.
+func (d Date) MarshalText() ([]byte, error) {
+	if d.IsZero() {
+		return []byte{}, nil
+	}
+	if !d.IsValid() {
+		return nil, fmt.Errorf("cannot marshal invalid date %#v", d)
+	}
+	s := d.String()
+	if x, err := parseDateText(s); err != nil || x != d {
+		return nil, fmt.Errorf("cannot marshal date %#v as text", d)
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText is the inverse of MarshalText. Weekday and month names are
+// case-insensitive.
+func (d *Date) UnmarshalText(b []byte) error {
+	x, err := parseDateText(string(b))
+	if err != nil {
+		return err
+	}
+	*d = x
+	return nil
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return marshalJSONText(d)
+}
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONText(b, d, func(n int64) error {
+		*d = Date(n)
+		return nil
+	}, nil)
+}
+
+func parseDateText(s string) (Date, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	var (
+		wkday  = time.Weekday(-1)
+		month  time.Month
+		nums   []int
+		fields = strings.FieldsFunc(s, func(r rune) bool {
+			return r == ' ' || r == ','
+		})
+	)
+	for i, f := range fields {
+		if n, err := strconv.Atoi(f); err == nil && n > 0 {
+			nums = append(nums, n)
+			continue
+		}
+		if i == 0 {
+			if w, ok := parseTextEnum(f, 7, func(w int) string { return time.Weekday(w).String() }); ok {
+				wkday = time.Weekday(w)
+				continue
+			}
+		}
+		if i <= 1 && month == 0 && len(nums) == 0 {
+			if m, ok := parseTextEnum(f, 12, func(m int) string { return time.Month(m + 1).String() }); ok {
+				month = time.Month(m + 1)
+				continue
+			}
+		}
+		return 0, fmt.Errorf("invalid date %q: unexpected %q", s, f)
+	}
+	var year, day int
+	switch {
+	case len(nums) == 0:
+	case len(nums) == 1 && month != 0 && nums[0] <= 31:
+		day = nums[0]
+	case len(nums) == 1:
+		year = nums[0]
+	case len(nums) == 2 && month != 0:
+		day, year = nums[0], nums[1]
+	default:
+		return 0, fmt.Errorf("invalid date %q: too many numbers", s)
+	}
+	x := MakeDate(year, month, day, wkday)
+	if !x.IsValid() {
+		return 0, fmt.Errorf("invalid date %q", s)
+	}
+	return x, nil
+}
+
+// parseTextEnum case-insensitively matches s against the names of n values.
+func parseTextEnum(s string, n int, name func(int) string) (int, bool) {
+	for i := range n {
+		if strings.EqualFold(s, name(i)) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// MarshalText formats d like String (e.g., "January 6 to April 6, 2025",
+// "starting January 6", "until April 6"). It returns an error if either side
+// can't be marshaled.
+func (d DateRange) MarshalText() ([]byte, error) {
+	for _, x := range []Date{d.From, d.To} {
+		if _, err := x.MarshalText(); err != nil {
+			return nil, err
+		}
+	}
+	s := d.String()
+	if x, err := parseDateRangeText(s); err != nil || x != d {
+		return nil, fmt.Errorf("cannot marshal date range %s as text", s)
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText is the inverse of MarshalText. A single date is parsed as a
+// range starting and ending on that date.
+func (d *DateRange) UnmarshalText(b []byte) error {
+	x, err := parseDateRangeText(string(b))
+	if err != nil {
+		return err
+	}
+	*d = x
+	return nil
+}
+
+func (d DateRange) MarshalJSON() ([]byte, error) {
+	return marshalJSONText(d)
+}
+
+func (d *DateRange) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONText(b, d, nil, func(b []byte) error {
+		type plain DateRange
+		return json.Unmarshal(b, (*plain)(d))
+	})
+}
+
+func parseDateRangeText(s string) (DateRange, error) {
+	var (
+		r    DateRange
+		err  error
+		x, y string
+	)
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return r, nil
+	case strings.HasPrefix(s, "starting "):
+		x = strings.TrimPrefix(s, "starting ")
+	case strings.HasPrefix(s, "until "):
+		y = strings.TrimPrefix(s, "until ")
+	default:
+		var ok bool
+		if x, y, ok = strings.Cut(s, " to "); !ok {
+			y = x
+		}
+	}
+	if x != "" {
+		if r.From, err = parseDateText(x); err != nil {
+			return DateRange{}, fmt.Errorf("invalid date range %q: from: %w", s, err)
+		}
+	}
+	if y != "" {
+		if r.To, err = parseDateText(y); err != nil {
+			return DateRange{}, fmt.Errorf("invalid date range %q: to: %w", s, err)
+		}
+	}
+	return r, nil
+}
+
+// marshalJSONText marshals v as a json string using its text marshaler.
+func marshalJSONText(v encoding.TextMarshaler) ([]byte, error) {
+	b, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(b))
+}
+
+// unmarshalJSONText unmarshals a json string using the text unmarshaler, or a
+// number or object using the provided functions if not nil. Null is ignored.
+func unmarshalJSONText(b []byte, v encoding.TextUnmarshaler, number func(int64) error, object func([]byte) error) error {
+	b = bytes.TrimSpace(b)
+	switch {
+	case bytes.Equal(b, []byte("null")):
+		return nil
+	case len(b) != 0 && b[0] == '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		return v.UnmarshalText([]byte(s))
+	case len(b) != 0 && b[0] == '{' && object != nil:
+		return object(b)
+	case number != nil:
+		var n int64
+		if err := json.Unmarshal(b, &n); err == nil {
+			return number(n)
+		}
+	}
+	return fmt.Errorf("cannot unmarshal %s into %T", b, v)
+}
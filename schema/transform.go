@@ -0,0 +1,130 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// IsDerived checks whether a field contains data parsed or otherwise enriched
+// by the scraper (i.e., it is underscored) rather than coming directly from the
+// source page.
+func IsDerived(fd protoreflect.FieldDescriptor) bool {
+	return strings.HasPrefix(string(fd.Name()), "_")
+}
+
+// StripDerived returns a copy of pb with all derived fields removed, leaving
+// only the raw data from the source pages.
+func StripDerived(pb *Data) *Data {
+	pb = proto.CloneOf(pb)
+	clearFields(pb.ProtoReflect(), IsDerived)
+	return pb
+}
+
+// OnlyDerived returns a copy of pb with only the derived fields, and the
+// messages containing them. Lists of messages are kept in full so indexes still
+// correspond to the original data, but other raw fields are removed.
+func OnlyDerived(pb *Data) *Data {
+	pb = proto.CloneOf(pb)
+	onlyDerived(pb.ProtoReflect())
+	return pb
+}
+
+// Lightweight returns a copy of pb without the bulky html fields and
+// descriptions, for bandwidth-constrained consumers.
+func Lightweight(pb *Data) *Data {
+	pb = proto.CloneOf(pb)
+	clearFields(pb.ProtoReflect(), func(fd protoreflect.FieldDescriptor) bool {
+		name := string(fd.Name())
+		return name == "html" || strings.HasSuffix(name, "_html") || name == "description"
+	})
+	return pb
+}
+
+// Equal checks whether a and b (e.g., a Data, Facility, or Schedule) have the
+// same content, ignoring derived fields (including scrape metadata like source
+// dates and diagnostics).
+func Equal[M proto.Message](a, b M) bool {
+	return proto.Equal(stripDerivedMessage(a), stripDerivedMessage(b))
+}
+
+// Hash returns a sha256 hash of the content of m, ignoring derived fields, such
+// that Equal messages have the same hash. It is only stable for a single
+// version of the schema and protobuf library.
+func Hash[M proto.Message](m M) [sha256.Size]byte {
+	buf, err := proto.MarshalOptions{Deterministic: true}.Marshal(stripDerivedMessage(m))
+	if err != nil {
+		panic(fmt.Errorf("schema: hash: %w", err)) // should never happen for a valid message
+	}
+	return sha256.Sum256(buf)
+}
+
+// stripDerivedMessage returns a copy of m without derived fields.
+func stripDerivedMessage(m proto.Message) proto.Message {
+	if m == nil || !m.ProtoReflect().IsValid() {
+		return m
+	}
+	m = proto.Clone(m)
+	clearFields(m.ProtoReflect(), IsDerived)
+	return m
+}
+
+// clearFields recursively clears the fields in m matching fn.
+func clearFields(m protoreflect.Message, fn func(protoreflect.FieldDescriptor) bool) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fn(fd):
+			m.Clear(fd)
+		case !isSchemaMessage(fd):
+		case fd.IsList():
+			for i := range v.List().Len() {
+				clearFields(v.List().Get(i).Message(), fn)
+			}
+		default:
+			clearFields(v.Message(), fn)
+		}
+		return true
+	})
+}
+
+// onlyDerived clears the raw fields in m, returning true if anything is left.
+func onlyDerived(m protoreflect.Message) bool {
+	var keep bool
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case IsDerived(fd):
+			keep = true
+		case !isSchemaMessage(fd):
+			m.Clear(fd)
+		case fd.IsList():
+			var found bool
+			for i := range v.List().Len() {
+				if onlyDerived(v.List().Get(i).Message()) {
+					found = true
+				}
+			}
+			if found {
+				keep = true
+			} else {
+				m.Clear(fd)
+			}
+		default:
+			if onlyDerived(v.Message()) {
+				keep = true
+			} else {
+				m.Clear(fd)
+			}
+		}
+		return true
+	})
+	return keep
+}
+
+// isSchemaMessage checks whether fd contains messages defined in the schema
+// (i.e., not well-known types like timestamps).
+func isSchemaMessage(fd protoreflect.FieldDescriptor) bool {
+	return fd.Message() != nil && fd.Message().ParentFile() == File_schema_proto
+}
@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Issue is a problem found by Validate.
+type Issue struct {
+	// Indexes of the facility, schedule group, schedule, and activity the issue
+	// is in, or -1 if not applicable.
+	Facility, Group, Schedule, Activity int
+
+	// Human-readable path to the location of the issue (e.g., `facility "A" >
+	// group "B"`), or empty if it applies to the data as a whole.
+	Context string
+
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Context == "" {
+		return i.Message
+	}
+	return i.Context + ": " + i.Message
+}
+
+// Validate checks invariants which should hold for any valid data, including
+// required fields, parsed dates, times, and weekdays, and the consistency of
+// schedule days with activity days.
+func Validate(pb *Data) []Issue {
+	var issues []Issue
+	if len(pb.GetFacilities()) == 0 {
+		issues = append(issues, Issue{-1, -1, -1, -1, "", "no facilities"})
+	}
+	for fi, f := range pb.GetFacilities() {
+		fctx := "facility " + strconv.Quote(f.GetName())
+		report := func(gi, si, ai int, ctx, format string, a ...any) {
+			issues = append(issues, Issue{fi, gi, si, ai, fctx + ctx, fmt.Sprintf(format, a...)})
+		}
+		if f.GetName() == "" {
+			report(-1, -1, -1, "", "missing name")
+		}
+		if f.GetSource().GetUrl() == "" {
+			report(-1, -1, -1, "", "missing source url")
+		}
+		if f.HasXLnglat() {
+			if ll := f.GetXLnglat(); ll.GetLat() < -90 || ll.GetLat() > 90 || ll.GetLng() < -180 || ll.GetLng() > 180 || (ll.GetLat() == 0 && ll.GetLng() == 0) {
+				report(-1, -1, -1, "", "coordinates out of range (%f, %f)", ll.GetLng(), ll.GetLat())
+			}
+		}
+		for gi, g := range f.GetScheduleGroups() {
+			gctx := " > group " + strconv.Quote(g.GetLabel())
+			for si, s := range g.GetSchedules() {
+				sctx := gctx + " > schedule " + strconv.Quote(s.GetCaption())
+				for _, x := range []struct {
+					name string
+					has  bool
+					d    int32
+				}{
+					{"from", s.HasXFrom(), s.GetXFrom()},
+					{"to", s.HasXTo(), s.GetXTo()},
+				} {
+					if x.has && x.d != 0 && !Date(x.d).IsValid() {
+						report(gi, si, -1, sctx, "invalid %s date %d", x.name, x.d)
+					}
+				}
+				if from, ok := Date(s.GetXFrom()).date(); ok {
+					if to, ok := Date(s.GetXTo()).date(); ok && to.Before(from) {
+						report(gi, si, -1, sctx, "date range ends before it starts")
+					}
+				}
+				if n := len(s.GetXDaydates()); n != 0 && n != len(s.GetDays()) {
+					report(gi, si, -1, sctx, "%d parsed day dates for %d days", n, len(s.GetDays()))
+				}
+				for _, d := range s.GetXDaydates() {
+					if d != 0 && !Date(d).IsValid() {
+						report(gi, si, -1, sctx, "invalid day date %d", d)
+					}
+				}
+				for ai, a := range s.GetActivities() {
+					actx := sctx + " > activity " + strconv.Quote(a.GetLabel())
+					if len(a.GetDays()) != len(s.GetDays()) {
+						report(gi, si, ai, actx, "%d activity days for %d schedule days", len(a.GetDays()), len(s.GetDays()))
+					}
+					for _, d := range a.GetDays() {
+						for _, t := range d.GetTimes() {
+							if t.HasXWkday() && (t.GetXWkday() < Weekday_SUNDAY || t.GetXWkday() > Weekday_SATURDAY) {
+								report(gi, si, ai, actx, "invalid weekday %d for %q", t.GetXWkday(), t.GetLabel())
+							}
+							if t.HasXStart() && !ClockTime(t.GetXStart()).IsValid() {
+								report(gi, si, ai, actx, "invalid start time %d for %q", t.GetXStart(), t.GetLabel())
+							}
+							if t.HasXEnd() && !ClockTime(t.GetXEnd()).IsValid() {
+								report(gi, si, ai, actx, "invalid end time %d for %q", t.GetXEnd(), t.GetLabel())
+							}
+							if _, r, ok := t.AsXParsed(); ok && !r.IsValid() {
+								report(gi, si, ai, actx, "invalid time range %s - %s for %q", r.Start, r.End, t.GetLabel())
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return issues
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// attributionText formats the attribution in pb as a plain text file, or
+// returns an empty string if there isn't any.
+func attributionText(pb *schema.Data) string {
+	if len(pb.GetAttribution()) == 0 {
+		return ""
+	}
+	return strings.Join(pb.GetAttribution(), "\n\n") + "\n"
+}
+
+// writeAttribution writes ATTRIBUTION.txt into each of dirs, skipping any
+// duplicates, and returns the names of the written files.
+func writeAttribution(pb *schema.Data, dirs []string) ([]string, error) {
+	text := attributionText(pb)
+	if text == "" {
+		return nil, nil
+	}
+	for i, dir := range dirs {
+		dirs[i] = filepath.Clean(dir)
+	}
+	slices.Sort(dirs)
+	var written []string
+	for _, dir := range slices.Compact(dirs) {
+		name := filepath.Join(dir, "ATTRIBUTION.txt")
+		if err := os.WriteFile(name, []byte(text), 0644); err != nil {
+			return written, err
+		}
+		written = append(written, name)
+	}
+	return written, nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestWriteAttribution(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "site"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	if written, err := writeAttribution(schema.Data_builder{}.Build(), []string{dir}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(written) != 0 {
+		t.Errorf("unexpected written files %q", written)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ATTRIBUTION.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected no attribution file to be written without attribution")
+	}
+
+	pb := schema.Data_builder{Attribution: []string{"A", "B"}}.Build()
+	if written, err := writeAttribution(pb, []string{dir, filepath.Join(dir, "site"), dir + "/"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(written) != 2 {
+		t.Errorf("unexpected written files %q", written)
+	}
+	for _, name := range []string{"ATTRIBUTION.txt", "site/ATTRIBUTION.txt"} {
+		if buf, err := os.ReadFile(filepath.Join(dir, name)); err != nil {
+			t.Errorf("read %s: %v", name, err)
+		} else if string(buf) != "A\n\nB\n" {
+			t.Errorf("%s: unexpected contents %q", name, buf)
+		}
+	}
+}
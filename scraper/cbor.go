@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// jsonToCBOR converts a json document into the equivalent CBOR (RFC 8949),
+// with map keys sorted using the core deterministic encoding rules.
+func jsonToCBOR(buf []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := appendCBOR(&b, v); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func appendCBOR(b *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		b.WriteByte(0xf6)
+	case bool:
+		if v {
+			b.WriteByte(0xf5)
+		} else {
+			b.WriteByte(0xf4)
+		}
+	case json.Number:
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			if n >= 0 {
+				cborHead(b, 0, uint64(n))
+			} else {
+				cborHead(b, 1, uint64(-1-n))
+			}
+		} else if f, err := v.Float64(); err == nil {
+			if f32 := float32(f); float64(f32) == f {
+				b.WriteByte(0xfa)
+				b.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f32)))
+			} else {
+				b.WriteByte(0xfb)
+				b.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+			}
+		} else {
+			return fmt.Errorf("invalid number %q", v)
+		}
+	case string:
+		cborHead(b, 3, uint64(len(v)))
+		b.WriteString(v)
+	case []any:
+		cborHead(b, 4, uint64(len(v)))
+		for _, x := range v {
+			if err := appendCBOR(b, x); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.SortFunc(keys, func(a, b string) int {
+			return cmp.Or(cmp.Compare(len(a), len(b)), cmp.Compare(a, b))
+		})
+		cborHead(b, 5, uint64(len(v)))
+		for _, k := range keys {
+			cborHead(b, 3, uint64(len(k)))
+			b.WriteString(k)
+			if err := appendCBOR(b, v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type %T", v)
+	}
+	return nil
+}
+
+// cborHead writes the initial byte and argument for a data item.
+func cborHead(b *bytes.Buffer, major byte, n uint64) {
+	switch {
+	case n < 24:
+		b.WriteByte(major<<5 | byte(n))
+	case n <= math.MaxUint8:
+		b.WriteByte(major<<5 | 24)
+		b.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		b.WriteByte(major<<5 | 25)
+		b.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n <= math.MaxUint32:
+		b.WriteByte(major<<5 | 26)
+		b.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		b.WriteByte(major<<5 | 27)
+		b.Write(binary.BigEndian.AppendUint64(nil, n))
+	}
+}
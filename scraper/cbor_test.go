@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestJSONToCBOR(t *testing.T) {
+	for _, tc := range []struct {
+		JSON, CBOR string
+	}{
+		// from RFC 8949 appendix A
+		{`0`, "00"},
+		{`23`, "17"},
+		{`24`, "1818"},
+		{`1000`, "1903e8"},
+		{`100000`, "1a000186a0"},
+		{`1000000000000`, "1b000000e8d4a51000"},
+		{`-1`, "20"},
+		{`-1000`, "3903e7"},
+		{`1.1`, "fb3ff199999999999a"},
+		{`1.5`, "fa3fc00000"},
+		{`false`, "f4"},
+		{`true`, "f5"},
+		{`null`, "f6"},
+		{`""`, "60"},
+		{`"IETF"`, "6449455446"},
+		{`"ü"`, "62c3bc"},
+		{`[]`, "80"},
+		{`[1,[2,3],[4,5]]`, "8301820203820405"},
+		{`{}`, "a0"},
+		{`{"b":[2,3],"a":1}`, "a26161016162820203"},
+		{`{"aa":1,"b":2}`, "a261620262616101"},
+	} {
+		buf, err := jsonToCBOR([]byte(tc.JSON))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.JSON, err)
+		} else if act := hex.EncodeToString(buf); act != tc.CBOR {
+			t.Errorf("%s: expected %s, got %s", tc.JSON, tc.CBOR, act)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"encoding/json"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// compactData is a minimal version of schema.Data with only the parsed and
+// normalized fields, for bundling into apps.
+type compactData struct {
+	Attribution []string          `json:"a,omitempty"`
+	Facilities  []compactFacility `json:"f"`
+}
+
+type compactFacility struct {
+	Name    string         `json:"n"`
+	Address string         `json:"d,omitempty"`
+	URL     string         `json:"u,omitempty"`
+	LngLat  *[2]float32    `json:"p,omitempty"`
+	Groups  []compactGroup `json:"g,omitempty"`
+}
+
+type compactGroup struct {
+	Title     string            `json:"n"`
+	Schedules []compactSchedule `json:"s"`
+}
+
+type compactSchedule struct {
+	Name       string            `json:"n"`
+	From       string            `json:"f,omitempty"` // YYYY-MM-DD
+	To         string            `json:"t,omitempty"` // YYYY-MM-DD
+	Holiday    string            `json:"h,omitempty"`
+	Activities []compactActivity `json:"a"`
+}
+
+type compactActivity struct {
+	Name        string     `json:"n"`
+	Venue       string     `json:"v,omitempty"`
+	Reservation *bool      `json:"r,omitempty"`
+	Times       [][3]int32 `json:"t"` // weekday (sunday = 0), start, end (minutes from 00:00)
+}
+
+// exportCompactJSON converts pb into gzipped json with short keys, containing
+// only facilities, schedules, and activities with parsed time ranges, and
+// without any raw text or html.
+func exportCompactJSON(pb *schema.Data) ([]byte, error) {
+	data := compactData{
+		Attribution: pb.GetAttribution(),
+		Facilities:  []compactFacility{},
+	}
+	for _, f := range pb.GetFacilities() {
+		cf := compactFacility{
+			Name:    f.GetName(),
+			Address: f.GetAddress(),
+			URL:     f.GetSource().GetUrl(),
+		}
+		if f.HasXLnglat() {
+			cf.LngLat = &[2]float32{f.GetXLnglat().GetLng(), f.GetXLnglat().GetLat()}
+		}
+		for _, g := range f.GetScheduleGroups() {
+			cg := compactGroup{
+				Title: cmp.Or(g.GetXTitle(), g.GetLabel()),
+			}
+			for _, s := range g.GetSchedules() {
+				cs := compactSchedule{
+					Name:    cmp.Or(s.GetXName(), s.GetCaption()),
+					From:    ndjsonDate(s.GetXFrom()),
+					To:      ndjsonDate(s.GetXTo()),
+					Holiday: s.GetXHoliday(),
+				}
+				for _, a := range s.GetActivities() {
+					ca := compactActivity{
+						Name:  cmp.Or(a.GetXName(), a.GetLabel()),
+						Venue: a.GetXVenue(),
+					}
+					if a.HasXResv() {
+						ca.Reservation = ptrTo(a.GetXResv())
+					}
+					for _, d := range a.GetDays() {
+						for _, t := range d.GetTimes() {
+							if wkday, r, ok := t.AsXParsed(); ok && r.IsValid() {
+								ca.Times = append(ca.Times, [3]int32{int32(wkday), int32(r.Start), int32(r.End)})
+							}
+						}
+					}
+					if len(ca.Times) != 0 {
+						cs.Activities = append(cs.Activities, ca)
+					}
+				}
+				if len(cs.Activities) != 0 {
+					cg.Schedules = append(cg.Schedules, cs)
+				}
+			}
+			if len(cg.Schedules) != 0 {
+				cf.Groups = append(cf.Groups, cg)
+			}
+		}
+		data.Facilities = append(data.Facilities, cf)
+	}
+
+	var b bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&b, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(zw)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
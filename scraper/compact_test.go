@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestExportCompactJSON(t *testing.T) {
+	pb := schema.Data_builder{
+		Attribution: []string{"Test"},
+		Facilities: []*schema.Facility{
+			schema.Facility_builder{
+				Name:              "A Pool",
+				Address:           "1 A Road",
+				NotificationsHtml: "<p>Closed</p>",
+				Source:            schema.Source_builder{Url: "https://ottawa.ca/en/a"}.Build(),
+				XLnglat:           schema.LngLat_builder{Lng: -75.5, Lat: 45.25}.Build(),
+				ScheduleGroups: []*schema.ScheduleGroup{schema.ScheduleGroup_builder{
+					Label:  "Drop-in swimming schedules",
+					XTitle: "Swimming",
+					Schedules: []*schema.Schedule{schema.Schedule_builder{
+						Caption: "Swimming - fall",
+						XName:   "fall",
+						XFrom:   ptrTo(int32(schema.MakeDate(2025, time.September, 1, -1))),
+						Days:    []string{"Monday"},
+						Activities: []*schema.Schedule_Activity{
+							schema.Schedule_Activity_builder{
+								Label:  "Lane swim - 25m pool",
+								XName:  "lane swim",
+								XVenue: "25m pool",
+								XResv:  ptrTo(true),
+								Days: []*schema.Schedule_ActivityDay{schema.Schedule_ActivityDay_builder{
+									Times: []*schema.TimeRange{
+										schema.TimeRange_builder{Label: "7 - 8 am", XWkday: ptrTo(schema.Weekday_MONDAY), XStart: ptrTo(int32(7 * 60)), XEnd: ptrTo(int32(8 * 60))}.Build(),
+										schema.TimeRange_builder{Label: "unparsed"}.Build(),
+									},
+								}.Build()},
+							}.Build(),
+							schema.Schedule_Activity_builder{
+								Label: "Unparsed",
+								Days: []*schema.Schedule_ActivityDay{schema.Schedule_ActivityDay_builder{
+									Times: []*schema.TimeRange{schema.TimeRange_builder{Label: "unparsed"}.Build()},
+								}.Build()},
+							}.Build(),
+						},
+					}.Build()},
+				}.Build()},
+			}.Build(),
+			schema.Facility_builder{
+				Name: "B Arena",
+			}.Build(),
+		},
+	}.Build()
+	buf, err := exportCompactJSON(pb)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	exp := `{"a":["Test"],"f":[{"n":"A Pool","d":"1 A Road","u":"https://ottawa.ca/en/a","p":[-75.5,45.25],"g":[{"n":"Swimming","s":[{"n":"fall","f":"2025-09-01","a":[{"n":"lane swim","v":"25m pool","r":true,"t":[[1,420,480]]}]}]}]},{"n":"B Arena"}]}` + "\n"
+	if string(out) != exp {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// postalCodeRe matches a Canadian postal code.
+var postalCodeRe = regexp.MustCompile(`(?i)\b([a-z][0-9][a-z])\s*([0-9][a-z][0-9])\b`)
+
+// facilityLinkKey returns a key identifying the address of a facility
+// independently of the language it was written in (i.e., the civic number and
+// postal code), or an empty string if one could not be determined.
+func facilityLinkKey(address string) string {
+	m := postalCodeRe.FindStringSubmatch(address)
+	if m == nil {
+		return ""
+	}
+	civic := strings.TrimSpace(address)
+	if i := strings.IndexFunc(civic, func(r rune) bool { return r < '0' || r > '9' }); i != -1 {
+		civic = civic[:i]
+	}
+	if civic == "" {
+		return ""
+	}
+	return strings.ToUpper(m[1]+m[2]) + " " + civic
+}
+
+// timeSlot is a parsed weekday and time range.
+type timeSlot struct {
+	Weekday time.Weekday
+	Range   schema.ClockRange
+}
+
+func (s timeSlot) String() string {
+	return s.Weekday.String() + " " + s.Range.String()
+}
+
+func (s timeSlot) Compare(o timeSlot) int {
+	return cmp.Or(
+		cmp.Compare(s.Weekday, o.Weekday),
+		cmp.Compare(s.Range.Start, o.Range.Start),
+		cmp.Compare(s.Range.End, o.Range.End),
+	)
+}
+
+// facilityTimeSlots counts the successfully parsed time slots in all schedules
+// of a facility.
+func facilityTimeSlots(f *schema.Facility) map[timeSlot]int {
+	slots := map[timeSlot]int{}
+	for _, group := range f.GetScheduleGroups() {
+		for _, schedule := range group.GetSchedules() {
+			for _, activity := range schedule.GetActivities() {
+				for _, day := range activity.GetDays() {
+					for _, tr := range day.GetTimes() {
+						if w, r, ok := tr.AsXParsed(); ok {
+							slots[timeSlot{w, r}]++
+						}
+					}
+				}
+			}
+		}
+	}
+	return slots
+}
+
+// compareTimeSlots returns the parsed time slots which are only in a or only in
+// b.
+func compareTimeSlots(a, b *schema.Facility) (onlyA, onlyB []timeSlot) {
+	x, y := facilityTimeSlots(a), facilityTimeSlots(b)
+	for s, n := range x {
+		for range n - y[s] {
+			onlyA = append(onlyA, s)
+		}
+	}
+	for s, n := range y {
+		for range n - x[s] {
+			onlyB = append(onlyB, s)
+		}
+	}
+	return onlyA, onlyB
+}
+
+// linkFacilities links the facilities in data with the same facilities in
+// other (scraped from the other language version of the website) by address. It
+// returns the linked facility from other for each facility in data, or nil if
+// it could not be linked unambiguously.
+func linkFacilities(data, other *schema.Data) []*schema.Facility {
+	var (
+		keys   = map[string]int{}
+		others = map[string][]*schema.Facility{}
+		links  = make([]*schema.Facility, len(data.GetFacilities()))
+	)
+	for _, f := range data.GetFacilities() {
+		if k := facilityLinkKey(f.GetAddress()); k != "" {
+			keys[k]++
+		}
+	}
+	for _, f := range other.GetFacilities() {
+		if k := facilityLinkKey(f.GetAddress()); k != "" {
+			others[k] = append(others[k], f)
+		}
+	}
+	for i, f := range data.GetFacilities() {
+		k := facilityLinkKey(f.GetAddress())
+		if k == "" || len(others[k]) == 0 {
+			continue // not linkable
+		}
+		if keys[k] != 1 || len(others[k]) != 1 {
+			continue // ambiguous (multiple facilities at the same address)
+		}
+		links[i] = others[k][0]
+	}
+	return links
+}
+
+// crossCheck links the facilities in data with the same facilities in other
+// (see [linkFacilities]), and adds errors to the facilities in data where the
+// parsed schedule times disagree, since it usually means only one of the pages
+// was updated (so the times on one of them are wrong). It returns the number of
+// linked facilities.
+func crossCheck(data, other *schema.Data) (linked int) {
+	for i, o := range linkFacilities(data, other) {
+		if o == nil {
+			continue
+		}
+		f := data.GetFacilities()[i]
+		linked++
+
+		onlyA, onlyB := compareTimeSlots(f, o)
+		if len(onlyA) != 0 {
+			f.SetXDiagnostics(append(f.GetXDiagnostics(), schema.DiagError(schema.Diagnostic_CHECK, "", "crosscheck: %d time slots not on other-language page %q (%s)", len(onlyA), o.GetSource().GetUrl(), formatTimeSlots(onlyA, 3))))
+		}
+		if len(onlyB) != 0 {
+			f.SetXDiagnostics(append(f.GetXDiagnostics(), schema.DiagError(schema.Diagnostic_CHECK, "", "crosscheck: %d time slots only on other-language page %q (%s)", len(onlyB), o.GetSource().GetUrl(), formatTimeSlots(onlyB, 3))))
+		}
+	}
+	return linked
+}
+
+// mergeLanguages cross-checks data and other (scraped from the other language
+// version of the website) against each other, then combines them (see
+// [schema.Merge]) with the translation of each linked facility set to the id of
+// the other one. Unlike [mergeData], facilities are not deduplicated since the
+// linked ones are translations rather than duplicates. It returns the merged
+// data and the number of linked facilities.
+func mergeLanguages(data, other *schema.Data) (*schema.Data, int) {
+	links := linkFacilities(data, other)
+	linked := crossCheck(data, other)
+	crossCheck(other, data)
+
+	merged := schema.Merge(data, other)
+	fs := merged.GetFacilities()
+	for i, o := range links {
+		if o == nil {
+			continue
+		}
+		a, b := fs[i], fs[len(data.GetFacilities())+slices.Index(other.GetFacilities(), o)]
+		a.SetXTranslation(b.GetXId())
+		b.SetXTranslation(a.GetXId())
+	}
+	return merged, linked
+}
+
+// formatTimeSlots sorts and formats up to n time slots.
+func formatTimeSlots(slots []timeSlot, n int) string {
+	slices.SortFunc(slots, timeSlot.Compare)
+	var b strings.Builder
+	for i, s := range slots {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		if i == n {
+			b.WriteString("...")
+			break
+		}
+		b.WriteString(s.String())
+	}
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestFacilityLinkKey(t *testing.T) {
+	for _, tc := range []struct {
+		A, B string
+	}{
+		{"2040 Ogilvie Road\nOttawa, ON K1J 7N8", "K1J7N8 2040"},
+		{"2040, chemin Ogilvie\nOttawa (Ontario) K1J7N8", "K1J7N8 2040"},
+		{"2040 Ogilvie Road", ""},
+		{"Ogilvie Road K1J 7N8", ""},
+		{"", ""},
+	} {
+		if c := facilityLinkKey(tc.A); c != tc.B {
+			t.Errorf("key %q: expected %q, got %q", tc.A, tc.B, c)
+		}
+	}
+}
+
+func TestCrossCheck(t *testing.T) {
+	facility := func(name, address string, slots ...*schema.TimeRange) *schema.Facility {
+		var days [][]*schema.TimeRange
+		for _, x := range slots {
+			days = append(days, []*schema.TimeRange{x})
+		}
+		return testFacility(name, address, testSchedule("", nil, testActivity("", days...)))
+	}
+	en := schema.Data_builder{
+		Facilities: []*schema.Facility{
+			facility("a", "1 A Road K1A 0A1", testSlot(1, 60, 120), testSlot(2, 60, 120)),
+			facility("b", "2 B Road K1A 0A2", testSlot(1, 60, 120)),
+			facility("c", "3 C Road K1A 0A3", testSlot(1, 60, 120)),
+			facility("d", "4 D Road K1A 0A4", testSlot(1, 60, 120)),
+		},
+	}.Build()
+	fr := schema.Data_builder{
+		Facilities: []*schema.Facility{
+			facility("a", "1, chemin A K1A 0A1", testSlot(2, 60, 120), testSlot(1, 60, 120)),
+			facility("b", "2, chemin B K1A 0A2", testSlot(1, 60, 150)),
+			facility("c", "3, chemin C K1A 0A3", testSlot(1, 60, 120), testSlot(1, 60, 120)),
+		},
+	}.Build()
+	if n := crossCheck(en, fr); n != 3 {
+		t.Errorf("expected 3 linked facilities, got %d", n)
+	}
+	for i, exp := range [][]string{
+		nil,
+		{"Monday 1:00 - 2:00am", "Monday 1:00 - 2:30am"},
+		{"only on other-language page"},
+		nil,
+	} {
+		f := en.GetFacilities()[i]
+		var msgs []string
+		for _, d := range f.GetXDiagnostics() {
+			if d.GetSeverity() != schema.Diagnostic_ERROR || d.GetStage() != schema.Diagnostic_CHECK {
+				t.Errorf("facility %q: expected check error, got %v", f.GetName(), d)
+			}
+			msgs = append(msgs, d.GetMessage())
+		}
+		if len(exp) == 0 && len(msgs) != 0 {
+			t.Errorf("facility %q: unexpected diagnostics %q", f.GetName(), msgs)
+		}
+		for _, x := range exp {
+			if !strings.Contains(strings.Join(msgs, "\n"), x) {
+				t.Errorf("facility %q: expected diagnostic containing %q, got %q", f.GetName(), x, msgs)
+			}
+		}
+	}
+}
+
+func TestMergeLanguages(t *testing.T) {
+	facility := func(id, lang, address string, slots ...*schema.TimeRange) *schema.Facility {
+		var days [][]*schema.TimeRange
+		for _, x := range slots {
+			days = append(days, []*schema.TimeRange{x})
+		}
+		f := testFacility(id, address, testSchedule("", nil, testActivity("", days...)))
+		f.SetXId(id)
+		f.SetName(strings.ToUpper(id[:1])) // same name and location so translations look like duplicates
+		f.SetXLnglat(schema.LngLat_builder{Lng: -75, Lat: 45 + float32(id[0]-'a')}.Build())
+		f.GetSource().SetXLang(lang)
+		return f
+	}
+	en := schema.Data_builder{
+		Attribution: []string{"en"},
+		Facilities: []*schema.Facility{
+			facility("a-en", "en", "1 A Road K1A 0A1", testSlot(1, 60, 120)),
+			facility("b-en", "en", "2 B Road K1A 0A2", testSlot(1, 60, 120)),
+			facility("c-en", "en", "3 C Road K1A 0A3", testSlot(1, 60, 120)),
+		},
+	}.Build()
+	fr := schema.Data_builder{
+		Attribution: []string{"fr"},
+		Facilities: []*schema.Facility{
+			facility("b-fr", "fr", "2, chemin B K1A 0A2", testSlot(1, 60, 150)),
+			facility("a-fr", "fr", "1, chemin A K1A 0A1", testSlot(1, 60, 120)),
+			facility("d-fr", "fr", "4, chemin D K1A 0A4", testSlot(1, 60, 120)),
+		},
+	}.Build()
+	pb, linked := mergeLanguages(en, fr)
+	if linked != 2 {
+		t.Errorf("expected 2 linked facilities, got %d", linked)
+	}
+	if act := pb.GetAttribution(); len(act) != 2 {
+		t.Errorf("expected attribution from both languages, got %q", act)
+	}
+	translations := map[string]string{
+		"a-en": "a-fr",
+		"b-en": "b-fr",
+		"c-en": "",
+		"b-fr": "b-en",
+		"a-fr": "a-en",
+		"d-fr": "",
+	}
+	if n := len(pb.GetFacilities()); n != len(translations) {
+		t.Errorf("expected %d facilities, got %d", len(translations), n)
+	}
+	for _, f := range pb.GetFacilities() {
+		if act, exp := f.GetXTranslation(), translations[f.GetXId()]; act != exp {
+			t.Errorf("facility %q: expected translation %q, got %q", f.GetXId(), exp, act)
+		}
+		if exp := strings.HasPrefix(f.GetXId(), "b-"); exp != (len(f.GetXDiagnostics()) != 0) {
+			t.Errorf("facility %q: unexpected diagnostics %v", f.GetXId(), f.GetXDiagnostics())
+		}
+	}
+	if n := len(dedupeFacilities(pb.GetFacilities())); n != len(translations) {
+		t.Errorf("expected translations not to be deduplicated, got %d facilities", n)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// datapackageSchemas are table schemas for the csv exports, by format.
+var datapackageSchemas = map[string]map[string]any{
+	"pivot": datapackageTable("\n",
+		datapackageField("Facility", "string", "facility name"),
+		datapackageField("Group", "string", "schedule group title"),
+		datapackageField("Schedule", "string", "schedule caption"),
+		datapackageField("From", "date", "first date the schedule is in effect, if known"),
+		datapackageField("To", "date", "last date the schedule is in effect, if known"),
+		datapackageField("Activity", "string", "activity label"),
+		datapackageField("Monday", "string", "comma-separated time ranges on the weekday"),
+		datapackageField("Tuesday", "string", "comma-separated time ranges on the weekday"),
+		datapackageField("Wednesday", "string", "comma-separated time ranges on the weekday"),
+		datapackageField("Thursday", "string", "comma-separated time ranges on the weekday"),
+		datapackageField("Friday", "string", "comma-separated time ranges on the weekday"),
+		datapackageField("Saturday", "string", "comma-separated time ranges on the weekday"),
+		datapackageField("Sunday", "string", "comma-separated time ranges on the weekday"),
+		datapackageField("Other", "string", "comma-separated time ranges without a parsed weekday, prefixed by the day"),
+	),
+	"gcal": datapackageTable("\r\n",
+		datapackageField("Subject", "string", "activity label"),
+		datapackageField("Start Date", "date", "start date", "format", "%m/%d/%Y"),
+		datapackageField("Start Time", "time", "start time", "format", "%I:%M %p"),
+		datapackageField("End Date", "date", "end date", "format", "%m/%d/%Y"),
+		datapackageField("End Time", "time", "end time", "format", "%I:%M %p"),
+		datapackageField("All Day Event", "boolean", "always false", "trueValues", []string{"True"}, "falseValues", []string{"False"}),
+		datapackageField("Description", "string", "facility, schedule, holiday notes, and source url"),
+		datapackageField("Location", "string", "facility name and address"),
+		datapackageField("Private", "boolean", "always false", "trueValues", []string{"True"}, "falseValues", []string{"False"}),
+	),
+}
+
+func datapackageTable(lineTerminator string, fields ...map[string]any) map[string]any {
+	return map[string]any{
+		"dialect": map[string]any{
+			"delimiter":      ",",
+			"lineTerminator": lineTerminator,
+			"header":         true,
+		},
+		"schema": map[string]any{
+			"fields":        fields,
+			"missingValues": []string{""},
+		},
+	}
+}
+
+func datapackageField(name, typ, desc string, kv ...any) map[string]any {
+	f := map[string]any{
+		"name":        name,
+		"type":        typ,
+		"description": desc,
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		f[kv[i].(string)] = kv[i+1]
+	}
+	return f
+}
+
+var datapackageInvalidName = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// exportDatapackage generates a Frictionless Data package descriptor for the
+// exported files, with paths relative to base, and table schemas for the csv
+// exports. The attribution is included as the package sources.
+func exportDatapackage(base string, pb *schema.Data, files []exportFile) ([]byte, error) {
+	pkg := map[string]any{
+		"profile":  "data-package",
+		"name":     "ottrec",
+		"title":    "Ottawa Recreation Schedules",
+		"homepage": "https://github.com/pgaskin/ottrec",
+	}
+	if t := dataUpdated(pb); !t.IsZero() {
+		pkg["created"] = t.UTC().Format(time.RFC3339)
+	}
+	var sources []map[string]any
+	for _, x := range pb.GetAttribution() {
+		src := map[string]any{"title": x}
+		if i := strings.LastIndex(x, "https://"); i != -1 && !strings.ContainsAny(x[i:], " \t\n") {
+			src["path"] = x[i:]
+		}
+		sources = append(sources, src)
+	}
+	if sources != nil {
+		pkg["sources"] = sources
+	}
+	resources := []map[string]any{}
+	names := map[string]bool{}
+	for _, f := range files {
+		e, err := manifestFile(base, f.Name, f.Format)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.Trim(datapackageInvalidName.ReplaceAllString(strings.ToLower(f.Format), "-"), "-")
+		if names[name] {
+			name = strings.Trim(datapackageInvalidName.ReplaceAllString(strings.ToLower(e.Name), "-"), "-")
+		}
+		names[name] = true
+		r := map[string]any{
+			"name":      name,
+			"path":      e.Name,
+			"mediatype": uploadContentType(f.Name),
+			"bytes":     e.Size,
+			"hash":      "sha256:" + e.SHA256,
+		}
+		if ext := strings.TrimPrefix(filepath.Ext(f.Name), "."); ext != "" {
+			r["format"] = strings.ToLower(ext)
+		}
+		if t, ok := datapackageSchemas[f.Format]; ok {
+			r["profile"] = "tabular-data-resource"
+			r["format"] = "csv"
+			r["encoding"] = "utf-8"
+			for k, v := range t {
+				r[k] = v
+			}
+		}
+		resources = append(resources, r)
+	}
+	pkg["resources"] = resources
+	buf, err := json.MarshalIndent(pkg, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, '\n'), nil
+}
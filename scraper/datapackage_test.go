@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestExportDatapackage(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"data.json":  "{}",
+		"pivot.csv":  "Facility\n",
+		"other.json": "{}",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pb := schema.Data_builder{
+		Attribution: []string{"Compiled data © Someone. https://example.com/a", "Other attribution"},
+	}.Build()
+	buf, err := exportDatapackage(dir, pb, []exportFile{
+		{filepath.Join(dir, "data.json"), "json"},
+		{filepath.Join(dir, "pivot.csv"), "pivot"},
+		{filepath.Join(dir, "other.json"), "json"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var pkg struct {
+		Name    string
+		Sources []struct {
+			Title string
+			Path  string
+		}
+		Resources []struct {
+			Name    string
+			Path    string
+			Profile string
+			Format  string
+			Bytes   int64
+			Hash    string
+			Schema  struct {
+				Fields []struct {
+					Name string
+					Type string
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(buf, &pkg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if pkg.Name != "ottrec" || len(pkg.Sources) != 2 || pkg.Sources[0].Path != "https://example.com/a" || pkg.Sources[1].Path != "" {
+		t.Errorf("unexpected package:\n%s", buf)
+	}
+	if len(pkg.Resources) != 3 {
+		t.Fatalf("unexpected resources:\n%s", buf)
+	}
+	if r := pkg.Resources[0]; r.Name != "json" || r.Path != "data.json" || r.Format != "json" || r.Bytes != 2 || r.Hash != "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a" || r.Profile != "" {
+		t.Errorf("unexpected json resource %+v", r)
+	}
+	if r := pkg.Resources[1]; r.Name != "pivot" || r.Profile != "tabular-data-resource" || r.Format != "csv" || len(r.Schema.Fields) != 14 || r.Schema.Fields[3].Name != "From" || r.Schema.Fields[3].Type != "date" {
+		t.Errorf("unexpected pivot resource %+v", r)
+	}
+	if r := pkg.Resources[2]; r.Name != "other.json" {
+		t.Errorf("expected duplicate resource name to be made unique, got %q", r.Name)
+	}
+}
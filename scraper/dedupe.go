@@ -0,0 +1,183 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/pgaskin/ottrec/schema"
+	"google.golang.org/protobuf/proto"
+)
+
+// dedupeFacilities merges facilities which were listed multiple times (i.e.,
+// ones with the same page, or with matching addresses or coordinates and
+// near-identical names), recording the names of the merged facilities as
+// aliases. Duplicates are grouped transitively before merging so the result
+// doesn't depend on the order of the facilities. The facility with the most
+// schedule groups is kept (or the first one if tied), and the schedule groups,
+// diagnostics, and other missing information from the others are merged into
+// it.
+func dedupeFacilities(facilities []*schema.Facility) []*schema.Facility {
+	parent := make([]int, len(facilities))
+	for i := range parent {
+		parent[i] = i
+	}
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i], i = parent[parent[i]], parent[i]
+		}
+		return i
+	}
+	for i, a := range facilities {
+		for j, b := range facilities[i+1:] {
+			if isDuplicateFacility(a, b) {
+				parent[find(i+1+j)] = find(i)
+			}
+		}
+	}
+
+	var (
+		roots  []int
+		groups = map[int][]*schema.Facility{}
+	)
+	for i, f := range facilities {
+		r := find(i)
+		if _, ok := groups[r]; !ok {
+			roots = append(roots, r)
+		}
+		groups[r] = append(groups[r], f)
+	}
+
+	out := make([]*schema.Facility, 0, len(roots))
+	for _, r := range roots {
+		group := groups[r]
+		a := slices.MaxFunc(group, func(a, b *schema.Facility) int {
+			return cmp.Compare(len(a.GetScheduleGroups()), len(b.GetScheduleGroups()))
+		})
+		for _, b := range group {
+			if b != a {
+				mergeFacility(a, b)
+			}
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// dedupeSchedules removes schedules which are exact duplicates of an earlier
+// one in the same group, returning an error diagnostic for each schedule which
+// has the same caption as an earlier one but different contents (both are
+// kept since we can't know which one is correct).
+func dedupeSchedules(schedules []*schema.Schedule) ([]*schema.Schedule, []*schema.Diagnostic) {
+	var (
+		out   []*schema.Schedule
+		diags []*schema.Diagnostic
+	)
+	for _, s := range schedules {
+		if slices.ContainsFunc(out, func(o *schema.Schedule) bool {
+			return proto.Equal(o, s)
+		}) {
+			continue
+		}
+		if slices.ContainsFunc(out, func(o *schema.Schedule) bool {
+			return o.GetCaption() == s.GetCaption()
+		}) {
+			diags = append(diags, schema.DiagError(schema.Diagnostic_PARSE, fmt.Sprintf("schedule %q", s.GetCaption()), "conflicting duplicate schedule"))
+		}
+		out = append(out, s)
+	}
+	return out, diags
+}
+
+// isDuplicateFacility checks if a and b refer to the same facility.
+func isDuplicateFacility(a, b *schema.Facility) bool {
+	if a.GetSource().GetUrl() != "" && a.GetSource().GetUrl() == b.GetSource().GetUrl() {
+		return true
+	}
+	if x, y := a.GetSource().GetXLang(), b.GetSource().GetXLang(); x != "" && y != "" && x != y {
+		return false // translations (see mergeLanguages)
+	}
+	sameAddress := normalizeFuzzy(a.GetAddress()) != "" && normalizeFuzzy(a.GetAddress()) == normalizeFuzzy(b.GetAddress())
+	if !sameAddress && a.HasXLnglat() && b.HasXLnglat() {
+		sameAddress = a.GetXLnglat().DistanceTo(b.GetXLnglat()) < 50
+	}
+	if !sameAddress {
+		return false
+	}
+	return similarity(normalizeFuzzy(a.GetName()), normalizeFuzzy(b.GetName())) >= 0.9
+}
+
+// mergeFacility merges b into a.
+func mergeFacility(a, b *schema.Facility) {
+	aliases := a.GetXAliases()
+	for _, x := range append([]string{b.GetName()}, b.GetXAliases()...) {
+		if x != a.GetName() && !slices.Contains(aliases, x) {
+			aliases = append(aliases, x)
+		}
+	}
+	a.SetXAliases(aliases)
+
+	if a.GetSource().GetUrl() != b.GetSource().GetUrl() {
+		groups := a.GetScheduleGroups()
+		for _, g := range b.GetScheduleGroups() {
+			if !slices.ContainsFunc(groups, func(x *schema.ScheduleGroup) bool {
+				return x.GetLabel() == g.GetLabel()
+			}) {
+				groups = append(groups, g)
+			}
+		}
+		a.SetScheduleGroups(groups)
+	}
+
+	a.SetXDiagnostics(schema.AppendDiagnostics(a.GetXDiagnostics(), b.GetXDiagnostics()...))
+
+	if !a.HasXLnglat() && b.HasXLnglat() {
+		a.SetXLnglat(b.GetXLnglat())
+	}
+	if a.GetDescription() == "" {
+		a.SetDescription(b.GetDescription())
+	}
+	if a.GetNotificationsHtml() == "" {
+		a.SetNotificationsHtml(b.GetNotificationsHtml())
+		a.SetXNotificationsHtml(b.GetXNotificationsHtml())
+	}
+	if a.GetSpecialHoursHtml() == "" {
+		a.SetSpecialHoursHtml(b.GetSpecialHoursHtml())
+		a.SetXSpecialHoursHtml(b.GetXSpecialHoursHtml())
+	}
+}
+
+// normalizeFuzzy normalizes s for fuzzy comparisons, keeping only lowercase
+// letters and digits separated by single spaces.
+func normalizeFuzzy(s string) string {
+	return strings.Join(strings.FieldsFunc(normalizeText(s, false, true), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// similarity returns the normalized levenshtein similarity of a and b, from 0
+// (completely different) to 1 (identical).
+func similarity(a, b string) float64 {
+	x, y := []rune(a), []rune(b)
+	if len(x) == 0 && len(y) == 0 {
+		return 1
+	}
+	prev, cur := make([]int, len(y)+1), make([]int, len(y)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range x {
+		cur[0] = i + 1
+		for j := range y {
+			cost := 1
+			if x[i] == y[j] {
+				cost = 0
+			}
+			cur[j+1] = min(prev[j+1]+1, cur[j]+1, prev[j]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(y)])/float64(max(len(x), len(y)))
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestDedupeFacilities(t *testing.T) {
+	facility := func(name, address, url string, groups ...string) *schema.Facility {
+		var f schema.Facility_builder
+		f.Name = name
+		f.Address = address
+		f.Source = schema.Source_builder{Url: url}.Build()
+		for _, g := range groups {
+			f.ScheduleGroups = append(f.ScheduleGroups, schema.ScheduleGroup_builder{Label: g}.Build())
+		}
+		return f.Build()
+	}
+	out := dedupeFacilities([]*schema.Facility{
+		facility("Bob MacQuarrie Recreation Complex - Orléans", "1490 Youville Drive", "https://example.com/a"),
+		facility("Jack Purcell Community Centre", "320 Jack Purcell Lane", "https://example.com/b", "Swim"),
+		facility("Bob MacQuarrie Recreation Complex-Orléans", "1490 Youville Drive", "https://example.com/c", "Swim", "Skate"),
+		facility("Jack Purcell Park", "320 Jack Purcell Lane", "https://example.com/d"),
+		facility("Jack Purcell CC", "320 Jack Purcell Lane", "https://example.com/b", "Swim", "Gym"),
+	})
+	if len(out) != 3 {
+		t.Fatalf("expected 3 facilities, got %d", len(out))
+	}
+	if f := out[0]; f.GetName() != "Bob MacQuarrie Recreation Complex-Orléans" || !slices.Equal(f.GetXAliases(), []string{"Bob MacQuarrie Recreation Complex - Orléans"}) || len(f.GetScheduleGroups()) != 2 {
+		t.Errorf("incorrectly merged by address: %v", f)
+	}
+	if f := out[1]; f.GetName() != "Jack Purcell CC" || !slices.Equal(f.GetXAliases(), []string{"Jack Purcell Community Centre"}) || len(f.GetScheduleGroups()) != 2 {
+		t.Errorf("incorrectly merged by url: %v", f)
+	}
+	if f := out[2]; f.GetName() != "Jack Purcell Park" || len(f.GetXAliases()) != 0 {
+		t.Errorf("incorrectly merged different name: %v", f)
+	}
+}
+
+func TestDedupeFacilitiesOrder(t *testing.T) {
+	facility := func(name, url string, groups ...string) *schema.Facility {
+		var f schema.Facility_builder
+		f.Name = name
+		f.Address = "1490 Youville Drive"
+		f.Source = schema.Source_builder{Url: url}.Build()
+		for _, g := range groups {
+			f.ScheduleGroups = append(f.ScheduleGroups, schema.ScheduleGroup_builder{Label: g}.Build())
+		}
+		return f.Build()
+	}
+	// a and b have the same page, and b and c have the same address and name,
+	// but a and c aren't duplicates of each other
+	for _, order := range [][]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}} {
+		fs := []*schema.Facility{
+			facility("Youville Pool", "https://example.com/a", "Swim"),
+			facility("Bob MacQuarrie Recreation Complex - Orléans", "https://example.com/a", "Swim", "Skate"),
+			facility("Bob MacQuarrie Recreation Complex-Orléans", "https://example.com/c", "Swim", "Skate", "Gym"),
+		}
+		var in []*schema.Facility
+		for _, i := range order {
+			in = append(in, fs[i])
+		}
+		out := dedupeFacilities(in)
+		if len(out) != 1 {
+			t.Errorf("order %v: expected 1 facility, got %d", order, len(out))
+			continue
+		}
+		if f := out[0]; f.GetName() != "Bob MacQuarrie Recreation Complex-Orléans" || !slices.Equal(slices.Sorted(slices.Values(f.GetXAliases())), []string{"Bob MacQuarrie Recreation Complex - Orléans", "Youville Pool"}) || len(f.GetScheduleGroups()) != 3 {
+			t.Errorf("order %v: incorrectly merged: %v", order, f)
+		}
+	}
+}
+
+func TestDedupeSchedules(t *testing.T) {
+	schedule := func(caption string, activities ...string) *schema.Schedule {
+		var as []*schema.Schedule_Activity
+		for _, a := range activities {
+			as = append(as, testActivity(a))
+		}
+		return testSchedule(caption, []string{"Monday"}, as...)
+	}
+	out, diags := dedupeSchedules([]*schema.Schedule{
+		schedule("Swim - January 6 to April 6", "Lane swim"),
+		schedule("Swim - April 7 to June 22", "Lane swim"),
+		schedule("Swim - January 6 to April 6", "Lane swim"),
+		schedule("Skate", "Public skating"),
+		schedule("Skate", "Public skating", "Shinny"),
+	})
+	if len(out) != 4 {
+		t.Errorf("expected 4 schedules, got %d", len(out))
+	}
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+	}
+	if d := diags[0]; d.GetSeverity() != schema.Diagnostic_ERROR || d.GetContext() != `schedule "Skate"` {
+		t.Errorf("unexpected diagnostic %v", d)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	for _, tc := range []struct {
+		A, B string
+		S    float64
+	}{
+		{"", "", 1},
+		{"abc", "abc", 1},
+		{"abc", "", 0},
+		{"kitten", "sitting", 1 - 3.0/7},
+		{"abcd", "abce", 0.75},
+	} {
+		if s := similarity(tc.A, tc.B); s != tc.S {
+			t.Errorf("similarity(%q, %q): expected %f, got %f", tc.A, tc.B, tc.S, s)
+		}
+	}
+}
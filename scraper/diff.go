@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// dataDiff summarizes the changes between two versions of the data.
+type dataDiff struct {
+	AddedFacilities   []string       `json:"added_facilities,omitempty"`
+	RemovedFacilities []string       `json:"removed_facilities,omitempty"`
+	ChangedFacilities []facilityDiff `json:"changed_facilities,omitempty"`
+}
+
+type facilityDiff struct {
+	Name             string         `json:"name"`
+	ChangedFields    []string       `json:"changed_fields,omitempty"`
+	AddedSchedules   []string       `json:"added_schedules,omitempty"`
+	RemovedSchedules []string       `json:"removed_schedules,omitempty"`
+	ChangedSchedules []scheduleDiff `json:"changed_schedules,omitempty"`
+
+	facility *schema.Facility
+}
+
+type scheduleDiff struct {
+	Name         string   `json:"name"`
+	AddedSlots   []string `json:"added_slots,omitempty"`
+	RemovedSlots []string `json:"removed_slots,omitempty"`
+}
+
+// diffData summarizes the changes between a and b (see [schema.Diff]).
+func diffData(a, b *schema.Data) dataDiff {
+	var (
+		d   dataDiff
+		cur *facilityDiff
+	)
+	for _, c := range schema.Diff(a, b) {
+		switch c.Kind {
+		case schema.FacilityAdded:
+			d.AddedFacilities = append(d.AddedFacilities, c.Facility.GetName())
+			continue
+		case schema.FacilityRemoved:
+			d.RemovedFacilities = append(d.RemovedFacilities, c.Facility.GetName())
+			continue
+		}
+		if cur == nil || cur.facility != c.Facility {
+			d.ChangedFacilities = append(d.ChangedFacilities, facilityDiff{Name: c.Facility.GetName(), facility: c.Facility})
+			cur = &d.ChangedFacilities[len(d.ChangedFacilities)-1]
+		}
+		switch c.Kind {
+		case schema.FieldChanged, schema.NotificationChanged:
+			cur.ChangedFields = append(cur.ChangedFields, c.Field)
+		case schema.ScheduleAdded:
+			cur.AddedSchedules = append(cur.AddedSchedules, c.Schedule)
+		case schema.ScheduleRemoved:
+			cur.RemovedSchedules = append(cur.RemovedSchedules, c.Schedule)
+		case schema.ScheduleChanged:
+			cur.ChangedSchedules = append(cur.ChangedSchedules, scheduleDiff{Name: c.Schedule})
+		case schema.TimeSlotAdded:
+			sd := &cur.ChangedSchedules[len(cur.ChangedSchedules)-1]
+			sd.AddedSlots = append(sd.AddedSlots, c.Slot)
+		case schema.TimeSlotRemoved:
+			sd := &cur.ChangedSchedules[len(cur.ChangedSchedules)-1]
+			sd.RemovedSlots = append(sd.RemovedSlots, c.Slot)
+		}
+	}
+	return d
+}
+
+// String formats d as a human-readable summary.
+func (d dataDiff) String() string {
+	var b strings.Builder
+	for _, x := range d.AddedFacilities {
+		fmt.Fprintf(&b, "+ facility %q\n", x)
+	}
+	for _, x := range d.RemovedFacilities {
+		fmt.Fprintf(&b, "- facility %q\n", x)
+	}
+	for _, f := range d.ChangedFacilities {
+		fmt.Fprintf(&b, "~ facility %q\n", f.Name)
+		if len(f.ChangedFields) != 0 {
+			fmt.Fprintf(&b, "    changed %s\n", strings.Join(f.ChangedFields, ", "))
+		}
+		for _, x := range f.AddedSchedules {
+			fmt.Fprintf(&b, "    + schedule %q\n", x)
+		}
+		for _, x := range f.RemovedSchedules {
+			fmt.Fprintf(&b, "    - schedule %q\n", x)
+		}
+		for _, s := range f.ChangedSchedules {
+			fmt.Fprintf(&b, "    ~ schedule %q\n", s.Name)
+			for _, x := range s.AddedSlots {
+				fmt.Fprintf(&b, "        + %s\n", x)
+			}
+			for _, x := range s.RemovedSlots {
+				fmt.Fprintf(&b, "        - %s\n", x)
+			}
+		}
+	}
+	return b.String()
+}
+
+// writeDiff loads the old and new data files and writes the diff to w as json
+// or human-readable text.
+func writeDiff(w io.Writer, oldName, newName string, asJSON bool) error {
+	a, err := loadData(oldName)
+	if err != nil {
+		return fmt.Errorf("load %q: %w", oldName, err)
+	}
+	b, err := loadData(newName)
+	if err != nil {
+		return fmt.Errorf("load %q: %w", newName, err)
+	}
+	d := diffData(a, b)
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(d)
+	}
+	_, err = io.WriteString(w, d.String())
+	return err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestDiffData(t *testing.T) {
+	facility := func(name, desc string, schedules map[string][]string) *schema.Facility {
+		var ss []*schema.Schedule
+		for caption, times := range schedules {
+			var days [][]*schema.TimeRange
+			for _, x := range times {
+				days = append(days, testTimes(x))
+			}
+			ss = append(ss, testSchedule(caption, []string{"Monday", "Tuesday"}, testActivity("Lane swim", days...)))
+		}
+		f := testFacility(name, "", ss...)
+		f.SetDescription(desc)
+		return f
+	}
+	a := schema.Data_builder{
+		Facilities: []*schema.Facility{
+			facility("a", "", map[string][]string{"Fall": {"7 - 8 am", "9 - 10 am"}}),
+			facility("b", "", nil),
+			facility("c", "", map[string][]string{"Fall": {"7 - 8 am"}, "Winter": {"7 - 8 am"}}),
+		},
+	}.Build()
+	b := schema.Data_builder{
+		Facilities: []*schema.Facility{
+			facility("a", "", map[string][]string{"Fall": {"7 - 8 am", "9 - 11 am"}}),
+			facility("c", "Pool", map[string][]string{"Fall": {"7 - 8 am"}, "Spring": {"7 - 8 am"}}),
+			facility("d", "", nil),
+		},
+	}.Build()
+	exp := `+ facility "d"
+- facility "b"
+~ facility "a"
+    ~ schedule "Swimming > Fall"
+        + Lane swim: Tuesday 9 - 11 am
+        - Lane swim: Tuesday 9 - 10 am
+~ facility "c"
+    changed description
+    + schedule "Swimming > Spring"
+    - schedule "Swimming > Winter"
+`
+	if act := diffData(a, b).String(); act != exp {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, act)
+	}
+	if d := diffData(a, a); len(d.AddedFacilities)+len(d.RemovedFacilities)+len(d.ChangedFacilities) != 0 {
+		t.Errorf("expected no changes, got:\n%s", d)
+	}
+}
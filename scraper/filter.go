@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// dataFilter selects a subset of the data to export.
+type dataFilter struct {
+	Facility *regexp.Regexp     // facility name or aliases
+	Activity *regexp.Regexp     // activity label or normalized name
+	Weekdays schema.WeekdaySet  // parsed weekday
+	Window   *schema.ClockRange // overlapping parsed time range
+}
+
+// newDataFilter parses the filter flags, returning nil if none are set.
+func newDataFilter(facility, activity, weekdays, window string) (*dataFilter, error) {
+	var (
+		df  dataFilter
+		set bool
+		err error
+	)
+	if facility != "" {
+		if df.Facility, err = regexp.Compile(facility); err != nil {
+			return nil, fmt.Errorf("facility: %w", err)
+		}
+		set = true
+	}
+	if activity != "" {
+		if df.Activity, err = regexp.Compile(activity); err != nil {
+			return nil, fmt.Errorf("activity: %w", err)
+		}
+		set = true
+	}
+	if weekdays != "" {
+		for x := range strings.SplitSeq(weekdays, ",") {
+			w, ok := schema.ParseWeekday(x)
+			if !ok {
+				return nil, fmt.Errorf("weekday: invalid weekday %q", strings.TrimSpace(x))
+			}
+			df.Weekdays = df.Weekdays.Add(w)
+		}
+		set = true
+	}
+	if window != "" {
+		var hh1, mm1, hh2, mm2 int
+		if n, err := fmt.Sscanf(window, "%d:%d-%d:%d", &hh1, &mm1, &hh2, &mm2); err != nil || n != 4 {
+			return nil, fmt.Errorf("time: invalid time window %q (expected HH:MM-HH:MM)", window)
+		}
+		r := schema.MakeClockRange(hh1, mm1, hh2, mm2)
+		if !r.IsValid() {
+			return nil, fmt.Errorf("time: invalid time window %q", window)
+		}
+		df.Window = &r
+		set = true
+	}
+	if !set {
+		return nil, nil
+	}
+	return &df, nil
+}
+
+// Apply returns a copy of pb with only the matching facilities and time
+// ranges (see [schema.Filter.Apply]).
+func (df *dataFilter) Apply(pb *schema.Data) *schema.Data {
+	var fl schema.Filter
+	if df.Facility != nil {
+		fl.Facility = func(f *schema.Facility) bool {
+			return slices.ContainsFunc(append([]string{f.GetName()}, f.GetXAliases()...), df.Facility.MatchString)
+		}
+	}
+	if df.Activity != nil {
+		fl.Activity = func(a *schema.Schedule_Activity) bool {
+			return df.Activity.MatchString(a.GetLabel()) || df.Activity.MatchString(a.GetXName())
+		}
+	}
+	if df.Weekdays != 0 || df.Window != nil {
+		fl.TimeRange = df.matchTime
+	}
+	return fl.Apply(pb)
+}
+
+func (df *dataFilter) matchTime(t *schema.TimeRange) bool {
+	if df.Weekdays != 0 && (!t.HasXWkday() || !df.Weekdays.Has(t.GetXWkday().AsWeekday())) {
+		return false
+	}
+	if df.Window != nil {
+		if !t.HasXStart() || !t.HasXEnd() {
+			return false
+		}
+		if !(schema.ClockRange{Start: schema.ClockTime(t.GetXStart()), End: schema.ClockTime(t.GetXEnd())}).Overlaps(*df.Window) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNewDataFilter(t *testing.T) {
+	if df, err := newDataFilter("", "", "", ""); df != nil || err != nil {
+		t.Errorf("expected no filter, got %v %v", df, err)
+	}
+	df, err := newDataFilter("(?i)pool", "swim", "sat, Sunday", "18:00-21:30")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if df.Weekdays != schema.MakeWeekdaySet(time.Saturday, time.Sunday) {
+		t.Errorf("unexpected weekdays %v", df.Weekdays)
+	}
+	if *df.Window != schema.MakeClockRange(18, 0, 21, 30) {
+		t.Errorf("unexpected window %v", df.Window)
+	}
+	if df, err := newDataFilter("", "", "lun., Mercredi", ""); err != nil || df.Weekdays != schema.MakeWeekdaySet(time.Monday, time.Wednesday) {
+		t.Errorf("unexpected french weekdays %v (error: %v)", df, err)
+	}
+	for _, args := range [][4]string{
+		{"(", "", "", ""},
+		{"", "", "sa", ""},
+		{"", "", "", "6pm-9pm"},
+		{"", "", "", "21:00-21:00"},
+	} {
+		if _, err := newDataFilter(args[0], args[1], args[2], args[3]); err == nil {
+			t.Errorf("%q: expected error", args)
+		}
+	}
+}
+
+func TestDataFilter(t *testing.T) {
+	activity := func(name string) *schema.Schedule_Activity {
+		return testActivity(name,
+			[]*schema.TimeRange{testSlot(schema.Weekday_SATURDAY, 9*60, 10*60), testSlot(schema.Weekday_SATURDAY, 19*60, 20*60)},
+			[]*schema.TimeRange{testSlot(schema.Weekday_SUNDAY, 9*60, 10*60)},
+		)
+	}
+	facility := func(name string) *schema.Facility {
+		return testFacility(name, "", testSchedule("", []string{"Saturday", "Sunday"}, activity("Lane swim"), activity("Public skate")))
+	}
+	pb := schema.Data_builder{
+		Facilities: []*schema.Facility{facility("A Pool"), facility("B Arena")},
+	}.Build()
+	orig := proto.CloneOf(pb)
+
+	df, _ := newDataFilter("Pool", "", "", "")
+	if out := df.Apply(pb); len(out.GetFacilities()) != 1 || out.GetFacilities()[0].GetName() != "A Pool" {
+		t.Errorf("facility filter: unexpected result %v", out)
+	}
+
+	df, _ = newDataFilter("", "skate", "sat", "18:00-22:00")
+	out := df.Apply(pb)
+	if len(out.GetFacilities()) != 2 {
+		t.Fatalf("slot filter: expected 2 facilities, got %d", len(out.GetFacilities()))
+	}
+	for _, f := range out.GetFacilities() {
+		acts := f.GetScheduleGroups()[0].GetSchedules()[0].GetActivities()
+		if len(acts) != 1 || acts[0].GetLabel() != "Public skate" {
+			t.Errorf("slot filter: unexpected activities %v", acts)
+			continue
+		}
+		if days := acts[0].GetDays(); len(days) != 2 || len(days[0].GetTimes()) != 1 || days[0].GetTimes()[0].GetXStart() != 19*60 || len(days[1].GetTimes()) != 0 {
+			t.Errorf("slot filter: unexpected days %v", days)
+		}
+	}
+
+	df, _ = newDataFilter("", "hockey", "", "")
+	if out := df.Apply(pb); len(out.GetFacilities()) != 0 {
+		t.Errorf("activity filter: expected no facilities, got %d", len(out.GetFacilities()))
+	}
+
+	if !proto.Equal(pb, orig) {
+		t.Errorf("input data was modified")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// exportGCal writes the occurrences between the from and to dates as a CSV
+// file which can be imported into Google Calendar. Since the format doesn't
+// have timezones, times are written as the wall clock time in loc.
+func exportGCal(w io.Writer, pb *schema.Data, from, to time.Time, loc *time.Location) error {
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	if err := cw.Write([]string{"Subject", "Start Date", "Start Time", "End Date", "End Time", "All Day Event", "Description", "Location", "Private"}); err != nil {
+		return err
+	}
+	for _, f := range pb.GetFacilities() {
+		location := f.GetName()
+		if x := strings.Join(strings.Fields(f.GetAddress()), " "); x != "" {
+			location += ", " + x
+		}
+		for o := range f.Occurrences(from, to, loc) {
+			start, end := o.Start, o.End
+
+			var desc strings.Builder
+			desc.WriteString(f.GetName())
+			if x := o.Schedule.GetCaption(); x != "" {
+				desc.WriteString(" - ")
+				desc.WriteString(x)
+			}
+			if x := o.Time.GetLabel(); x != "" {
+				desc.WriteString(" (")
+				desc.WriteString(x)
+				desc.WriteString(")")
+			}
+			if o.Holiday != nil {
+				desc.WriteString("\nNote: this is on ")
+				desc.WriteString(o.Holiday.Name)
+				desc.WriteString(", so the schedule may be different.")
+			}
+			if x := f.GetSource().GetUrl(); x != "" {
+				desc.WriteString("\n")
+				desc.WriteString(x)
+			}
+			if err := cw.Write([]string{
+				o.Activity.GetLabel(),
+				start.Format("01/02/2006"),
+				start.Format("03:04 PM"),
+				end.Format("01/02/2006"),
+				end.Format("03:04 PM"),
+				"False",
+				desc.String(),
+				location,
+				"False",
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestExportGCal(t *testing.T) {
+	pb := schema.Data_builder{
+		Facilities: []*schema.Facility{schema.Facility_builder{
+			Name:    "A Pool",
+			Address: "1 A Road\nOttawa",
+			Source:  schema.Source_builder{Url: "https://ottawa.ca/en/a"}.Build(),
+			ScheduleGroups: []*schema.ScheduleGroup{schema.ScheduleGroup_builder{
+				Schedules: []*schema.Schedule{schema.Schedule_builder{
+					Caption: "Fall schedule",
+					Days:    []string{"Monday"},
+					Activities: []*schema.Schedule_Activity{schema.Schedule_Activity_builder{
+						Label: "Lane swim",
+						Days: []*schema.Schedule_ActivityDay{schema.Schedule_ActivityDay_builder{
+							Times: []*schema.TimeRange{
+								schema.TimeRange_builder{Label: "11 pm - 1 am", XWkday: ptrTo(schema.Weekday_MONDAY), XStart: ptrTo(int32(23 * 60)), XEnd: ptrTo(int32(25 * 60))}.Build(),
+							},
+						}.Build()},
+					}.Build()},
+				}.Build()},
+			}.Build()},
+		}.Build()},
+	}.Build()
+	loc, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := exportGCal(&buf, pb, time.Date(2025, time.October, 7, 0, 0, 0, 0, time.UTC), time.Date(2025, time.October, 20, 0, 0, 0, 0, time.UTC), loc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exp := "Subject,Start Date,Start Time,End Date,End Time,All Day Event,Description,Location,Private\r\n" +
+		"Lane swim,10/13/2025,11:00 PM,10/14/2025,01:00 AM,False,\"A Pool - Fall schedule (11 pm - 1 am)\r\nNote: this is on Thanksgiving, so the schedule may be different.\r\nhttps://ottawa.ca/en/a\",\"A Pool, 1 A Road Ottawa\",False\r\n" +
+		"Lane swim,10/20/2025,11:00 PM,10/21/2025,01:00 AM,False,\"A Pool - Fall schedule (11 pm - 1 am)\r\nhttps://ottawa.ca/en/a\",\"A Pool, 1 A Road Ottawa\",False\r\n"
+	if act := buf.String(); act != exp {
+		t.Errorf("unexpected output:\n%s", act)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// healthCheck fetches the first page of the place listing and a known-good
+// facility page, and checks that the selectors the scraper depends on still
+// match, so changes to the website markup are caught before a full run.
+func healthCheck(ctx context.Context, listing, facility string) error {
+	var failed int
+	for _, page := range []struct {
+		category, url string
+		check         func(*goquery.Document) []error
+	}{
+		{CacheCategoryListing, listing, checkListingPage},
+		{CacheCategoryFacility, facility, checkFacilityPage},
+	} {
+		doc, _, err := fetchPage(ctx, page.category, page.url)
+		if err != nil {
+			return fmt.Errorf("fetch %q: %w", page.url, err)
+		}
+		errs := page.check(doc)
+		for _, err := range errs {
+			slog.Error("health check failed", "url", page.url, "error", err)
+		}
+		if len(errs) == 0 {
+			slog.Info("health check passed", "url", page.url)
+		}
+		failed += len(errs)
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d health checks failed", failed)
+	}
+	return nil
+}
+
+// checkListingPage checks the selectors used to scrape the first page of a
+// place listing.
+func checkListingPage(doc *goquery.Document) []error {
+	content, err := scrapeMainContentBlock(doc)
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	if next, err := scrapePagerNext(doc, content); err != nil {
+		errs = append(errs, fmt.Errorf("pager: %w", err))
+	} else if next == nil {
+		errs = append(errs, fmt.Errorf("pager: no next page found on the first page"))
+	}
+	var n int
+	if err := scrapePlaceListings(doc, content, func(u *url.URL, name, address string) error {
+		if name == "" || address == "" {
+			return fmt.Errorf("empty name or address")
+		}
+		n++
+		return nil
+	}); err != nil {
+		errs = append(errs, fmt.Errorf("place listings: %w", err))
+	} else if n == 0 {
+		errs = append(errs, fmt.Errorf("place listings: no places found"))
+	}
+	return errs
+}
+
+// checkFacilityPage checks the selectors used to scrape a facility page, which
+// is expected to have a description and at least one schedule.
+func checkFacilityPage(doc *goquery.Document) []error {
+	content, err := scrapeMainContentBlock(doc)
+	if err != nil {
+		return []error{err}
+	}
+	node, err := findOne(content, `.node.node--type-place`, "place node")
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	if _, err := scrapeNodeField(node, "description", "text-long", false, false); err != nil {
+		errs = append(errs, fmt.Errorf("description field: %w", err))
+	}
+	for _, name := range []string{"notification-details", "hours-details"} {
+		if _, err := scrapeNodeField(node, name, "text-long", false, true); err != nil {
+			errs = append(errs, fmt.Errorf("%s field: %w", name, err))
+		}
+	}
+	var sections, schedules int
+	if err := scrapeCollapseSections(node, func(label string, content *goquery.Selection) error {
+		sections++
+		if content.Find("table").Length() != 0 {
+			group, diags := scrapeScheduleGroup(doc, "", label, content, time.Time{})
+			schedules += len(group.GetSchedules())
+			if errs := schema.WithSeverity(diags, schema.Diagnostic_ERROR); len(errs) != 0 {
+				return errors.New(errs[0].Text())
+			}
+		}
+		return nil
+	}); err != nil {
+		errs = append(errs, fmt.Errorf("collapse sections: %w", err))
+	} else if sections == 0 {
+		errs = append(errs, fmt.Errorf("collapse sections: none found"))
+	} else if schedules == 0 {
+		errs = append(errs, fmt.Errorf("collapse sections: no schedules found"))
+	}
+	return errs
+}
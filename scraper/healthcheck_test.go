@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestCheckPages(t *testing.T) {
+	parse := func(s string) *goquery.Document {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(s))
+		if err != nil {
+			panic(err)
+		}
+		doc.Url, _ = url.Parse("https://ottawa.ca/en/recreation-and-parks/facilities/place-listing")
+		return doc
+	}
+
+	listing := `<div id="block-mainpagecontent"><div class="view-place-listing-search"><table><tbody>
+		<tr><td headers="view-title-table-column"><a href="/en/a">A</a></td><td headers="view-field-address-table-column">1 A Road</td></tr>
+	</tbody></table><nav class="pagerer-pager-basic" role="navigation"><a rel="next" href="?page=1">Next</a></nav></div></div>`
+	if errs := checkListingPage(parse(listing)); len(errs) != 0 {
+		t.Errorf("listing: unexpected errors %q", errs)
+	}
+	if errs := checkListingPage(parse(strings.ReplaceAll(listing, "pagerer-pager-basic", "pager"))); len(errs) != 1 {
+		t.Errorf("listing: expected pager error, got %q", errs)
+	}
+
+	facility := `<div id="block-mainpagecontent"><div class="node node--type-place">
+		<div class="field field--name-field-description field--type-text-long field__item">Test</div>
+		<a role="button" data-toggle="collapse" data-target="#c1">Drop-in schedules</a>
+		<div id="c1"><table><caption>Swim</caption><tr><th></th><th>Monday</th></tr><tr><th>Lane swim</th><td>7 - 8 am</td></tr></table></div>
+	</div></div>`
+	if errs := checkFacilityPage(parse(facility)); len(errs) != 0 {
+		t.Errorf("facility: unexpected errors %q", errs)
+	}
+	if errs := checkFacilityPage(parse(strings.ReplaceAll(facility, "field--name-field-description", "field--name-field-body"))); len(errs) != 1 {
+		t.Errorf("facility: expected description error, got %q", errs)
+	}
+	if errs := checkFacilityPage(parse(strings.ReplaceAll(facility, `data-toggle="collapse"`, ""))); len(errs) != 1 {
+		t.Errorf("facility: expected collapse section error, got %q", errs)
+	}
+}
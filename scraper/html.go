@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+//go:embed html.tmpl
+var htmlTemplateText string
+
+var htmlTemplate = template.Must(template.New("").Parse(htmlTemplateText))
+
+// exportHTML renders pb as a single self-contained html page without scripts
+// or links to other pages, with a table for each schedule grouped by facility.
+// Unlike exportSite, it is intended for printing or archiving.
+func exportHTML(pb *schema.Data) ([]byte, error) {
+	type htmlFacility struct {
+		ID       string
+		Facility *schema.Facility
+	}
+	var (
+		ids        = map[string]bool{}
+		facilities []htmlFacility
+	)
+	for _, f := range pb.GetFacilities() {
+		facilities = append(facilities, htmlFacility{
+			ID:       siteSlug(f.GetName(), ids),
+			Facility: f,
+		})
+	}
+	data := map[string]any{
+		"Facilities":  facilities,
+		"Attribution": pb.GetAttribution(),
+	}
+	if updated := dataUpdated(pb); !updated.IsZero() {
+		data["Updated"] = updated.Format("January 2, 2006")
+	}
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestExportHTML(t *testing.T) {
+	facility := func(name string) *schema.Facility {
+		f := testFacility(name, "1 A Road", testSchedule("Swim", []string{"Monday", "Tuesday"},
+			testActivity("Lane swim <18+>", testTimes("7 - 8 am", "6 - 7 pm"), nil),
+		))
+		f.SetNotificationsHtml("<p>Closed <b>today</b></p>")
+		return f
+	}
+	pb := schema.Data_builder{
+		Attribution: []string{"Test attribution"},
+		Facilities:  []*schema.Facility{facility("A Pool"), facility("a pool")},
+	}.Build()
+
+	buf, err := exportHTML(pb)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, x := range []string{
+		`<a href="#a-pool">A Pool</a>`,
+		`<a href="#a-pool-2">a pool</a>`,
+		`<section id="a-pool-2">`,
+		`<caption>Swim</caption>`,
+		`<tr><th></th><th>Monday</th><th>Tuesday</th></tr>`,
+		`<tr><th>Lane swim &lt;18&#43;&gt;</th><td>7 - 8 am<br>6 - 7 pm</td><td></td></tr>`,
+		"Last updated September 1, 2025.",
+		"Test attribution",
+	} {
+		if !strings.Contains(string(buf), x) {
+			t.Errorf("expected output to contain %q", x)
+		}
+	}
+	for _, x := range []string{"<script", "Closed"} {
+		if strings.Contains(string(buf), x) {
+			t.Errorf("expected output to not contain %q", x)
+		}
+	}
+}
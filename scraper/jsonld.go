@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// exportJSONLD converts pb into a schema.org JSON-LD graph with a dataset
+// crediting the attribution, a place for each facility, and a recurring event
+// for each activity in each schedule, including only time ranges which were
+// parsed. Schedule times are in loc.
+func exportJSONLD(pb *schema.Data, loc *time.Location) ([]byte, error) {
+	graph := []any{}
+	if attrib := pb.GetAttribution(); len(attrib) != 0 {
+		graph = append(graph, map[string]any{
+			"@type":      "Dataset",
+			"name":       "Ottawa Recreation Schedules",
+			"creditText": strings.Join(attrib, "\n"),
+		})
+	}
+	for _, f := range pb.GetFacilities() {
+		id := f.GetSource().GetUrl()
+		if id == "" {
+			continue
+		}
+		place := map[string]any{
+			"@type": "SportsActivityLocation",
+			"@id":   id,
+			"name":  f.GetName(),
+			"url":   id,
+		}
+		if x := f.GetAddress(); x != "" {
+			place["address"] = strings.Join(strings.Fields(x), " ")
+		}
+		if x := f.GetDescription(); x != "" {
+			place["description"] = x
+		}
+		if f.HasXLnglat() {
+			place["geo"] = map[string]any{
+				"@type":     "GeoCoordinates",
+				"latitude":  f.GetXLnglat().GetLat(),
+				"longitude": f.GetXLnglat().GetLng(),
+			}
+		}
+		graph = append(graph, place)
+
+		for _, g := range f.GetScheduleGroups() {
+			for _, s := range g.GetSchedules() {
+				for _, a := range s.GetActivities() {
+					var sched []any
+					for _, d := range a.GetDays() {
+						for _, t := range d.GetTimes() {
+							wkday, r, ok := t.AsXParsed()
+							if !ok || !r.IsValid() {
+								continue
+							}
+							x := map[string]any{
+								"@type":            "Schedule",
+								"byDay":            "https://schema.org/" + wkday.String(),
+								"startTime":        jsonldTime(r.Start),
+								"endTime":          jsonldTime(r.End),
+								"repeatFrequency":  "P1W",
+								"scheduleTimezone": loc.String(),
+							}
+							if from, ok := scheduleDate(s.GetXFrom()); ok {
+								x["startDate"] = from.Format(time.DateOnly)
+							}
+							if to, ok := scheduleDate(s.GetXTo()); ok {
+								x["endDate"] = to.Format(time.DateOnly)
+							}
+							sched = append(sched, x)
+						}
+					}
+					if len(sched) == 0 {
+						continue
+					}
+					event := map[string]any{
+						"@type":               "Event",
+						"name":                a.GetLabel(),
+						"url":                 id,
+						"location":            map[string]any{"@id": id},
+						"eventSchedule":       sched,
+						"eventAttendanceMode": "https://schema.org/OfflineEventAttendanceMode",
+					}
+					if x := s.GetCaption(); x != "" {
+						event["description"] = x
+					}
+					graph = append(graph, event)
+				}
+			}
+		}
+	}
+	return json.MarshalIndent(map[string]any{
+		"@context": "https://schema.org",
+		"@graph":   graph,
+	}, "", "  ")
+}
+
+// jsonldTime formats a clock time as an ISO 8601 time, wrapping times past
+// midnight.
+func jsonldTime(t schema.ClockTime) string {
+	_, hh, mm := t.Split()
+	return fmt.Sprintf("%02d:%02d:00", hh, mm)
+}
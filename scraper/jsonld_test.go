@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestExportJSONLD(t *testing.T) {
+	pb := schema.Data_builder{
+		Attribution: []string{"A", "B"},
+		Facilities: []*schema.Facility{schema.Facility_builder{
+			Name:    "A Pool",
+			Address: "1 A Road\nOttawa",
+			Source:  schema.Source_builder{Url: "https://ottawa.ca/en/a"}.Build(),
+			XLnglat: schema.LngLat_builder{Lng: -75.5, Lat: 45.25}.Build(),
+			ScheduleGroups: []*schema.ScheduleGroup{schema.ScheduleGroup_builder{
+				Schedules: []*schema.Schedule{schema.Schedule_builder{
+					Caption: "Fall schedule",
+					XFrom:   ptrTo(int32(schema.MakeDate(2025, time.September, 1, -1))),
+					Days:    []string{"Monday"},
+					Activities: []*schema.Schedule_Activity{
+						schema.Schedule_Activity_builder{
+							Label: "Lane swim",
+							Days: []*schema.Schedule_ActivityDay{schema.Schedule_ActivityDay_builder{
+								Times: []*schema.TimeRange{
+									schema.TimeRange_builder{XWkday: ptrTo(schema.Weekday_MONDAY), XStart: ptrTo(int32(23 * 60)), XEnd: ptrTo(int32(25 * 60))}.Build(),
+									schema.TimeRange_builder{Label: "unparsed"}.Build(),
+								},
+							}.Build()},
+						}.Build(),
+						schema.Schedule_Activity_builder{
+							Label: "Unparsed",
+							Days: []*schema.Schedule_ActivityDay{schema.Schedule_ActivityDay_builder{
+								Times: []*schema.TimeRange{schema.TimeRange_builder{Label: "unparsed"}.Build()},
+							}.Build()},
+						}.Build(),
+					},
+				}.Build()},
+			}.Build()},
+		}.Build()},
+	}.Build()
+	loc, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	buf, err := exportJSONLD(pb, loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var obj struct {
+		Context string `json:"@context"`
+		Graph   []struct {
+			Type     string         `json:"@type"`
+			Name     string         `json:"name"`
+			Credit   string         `json:"creditText"`
+			Address  string         `json:"address"`
+			Geo      map[string]any `json:"geo"`
+			Location map[string]any `json:"location"`
+			Schedule []struct {
+				ByDay     string `json:"byDay"`
+				StartTime string `json:"startTime"`
+				EndTime   string `json:"endTime"`
+				StartDate string `json:"startDate"`
+				EndDate   string `json:"endDate"`
+				Timezone  string `json:"scheduleTimezone"`
+			} `json:"eventSchedule"`
+		} `json:"@graph"`
+	}
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if obj.Context != "https://schema.org" || len(obj.Graph) != 3 {
+		t.Fatalf("unexpected output:\n%s", buf)
+	}
+	if d := obj.Graph[0]; d.Type != "Dataset" || d.Credit != "A\nB" {
+		t.Errorf("unexpected dataset %+v", d)
+	}
+	if p := obj.Graph[1]; p.Type != "SportsActivityLocation" || p.Address != "1 A Road Ottawa" || p.Geo["latitude"] != 45.25 {
+		t.Errorf("unexpected place %+v", p)
+	}
+	if e := obj.Graph[2]; e.Type != "Event" || e.Name != "Lane swim" || e.Location["@id"] != "https://ottawa.ca/en/a" || len(e.Schedule) != 1 {
+		t.Errorf("unexpected event %+v", e)
+	} else if s := e.Schedule[0]; s.ByDay != "https://schema.org/Monday" || s.StartTime != "23:00:00" || s.EndTime != "01:00:00" || s.StartDate != "2025-09-01" || s.EndDate != "" || s.Timezone != "America/Toronto" {
+		t.Errorf("unexpected event schedule %+v", s)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+type kmlDocument struct {
+	XMLName    xml.Name       `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Name       string         `xml:"Document>name"`
+	Desc       string         `xml:"Document>description,omitempty"`
+	Placemarks []kmlPlacemark `xml:"Document>Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name    string `xml:"name"`
+	Address string `xml:"address,omitempty"`
+	Desc    string `xml:"description,omitempty"`
+	Point   string `xml:"Point>coordinates"`
+}
+
+// exportKML converts pb into a KML document with a placemark for each
+// geocoded facility, with the schedules which haven't ended as of now in the
+// description.
+func exportKML(pb *schema.Data, now time.Time) ([]byte, error) {
+	doc := kmlDocument{
+		Name: "Ottawa Recreation Schedules",
+		Desc: strings.Join(pb.GetAttribution(), "\n"),
+	}
+	today := schema.MakeDate(now.Year(), now.Month(), now.Day(), now.Weekday())
+	for _, f := range pb.GetFacilities() {
+		if !f.HasXLnglat() {
+			continue
+		}
+		doc.Placemarks = append(doc.Placemarks, kmlPlacemark{
+			Name:    f.GetName(),
+			Address: f.GetAddress(),
+			Desc:    kmlFacilityDescription(f, today),
+			Point:   fmt.Sprintf("%f,%f", f.GetXLnglat().GetLng(), f.GetXLnglat().GetLat()),
+		})
+	}
+	buf, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), buf...), nil
+}
+
+// kmlFacilityDescription formats the facility information and upcoming
+// schedules as html for a placemark balloon.
+func kmlFacilityDescription(f *schema.Facility, today schema.Date) string {
+	var b strings.Builder
+	if x := f.GetAddress(); x != "" {
+		fmt.Fprintf(&b, "<p>%s</p>", strings.ReplaceAll(html.EscapeString(x), "\n", "<br>"))
+	}
+	if x := f.GetSource().GetUrl(); x != "" {
+		fmt.Fprintf(&b, "<p><a href=\"%s\">%s</a></p>", html.EscapeString(x), html.EscapeString(x))
+	}
+	for _, g := range f.GetScheduleGroups() {
+		var wroteGroup bool
+		for _, s := range g.GetSchedules() {
+			if !kmlUpcoming(s, today) {
+				continue
+			}
+			if !wroteGroup {
+				fmt.Fprintf(&b, "<h3>%s</h3>", html.EscapeString(g.GetLabel()))
+				wroteGroup = true
+			}
+			fmt.Fprintf(&b, "<h4>%s</h4><ul>", html.EscapeString(s.GetCaption()))
+			for _, a := range s.GetActivities() {
+				var times []string
+				for i, d := range a.GetDays() {
+					for _, t := range d.GetTimes() {
+						if i < len(s.GetDays()) {
+							times = append(times, s.GetDays()[i]+" "+t.GetLabel())
+						} else {
+							times = append(times, t.GetLabel())
+						}
+					}
+				}
+				if len(times) != 0 {
+					fmt.Fprintf(&b, "<li><b>%s</b>: %s</li>", html.EscapeString(a.GetLabel()), html.EscapeString(strings.Join(times, ", ")))
+				}
+			}
+			b.WriteString("</ul>")
+		}
+	}
+	return b.String()
+}
+
+// kmlUpcoming checks whether s hasn't ended as of today. Schedules without a
+// fully specified end date are assumed to be current.
+func kmlUpcoming(s *schema.Schedule, today schema.Date) bool {
+	if !s.HasXTo() {
+		return true
+	}
+	to := schema.Date(s.GetXTo())
+	if _, ok := to.Year(); !ok {
+		return true
+	}
+	if _, ok := to.Day(); !ok {
+		return true
+	}
+	return !to.Before(today)
+}
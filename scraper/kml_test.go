@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestExportKML(t *testing.T) {
+	schedule := func(caption string, to schema.Date) *schema.Schedule {
+		b := schema.Schedule_builder{
+			Caption: caption,
+			Days:    []string{"Monday"},
+			Activities: []*schema.Schedule_Activity{schema.Schedule_Activity_builder{
+				Label: "Lane swim",
+				Days: []*schema.Schedule_ActivityDay{schema.Schedule_ActivityDay_builder{
+					Times: []*schema.TimeRange{schema.TimeRange_builder{Label: "7 - 8 am"}.Build()},
+				}.Build()},
+			}.Build()},
+		}
+		if to != 0 {
+			b.XTo = ptrTo(int32(to))
+		}
+		return b.Build()
+	}
+	pb := schema.Data_builder{
+		Attribution: []string{"Test"},
+		Facilities: []*schema.Facility{
+			schema.Facility_builder{
+				Name:    "A & B Pool",
+				Address: "1 A Road",
+				XLnglat: schema.LngLat_builder{Lng: -75.5, Lat: 45.25}.Build(),
+				ScheduleGroups: []*schema.ScheduleGroup{schema.ScheduleGroup_builder{
+					Label: "Swimming",
+					Schedules: []*schema.Schedule{
+						schedule("Current", schema.MakeDate(2025, time.September, 1, -1)),
+						schedule("Old", schema.MakeDate(2025, time.June, 1, -1)),
+						schedule("Undated", 0),
+					},
+				}.Build()},
+			}.Build(),
+			schema.Facility_builder{Name: "Not geocoded"}.Build(),
+		},
+	}.Build()
+	buf, err := exportKML(pb, time.Date(2025, time.September, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := string(buf)
+	for _, x := range []string{
+		`<kml xmlns="http://www.opengis.net/kml/2.2">`,
+		`<name>A &amp; B Pool</name>`,
+		`<coordinates>-75.500000,45.250000</coordinates>`,
+		`&lt;h4&gt;Current&lt;/h4&gt;`,
+		`&lt;h4&gt;Undated&lt;/h4&gt;`,
+		`Monday 7 - 8 am`,
+	} {
+		if !strings.Contains(s, x) {
+			t.Errorf("expected output to contain %q", x)
+		}
+	}
+	for _, x := range []string{"Not geocoded", "&lt;h4&gt;Old"} {
+		if strings.Contains(s, x) {
+			t.Errorf("expected output to not contain %q", x)
+		}
+	}
+}
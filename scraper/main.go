@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"cmp"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/fs"
 	"log/slog"
 	"maps"
@@ -21,8 +25,8 @@ import (
 	"slices"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
+	_ "time/tzdata"
 	"unicode"
 
 	"github.com/PuerkitoBio/goquery"
@@ -30,6 +34,7 @@ import (
 	"github.com/pgaskin/ottrec/internal/zyte"
 	"github.com/pgaskin/ottrec/schema"
 	textpbfmt "github.com/protocolbuffers/txtpbfmt/parser"
+	"golang.org/x/net/html"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 	"golang.org/x/text/unicode/norm"
@@ -42,27 +47,107 @@ import (
 
 var (
 	Scrape       = flag.Bool("scrape", false, "parse data from pages")
-	ExportProto  = flag.String("export.proto", "", "write proto to this file")
-	ExportPB     = flag.String("export.pb", "", "write binpb to this file")
-	ExportTextPB = flag.String("export.textpb", "", "write textpb to this file")
-	ExportJSON   = flag.String("export.json", "", "write json to this file")
+	ExportProto  = flag.String("export.proto", "", "write proto to this file (- for stdout)")
+	ExportPB     = flag.String("export.pb", "", "write binpb to this file (- for stdout)")
+	ExportDesc   = flag.String("export.desc", "", "write a binpb FileDescriptorSet for decoding the binpb without the proto to this file (- for stdout)")
+	ExportTextPB = flag.String("export.textpb", "", "write textpb to this file (- for stdout)")
+	ExportJSON   = flag.String("export.json", "", "write json to this file (- for stdout)")
+	ExportCBOR   = flag.String("export.cbor", "", "write cbor (same structure as the json) to this file (- for stdout)")
+	ExportXLSX   = flag.String("export.xlsx", "", "write xlsx (one sheet per facility) to this file (- for stdout)")
+	ExportNDJSON = flag.String("export.ndjson", "", "write ndjson (one object per schedule time slot) to this file (- for stdout)")
+	ExportPivot  = flag.String("export.pivot", "", "write csv (one row per schedule activity with a column per weekday, like the website) to this file (- for stdout)")
+	ExportHTML   = flag.String("export.html", "", "write a single-page html dump of all schedules (for printing or archiving) to this file (- for stdout)")
+	ExportSite   = flag.String("export.site", "", "write a static html website to this directory")
+	ExportGCal   = flag.String("export.gcal", "", "write google calendar csv (schedule occurrences, see -expand.from and -expand.to) to this file (- for stdout)")
+	ExportKML    = flag.String("export.kml", "", "write kml (geocoded facilities with upcoming schedules) to this file (- for stdout)")
+	ExportJSONLD = flag.String("export.jsonld", "", "write schema.org json-ld (facilities and recurring activity events) to this file (- for stdout)")
 	ExportPretty = flag.Bool("export.pretty", false, "prettify output (-json -textpb)")
 
+	ExportPBRaw       = flag.String("export.pb.raw", "", "write binpb with only the raw data from the source pages (without any parsed or derived fields) to this file (- for stdout)")
+	ExportJSONCompact = flag.String("export.json.compact", "", "write gzipped minified json (short keys, parsed fields only, no html) for bundling into apps to this file (- for stdout)")
+	ExportDatapackage = flag.String("export.datapackage", "", "write a frictionless data package descriptor for the other exported files (with table schemas for the csv exports) to this file (- for stdout)")
+	ExportManifest    = flag.String("export.manifest", "", "write a json manifest listing the other exported files with their format, size, and sha256 to this file (- for stdout)")
+	ExportLite        = flag.Bool("export.lite", false, "strip the html fields and descriptions from the data before exporting it, for a smaller payload")
+	ExportCanonical   = flag.Bool("export.canonical", false, "sort and deduplicate the facilities, schedules, and attribution before exporting them, so diffs between snapshots don't include ordering changes")
+	ExportAttribution = flag.Bool("export.attribution", false, "write ATTRIBUTION.txt with the data attribution next to each exported file (and inside the -export.site directory)")
+
+	ExportStats     = flag.String("export.stats", "", "write a human-readable summary of the data coverage and quality to this file (- for stdout)")
+	ExportStatsJSON = flag.String("export.stats.json", "", "write the summary of the data coverage and quality as json to this file (- for stdout)")
+
+	TZ           = flag.String("tz", "America/Toronto", "timezone to interpret schedule times in for exports with absolute timestamps")
+	Reproducible = flag.Bool("reproducible", false, "make exports byte-for-byte deterministic for identical input (canonical json/textpb formatting, the latest source date instead of the current time, and no scrape run id or times)")
+
+	ExpandFrom = flag.String("expand.from", "", "first date (YYYY-MM-DD) to expand schedules into occurrences for (-export.gcal), default today")
+	ExpandTo   = flag.String("expand.to", "", "last date (YYYY-MM-DD) to expand schedules into occurrences for (-export.gcal), default four weeks after -expand.from")
+
+	FilterFacility = flag.String("filter.facility", "", "only export facilities with a name matching this regexp")
+	FilterActivity = flag.String("filter.activity", "", "only export activities with a label or normalized name matching this regexp")
+	FilterWeekday  = flag.String("filter.weekday", "", "only export time ranges on these comma-separated weekdays (e.g., sat,sun)")
+	FilterTime     = flag.String("filter.time", "", "only export time ranges overlapping this 24-hour time window (HH:MM-HH:MM)")
+
 	Cache              = flag.String("cache", "", "cache pages in the specified directory")
 	CachePurgeListing  = flag.Bool("cache.purge.listing", false, "remove cached facility listing")
 	CachePurgeFacility = flag.Bool("cache.purge.facility", false, "remove cached facility pages")
 	CachePurgeGeocode  = flag.Bool("cache.purge.geocode", false, "remove cached geocoding data")
+	CachePurgeActivity = flag.Bool("cache.purge.activity", false, "remove cached activity pages")
 
-	Fetch     = flag.Bool("fetch", false, "fetch uncached pages")
-	FetchZyte = flag.Int("fetch.zyte", 0, "use zyte, allowing the specified number of paid requests (set ZYTE_APIKEY)")
+	CacheMaxAge         = flag.Duration("cache.max-age", 0, "refetch cached responses older than this if fetching (zero to use them indefinitely)")
+	CacheMaxAgeListing  = flag.Duration("cache.max-age.listing", 0, "override -cache.max-age for the facility listing")
+	CacheMaxAgeFacility = flag.Duration("cache.max-age.facility", 0, "override -cache.max-age for facility pages")
+	CacheMaxAgeGeocode  = flag.Duration("cache.max-age.geocode", 0, "override -cache.max-age for geocoding data")
+	CacheMaxAgeActivity = flag.Duration("cache.max-age.activity", 0, "override -cache.max-age for activity pages")
+	CacheRefresh        = listFlag("cache.refresh", "refetch the specified url if fetching (may be specified multiple times)")
+
+	Fetch      = flag.Bool("fetch", false, "fetch uncached pages")
+	FetchZyte  = flag.Int("fetch.zyte", 0, "use zyte, allowing the specified number of paid requests (set ZYTE_APIKEY)")
+	FetchProxy = flag.String("fetch.proxy", "", "fetch pages using the specified http, https, or socks5 proxy url (other requests use the HTTPS_PROXY/HTTP_PROXY/NO_PROXY environment variables)")
+
+	Header    = listFlag("header", "set a request header, optionally only for a domain (format: [domain=]Name: value, where a domain with a leading dot also matches subdomains and an empty value removes the header; may be specified multiple times)")
+	UserAgent = listFlag("user-agent", "override the user agent, optionally only for a domain (format: [domain=]ua, where a domain with a leading dot also matches subdomains; may be specified multiple times)")
+
+	RequestTimeout = flag.Duration("request-timeout", 0, "abort uncached requests (including retries) taking longer than this (zero for no limit)")
+	Deadline       = flag.Duration("deadline", 0, "abort the run if it takes longer than this (zero for no limit)")
 
 	Geocodio = flag.Bool("geocodio", false, "use geocodio for geocoding (set GEOCODIO_APIKEY)")
 
+	Strict = flag.Bool("strict", false, "treat parse warnings for unparseable time ranges, weekday headers, and date ranges as errors, failing the scrape (after exporting) if any facility has them")
+
+	PlaceListing = listFlag("place-listing", "scrape facilities from this place listing url instead of the default one (may be specified multiple times)")
+
+	HealthCheck = flag.String("healthcheck", "", "instead of scraping, check that the selectors the scraper depends on still match the place listing and this known-good facility page")
+
+	Input = listFlag("input", "instead of fetching and scraping pages, load data from this file (binpb, json, or textpb, or - for stdin), geocode facilities which are missing coordinates or previously failed (if -geocodio is set), and export it (may be specified multiple times to merge snapshots)")
+
+	Validate = flag.Bool("validate", false, "instead of exporting the data in -input, check it for invalid values and exit with a non-zero status if any are found")
+
+	Diff     = flag.String("diff", "", "instead of scraping, compare the data in this file with the data in -input and write a summary of the changes to stdout")
+	DiffJSON = flag.Bool("diff.json", false, "write the diff summary as json")
+
+	Sheets            = flag.String("sheets", "", "replace the contents of a sheet in this google sheets spreadsheet id with the -export.pivot view (the spreadsheet must be shared with the service account)")
+	SheetsSheet       = flag.String("sheets.sheet", "Sheet1", "name of the sheet to replace for -sheets")
+	SheetsCredentials = flag.String("sheets.credentials", "", "google service account json key file for -sheets")
+
+	Upload             = flag.String("upload", "", "upload the exported files to cloud storage (s3://bucket/prefix or gs://bucket/prefix using AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or an azure blob storage container sas url)")
+	UploadCacheControl = flag.String("upload.cache-control", "", "cache-control header to set on uploaded files")
+
+	CrossCheck      = flag.String("crosscheck", "", "cross-check parsed schedule times against the other language version of the data in this file (binpb, json, or textpb)")
+	CrossCheckMerge = flag.Bool("crosscheck.merge", false, "with -crosscheck, also cross-check the other language version against the scraped data, and export both of them merged, with linked facilities referencing each other's id")
+
 	ScraperSecret  = os.Getenv("OTTCA_SCRAPER_SECRET")
 	GeocodioAPIKey = os.Getenv("GEOCODIO_APIKEY")
 	ZyteAPIKey     = os.Getenv("ZYTE_APIKEY")
 )
 
+// listFlag defines a flag which may be specified multiple times.
+func listFlag(name, usage string) *[]string {
+	var v []string
+	flag.Func(name, usage, func(s string) error {
+		v = append(v, s)
+		return nil
+	})
+	return &v
+}
+
 func defaultUserAgent() string {
 	var ua strings.Builder
 	ua.WriteString("ottawa-rec-scraper-bot/0.1")
@@ -82,9 +167,117 @@ func defaultUserAgent() string {
 	return ua.String()
 }
 
+// runID returns an identifier for the current scrape run, which is the github
+// actions run id and attempt if available, or a random one otherwise.
+func runID() string {
+	if id := os.Getenv("GITHUB_RUN_ID"); id != "" {
+		return id + "-" + cmp.Or(os.Getenv("GITHUB_RUN_ATTEMPT"), "1")
+	}
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// fetchCache is the response cache used by [http.DefaultClient], if set up.
+var fetchCache *httpcache.Transport
+
+// exportFilter is applied to the data before exporting, if set.
+var exportFilter *dataFilter
+
 func main() {
 	flag.Parse()
 
+	if df, err := newDataFilter(*FilterFacility, *FilterActivity, *FilterWeekday, *FilterTime); err != nil {
+		fmt.Fprintf(os.Stderr, "error: filter: %v\n", err)
+		os.Exit(2)
+	} else {
+		exportFilter = df
+	}
+	if *Sheets != "" && *SheetsCredentials == "" {
+		fmt.Fprintf(os.Stderr, "error: sheets: -sheets.credentials is required\n")
+		os.Exit(2)
+	}
+	if *Upload != "" {
+		if _, err := newUploadTarget(*Upload); err != nil {
+			fmt.Fprintf(os.Stderr, "error: upload: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if loc, err := exportLocation(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: tz: %v\n", err)
+		os.Exit(2)
+	} else if _, _, err := expandRange(time.Now().In(loc)); err != nil {
+		fmt.Fprintf(os.Stderr, "error: expand: %v\n", err)
+		os.Exit(2)
+	}
+
+	if err := setupHTTP(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	if *Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, *Deadline, fmt.Errorf("deadline of %s exceeded", *Deadline))
+		defer cancel()
+	}
+
+	var err error
+	if *HealthCheck != "" {
+		listing := defaultPlaceListing
+		if len(*PlaceListing) != 0 {
+			listing = (*PlaceListing)[0]
+		}
+		err = healthCheck(ctx, listing, *HealthCheck)
+	} else if *Validate {
+		if len(*Input) == 0 {
+			fmt.Fprintf(os.Stderr, "error: -validate requires -input\n")
+			os.Exit(2)
+		}
+		err = validate(os.Stdout, *Input)
+	} else if *Diff != "" {
+		if len(*Input) != 1 {
+			fmt.Fprintf(os.Stderr, "error: -diff requires a single -input\n")
+			os.Exit(2)
+		}
+		err = writeDiff(os.Stdout, *Diff, (*Input)[0], *DiffJSON)
+	} else {
+		err = run(ctx)
+	}
+	if err != nil {
+		if cause := context.Cause(ctx); cause != nil {
+			err = fmt.Errorf("%w (%w)", err, cause)
+		}
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// publishClient is used for publishing the data (e.g., uploads and sheets).
+// Unlike [http.DefaultClient], it doesn't go through the response cache, since
+// the cache only supports fetching, and the responses may contain secrets.
+var publishClient = &http.Client{}
+
+// setupHTTP sets up [http.DefaultTransport] and [http.DefaultClient] for
+// fetching pages according to the flags, and publishClient for publishing.
+func setupHTTP() error {
+	// use a proxy for pages
+	if *FetchProxy != "" {
+		proxy, err := parseProxy(*FetchProxy)
+		if err != nil {
+			return err
+		}
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.Proxy = func(r *http.Request) (*url.URL, error) {
+			if matchDomain(".ottawa.ca", r.URL) {
+				return proxy, nil
+			}
+			return http.ProxyFromEnvironment(r)
+		}
+		http.DefaultTransport = t
+	}
+
 	if b, _ := strconv.ParseBool(os.Getenv("OTTREC_DEBUG_HTTP")); b {
 		next := http.DefaultTransport
 		http.DefaultTransport = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
@@ -115,17 +308,16 @@ func main() {
 
 	// use zyte for some requests
 	if *FetchZyte > 0 {
+		retry := zyte.RetryLimit(3)
 		next := &zyte.Transport{
 			APIKey: ZyteAPIKey,
 			Limit:  zyte.FixedLimit(*FetchZyte),
 			Retry: func(ctx context.Context, tries, code int) bool {
-				if tries >= 3 {
-					return false
-				}
-				slog.Warn("zyte temporary error, retrying in a second")
-				return true
+				slog.Warn("zyte temporary error", "tries", tries, "status", code)
+				return retry(ctx, tries, code)
 			},
 			FollowRedirect: true,
+			Geolocation:    "CA", // ottawa.ca bans foreign ips more often
 			Next:           http.DefaultTransport,
 		}
 		http.DefaultTransport = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
@@ -136,17 +328,30 @@ func main() {
 				r2.Header.Del("Cookie")
 				r2.Header.Del("X-Scraper-Secret")
 				r = &r2
-				return next.RoundTrip(r)
+				resp, err := next.RoundTrip(r)
+				if err == nil {
+					resp.Header.Set(fetchChannelHeader, "zyte")
+				}
+				return resp, err
 			}
 			return next.Next.RoundTrip(r)
 		})
 	}
 
+	// limit the time taken by each request
+	if *RequestTimeout > 0 {
+		http.DefaultTransport = timeoutRoundTripper(http.DefaultTransport, *RequestTimeout)
+	}
+
 	// apply rate limits if not cached
 	http.DefaultTransport = rateLimitRoundTripper(http.DefaultTransport, ".ottawa.ca", rate.NewLimiter(rate.Every(time.Second*2), 1))
 	http.DefaultTransport = rateLimitRoundTripper(http.DefaultTransport, "api.geocod.io", rate.NewLimiter(rate.Every(time.Minute/1000), 1))
 
+	// record how responses were fetched
+	http.DefaultTransport = channelRoundTripper(http.DefaultTransport)
+
 	// cache responses
+	uncached := http.DefaultTransport
 	redactor := new(httpcache.Redactor)
 	cache := &httpcache.Transport{
 		Path:             *Cache,
@@ -157,7 +362,37 @@ func main() {
 	if *Fetch {
 		cache.Next = http.DefaultTransport
 	}
+	cache.MaxAge = func(category string) time.Duration {
+		switch category {
+		case CacheCategoryListing:
+			return cmp.Or(*CacheMaxAgeListing, *CacheMaxAge)
+		case CacheCategoryFacility:
+			return cmp.Or(*CacheMaxAgeFacility, *CacheMaxAge)
+		case CacheCategoryGeocode:
+			return cmp.Or(*CacheMaxAgeGeocode, *CacheMaxAge)
+		case CacheCategoryActivity:
+			return cmp.Or(*CacheMaxAgeActivity, *CacheMaxAge)
+		}
+		return *CacheMaxAge
+	}
+	if len(*CacheRefresh) != 0 {
+		refresh := map[string]bool{}
+		for _, x := range *CacheRefresh {
+			u, err := url.Parse(x)
+			if err != nil {
+				return fmt.Errorf("parse refresh url: %w", err)
+			}
+			refresh[u.String()] = true
+		}
+		cache.Refresh = func(r *http.Request) bool {
+			return refresh[r.URL.String()]
+		}
+	}
 	http.DefaultTransport = cache
+	fetchCache = cache
+
+	// don't cache requests for publishing the data
+	publish := uncached
 
 	// add secrets
 	if ScraperSecret != "" {
@@ -171,25 +406,52 @@ func main() {
 		redactor.RedactRequestHeader(header, 4)
 	}
 
+	// add custom headers
+	for _, x := range slices.Backward(*Header) { // later ones take precedence
+		domain, header := cutDomain(x)
+		name, value, ok := strings.Cut(header, ":")
+		if name = strings.TrimSpace(name); !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return fmt.Errorf("invalid header %q", x)
+		}
+		http.DefaultTransport = headerRoundTripper(http.DefaultTransport, domain, name, strings.TrimSpace(value))
+	}
+
 	// add user agent
-	if ua := defaultUserAgent(); ua != "" {
+	ua := defaultUserAgent()
+	for _, x := range *UserAgent {
+		if domain, value := cutDomain(x); domain == "" {
+			ua = value
+		}
+	}
+	for _, x := range slices.Backward(*UserAgent) { // later ones take precedence
+		if domain, value := cutDomain(x); domain != "" {
+			http.DefaultTransport = headerRoundTripper(http.DefaultTransport, domain, "User-Agent", value)
+		}
+	}
+	if ua != "" {
 		http.DefaultTransport = headerRoundTripper(http.DefaultTransport, "", "User-Agent", ua)
+		publish = headerRoundTripper(publish, "", "User-Agent", ua)
 	}
 
-	// set up the default http client
+	// set up the http clients
+	publishClient = &http.Client{Transport: publish}
 	http.DefaultClient.Transport = http.DefaultTransport
 	http.DefaultClient.Jar, _ = cookiejar.New(nil)
-
-	if err := run(context.Background()); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	return nil
 }
 
+// fetchChannelHeader is set on fetched responses to record how they were
+// fetched. It is persisted in the cache.
+const fetchChannelHeader = "X-Ottrec-Channel"
+
+// defaultPlaceListing is the place listing scraped if no others are specified.
+const defaultPlaceListing = "https://ottawa.ca/en/recreation-and-parks/facilities/place-listing"
+
 const (
 	CacheCategoryListing  = "listing"
 	CacheCategoryFacility = "facility"
 	CacheCategoryGeocode  = "geocode"
+	CacheCategoryActivity = "activity"
 )
 
 func run(ctx context.Context) error {
@@ -214,10 +476,17 @@ func run(ctx context.Context) error {
 			slog.Info("purging cached geocoding data")
 			purge = append(purge, CacheCategoryGeocode)
 		}
+		if *CachePurgeActivity {
+			slog.Info("purging cached activity pages")
+			purge = append(purge, CacheCategoryActivity)
+		}
 		if err := httpcache.Purge(*Cache, purge...); err != nil {
 			return fmt.Errorf("purge cache: %w", err)
 		}
 	}
+	if len(*Input) != 0 {
+		return reprocess(ctx, *Input)
+	}
 	if *Fetch {
 		slog.Info("will fetch data", "ua", defaultUserAgent())
 	} else {
@@ -227,7 +496,7 @@ func run(ctx context.Context) error {
 		slog.Info("only using cached data")
 	}
 	if *Scrape {
-		slog.Info("will parse data")
+		slog.Info("will parse data", "strict", *Strict)
 	} else {
 		slog.Info("will not parse data")
 	}
@@ -238,155 +507,313 @@ func run(ctx context.Context) error {
 	}
 	if *FetchZyte > 0 {
 		slog.Info("will fetch data using zyte", "limit", *FetchZyte)
+	} else if proxy, err := parseProxy(*FetchProxy); err == nil && proxy != nil {
+		slog.Info("will fetch data using proxy", "proxy", proxy.Redacted())
 	} else if ScraperSecret != "" {
 		slog.Info("will fetch data using scraper secret")
 	}
 	var (
 		data       schema.Data_builder
+		started    = time.Now()
 		geoAttrib  = map[string]struct{}{}
-		listing    = "https://ottawa.ca/en/recreation-and-parks/facilities/place-listing"
-		cur        = listing
+		listings   = *PlaceListing
+		seen       = map[string]bool{}
 		facilities int
+		strict     int
+		activities = map[string][]string{} // [url][]name
 	)
-	for cur != "" {
-		doc, _, err := fetchPage(ctx, CacheCategoryListing, cur)
-		if err != nil {
-			return err
-		}
-
-		content, err := scrapeMainContentBlock(doc)
-		if err != nil {
-			return err
-		}
-
-		nextURL, err := scrapePagerNext(doc, content)
-		if err != nil {
-			return err
-		}
+	if len(listings) == 0 {
+		listings = []string{defaultPlaceListing}
+	}
+	data.SchemaVersion = schema.SchemaVersion
+	for _, listing := range listings {
+		for cur := listing; cur != ""; {
+			doc, info, err := fetchPage(ctx, CacheCategoryListing, cur)
+			if err != nil {
+				return err
+			}
 
-		if err := scrapePlaceListings(doc, content, func(u *url.URL, name, address string) error {
-			var facility schema.Facility_builder
-			facility.Name = name
-			facility.Address = address
-			facility.Source = schema.Source_builder{
-				Url: u.String(),
-			}.Build()
-			facilities++
-
-			if !*Geocodio {
-				// skip geocoding
-			} else if lng, lat, attrib, hasLngLat, err := geocode(ctx, address); err != nil {
-				slog.Warn("failed to geocode place", "name", name, "address", address, "error", err)
-				facility.XErrors = append(facility.XErrors, fmt.Sprintf("failed to resolve address: %v", err))
-			} else if hasLngLat {
-				facility.XLnglat = schema.LngLat_builder{
-					Lat: float32(lat),
-					Lng: float32(lng),
-				}.Build()
-				if attrib != "" {
-					geoAttrib[attrib] = struct{}{}
+			if *Scrape {
+				for _, alert := range scrapeAlerts(doc) {
+					if !slices.ContainsFunc(data.Alerts, func(a *schema.Alert) bool {
+						return a.GetHtml() == alert.GetHtml()
+					}) {
+						setSourceInfo(alert.GetSource(), info)
+						data.Alerts = append(data.Alerts, alert)
+					}
 				}
 			}
 
-			doc, date, err := fetchPage(ctx, CacheCategoryFacility, u.String())
+			content, err := scrapeMainContentBlock(doc)
 			if err != nil {
-				slog.Warn("failed to fetch place", "name", name, "error", err)
-				facility.XErrors = append(facility.XErrors, fmt.Sprintf("failed to fetch data: %v", err))
-				data.Facilities = append(data.Facilities, facility.Build())
-				return nil
-			} else {
-				slog.Info("got place", "name", name)
-			}
-			if !date.IsZero() {
-				facility.Source.SetXDate(timestamppb.New(date))
+				return err
 			}
-			if !*Scrape {
-				return nil
+
+			nextURL, err := scrapePagerNext(doc, content)
+			if err != nil {
+				return err
 			}
-			if err := func() error {
-				content, err := scrapeMainContentBlock(doc)
-				if err != nil {
-					if tmp, err := url.Parse(cur); err == nil && !strings.EqualFold(doc.Url.Hostname(), tmp.Hostname()) {
-						return fmt.Errorf("facility page %q is not a City of Ottawa webpage", doc.Url)
-					}
-					return err
-				}
 
-				node, err := findOne(content, `.node.node--type-place`, "place node")
-				if err != nil {
-					return err
+			if err := scrapePlaceListings(doc, content, func(u *url.URL, name, address string) error {
+				if seen[u.String()] {
+					slog.Debug("skipping facility found via multiple listings", "name", name, "url", u)
+					return nil
 				}
+				seen[u.String()] = true
 
-				if field, err := scrapeNodeField(node, "description", "text-long", false, true); err != nil {
-					facility.XErrors = append(facility.XErrors, fmt.Sprintf("extract facility description: %v", err))
-				} else {
-					facility.Description = strings.Join(strings.Fields(field.Text()), " ")
-				}
+				var facility schema.Facility_builder
+				facility.Name = name
+				facility.Address = address
+				facility.Source = schema.Source_builder{
+					Url: u.String(),
+				}.Build()
+				facility.XId = schema.FacilityID(u.String())
+				facilities++
 
-				if field, err := scrapeNodeField(node, "notification-details", "text-long", false, true); err != nil {
-					facility.XErrors = append(facility.XErrors, fmt.Sprintf("extract facility notifications: %v", err))
-				} else if raw, err := field.Html(); err != nil {
-					facility.XErrors = append(facility.XErrors, fmt.Sprintf("extract facility notifications: %v", err))
+				doc, info, err := fetchPage(ctx, CacheCategoryFacility, u.String())
+				setSourceInfo(facility.Source, info)
+				if err != nil {
+					slog.Warn("failed to fetch place", "name", name, "error", err)
+					facility.XDiagnostics = append(facility.XDiagnostics, schema.DiagError(schema.Diagnostic_FETCH, "", "failed to fetch data: %v", err))
+					data.Facilities = append(data.Facilities, facility.Build())
+					return nil
 				} else {
-					facility.NotificationsHtml = raw
+					slog.Info("got place", "name", name)
 				}
-
-				if field, err := scrapeNodeField(node, "hours-details", "text-long", false, true); err != nil {
-					facility.XErrors = append(facility.XErrors, fmt.Sprintf("extract facility notifications: %v", err))
-				} else if raw, err := field.Html(); err != nil {
-					facility.XErrors = append(facility.XErrors, fmt.Sprintf("extract facility notifications: %v", err))
-				} else {
-					facility.SpecialHoursHtml = raw
+				if lang := pageLanguage(doc); lang != "" {
+					facility.Source.SetXLang(lang)
+					if exp := urlLanguage(listing); exp != "" && lang != exp {
+						slog.Warn("facility page language mismatch", "name", name, "url", doc.Url, "lang", lang, "expected", exp)
+						facility.XDiagnostics = append(facility.XDiagnostics, schema.DiagWarning(schema.Diagnostic_FETCH, "", "facility page %q is in language %q, expected %q", doc.Url, lang, exp))
+					}
+				}
+				for u, names := range scrapeActivityLinks(doc) {
+					for _, name := range names {
+						if !slices.Contains(activities[u], name) {
+							activities[u] = append(activities[u], name)
+						}
+					}
 				}
+				if !*Scrape {
+					data.Facilities = append(data.Facilities, facility.Build()) // still needed for geocoding
+					return nil
+				}
+				if err := func() error {
+					content, err := scrapeMainContentBlock(doc)
+					if err != nil {
+						if tmp, err := url.Parse(cur); err == nil && !strings.EqualFold(doc.Url.Hostname(), tmp.Hostname()) {
+							return fmt.Errorf("facility page %q is not a City of Ottawa webpage", doc.Url)
+						}
+						return err
+					}
+
+					node, err := findOne(content, `.node.node--type-place`, "place node")
+					if err != nil {
+						return err
+					}
+
+					if field, err := scrapeNodeField(node, "description", "text-long", false, true); err != nil {
+						facility.XDiagnostics = append(facility.XDiagnostics, schema.DiagError(schema.Diagnostic_PARSE, "description", "extract facility description: %v", err))
+					} else {
+						facility.Description = strings.Join(strings.Fields(field.Text()), " ")
+					}
 
-				if err := scrapeCollapseSections(node, func(label string, content *goquery.Selection) error {
-					if !strings.Contains(label, "drop-in") && !strings.Contains(label, "schedule") && content.Find(`a[href*="reservation.frontdesksuite"],p:contains("schedules listed in the charts below"),th:contains("Monday")`).Length() == 0 {
-						return nil // probably not a schedule group
+					if field, err := scrapeNodeField(node, "notification-details", "text-long", false, true); err != nil {
+						facility.XDiagnostics = append(facility.XDiagnostics, schema.DiagError(schema.Diagnostic_PARSE, "notification-details", "extract facility notifications: %v", err))
+					} else if raw, err := field.Html(); err != nil {
+						facility.XDiagnostics = append(facility.XDiagnostics, schema.DiagError(schema.Diagnostic_PARSE, "notification-details", "extract facility notifications: %v", err))
+					} else {
+						facility.NotificationsHtml = raw
+						if safe, err := sanitizeHTML(doc, field); err != nil {
+							facility.XDiagnostics = append(facility.XDiagnostics, schema.DiagWarning(schema.Diagnostic_PARSE, "notification-details", "sanitize facility notifications: %v", err))
+						} else {
+							facility.XNotificationsHtml = safe
+						}
 					}
-					group, xerrs := scrapeScheduleGroup(doc, facility.Name, label, content)
-					facility.XErrors = append(facility.XErrors, xerrs...)
-					facility.ScheduleGroups = append(facility.ScheduleGroups, group)
+
+					if field, err := scrapeNodeField(node, "hours-details", "text-long", false, true); err != nil {
+						facility.XDiagnostics = append(facility.XDiagnostics, schema.DiagError(schema.Diagnostic_PARSE, "hours-details", "extract facility special hours: %v", err))
+					} else if raw, err := field.Html(); err != nil {
+						facility.XDiagnostics = append(facility.XDiagnostics, schema.DiagError(schema.Diagnostic_PARSE, "hours-details", "extract facility special hours: %v", err))
+					} else {
+						facility.SpecialHoursHtml = raw
+						if safe, err := sanitizeHTML(doc, field); err != nil {
+							facility.XDiagnostics = append(facility.XDiagnostics, schema.DiagWarning(schema.Diagnostic_PARSE, "hours-details", "sanitize facility special hours: %v", err))
+						} else {
+							facility.XSpecialHoursHtml = safe
+						}
+					}
+
+					if err := scrapeCollapseSections(node, func(label string, content *goquery.Selection) error {
+						if !strings.Contains(label, "drop-in") && !strings.Contains(label, "schedule") && content.Find(`a[href*="reservation.frontdesksuite"],p:contains("schedules listed in the charts below"),th:contains("Monday")`).Length() == 0 {
+							return nil // probably not a schedule group
+						}
+						group, diags := scrapeScheduleGroup(doc, facility.Name, label, content, info.Date)
+						facility.XDiagnostics = append(facility.XDiagnostics, diags...)
+						facility.ScheduleGroups = append(facility.ScheduleGroups, group)
+						return nil
+					}); err != nil {
+						return err
+					}
+
 					return nil
-				}); err != nil {
-					return err
+				}(); err != nil {
+					facility.XDiagnostics = append(facility.XDiagnostics, schema.DiagError(schema.Diagnostic_PARSE, "", "failed to extract facility information: %v", err))
+				}
+				if *Strict {
+					if n := strictDiagnostics(facility.XDiagnostics); n != 0 {
+						slog.Error("strict: facility has parse warnings", "name", name, "count", n)
+						strict++
+					}
 				}
 
+				data.Facilities = append(data.Facilities, facility.Build())
 				return nil
-			}(); err != nil {
-				facility.XErrors = append(facility.XErrors, fmt.Sprintf("failed to extract facility information: %v", err))
+			}); err != nil {
+				return err
 			}
 
-			data.Facilities = append(data.Facilities, facility.Build())
-			return nil
-		}); err != nil {
-			return err
+			if nextURL == nil {
+				break
+			}
+			cur = nextURL.String()
 		}
-
-		if nextURL == nil {
-			break
+	}
+	if *Geocodio {
+		for _, attrib := range geocodeFacilities(ctx, data.Facilities) {
+			geoAttrib[attrib] = struct{}{}
 		}
-		cur = nextURL.String()
 	}
 	if facilities < 100 {
 		return fmt.Errorf("less than 100 facilities returned, something might be wrong")
 	}
+	// the activity pages are only found via links in schedules since the
+	// catalog is keyed by the normalized activity names they're linked from
+	for _, u := range slices.Sorted(maps.Keys(activities)) {
+		var info schema.ActivityInfo_builder
+		info.Source = schema.Source_builder{
+			Url: u,
+		}.Build()
+
+		doc, pinfo, err := fetchPage(ctx, CacheCategoryActivity, u)
+		setSourceInfo(info.Source, pinfo)
+		if err != nil {
+			slog.Warn("failed to fetch activity page", "url", u, "error", err)
+			info.XDiagnostics = append(info.XDiagnostics, schema.DiagError(schema.Diagnostic_FETCH, "", "failed to fetch data: %v", err))
+		} else if *Scrape {
+			if title, desc, err := scrapeActivityPage(doc); err != nil {
+				info.XDiagnostics = append(info.XDiagnostics, schema.DiagError(schema.Diagnostic_PARSE, "", "failed to extract activity information: %v", err))
+			} else {
+				info.Title = title
+				info.Description = desc
+			}
+		}
+		for _, name := range activities[u] {
+			info.XName = name
+			data.Activities = append(data.Activities, info.Build())
+		}
+	}
+	slices.SortStableFunc(data.Activities, func(a, b *schema.ActivityInfo) int {
+		return strings.Compare(a.GetXName(), b.GetXName())
+	})
 	if *Scrape {
 		data.Attribution = append(data.Attribution, "Compiled data © Patrick Gaskin. https://github.com/pgaskin/ottrec")
-		data.Attribution = append(data.Attribution, "Facility information and schedules © City of Ottawa. "+listing)
+		data.Attribution = append(data.Attribution, "Facility information and schedules © City of Ottawa. "+listings[0])
 		for _, attrib := range slices.Sorted(maps.Keys(geoAttrib)) {
 			data.Attribution = append(data.Attribution, "Address data "+strings.TrimPrefix(attrib, "Data "))
 		}
-		if err := export(data.Build()); err != nil {
+		if n := len(data.Facilities); n != 0 {
+			data.Facilities = dedupeFacilities(data.Facilities)
+			if d := n - len(data.Facilities); d != 0 {
+				slog.Info("merged duplicate facilities", "count", d)
+			}
+		}
+		pb := data.Build()
+		meta := schema.Meta_builder{
+			ScraperVersion: defaultUserAgent(),
+			RunId:          runID(),
+			Started:        timestamppb.New(started),
+			Finished:       timestamppb.New(time.Now()),
+			ListingUrls:    listings,
+		}.Build()
+		meta.SetCounts(pb)
+		pb.SetXMeta(meta)
+		if name := *CrossCheck; name != "" {
+			other, err := loadData(name)
+			if err != nil {
+				return fmt.Errorf("crosscheck: load %q: %w", name, err)
+			}
+			if *CrossCheckMerge {
+				merged, linked := mergeLanguages(pb, other)
+				for _, u := range other.GetXMeta().GetListingUrls() {
+					if !slices.Contains(meta.GetListingUrls(), u) {
+						meta.SetListingUrls(append(meta.GetListingUrls(), u))
+					}
+				}
+				merged.SetXMeta(meta)
+				slog.Info("merged data with other language", "name", name, "facilities", len(merged.GetFacilities()), "linked", linked)
+				pb = merged
+			} else {
+				linked := crossCheck(pb, other)
+				slog.Info("cross-checked data", "name", name, "facilities", len(pb.GetFacilities()), "linked", linked)
+			}
+		}
+		if err := export(ctx, pb); err != nil {
 			return fmt.Errorf("export: %w", err)
 		}
+		if strict != 0 {
+			return fmt.Errorf("strict: %d facilities have parse warnings", strict)
+		}
 	}
 	return nil
 }
 
-func export(pb *schema.Data) error {
+// exportJSONOptions is used for the json export and formats derived from it.
+var exportJSONOptions = protojson.MarshalOptions{
+	EmitUnpopulated:   true,
+	EmitDefaultValues: true,
+	Multiline:         false,
+	AllowPartial:      false,
+	UseEnumNumbers:    true,
+	UseProtoNames:     false,
+}
+
+func export(ctx context.Context, pb *schema.Data) error {
+	loc, err := exportLocation()
+	if err != nil {
+		return fmt.Errorf("tz: %w", err)
+	}
+	if exportFilter != nil {
+		n := len(pb.GetFacilities())
+		pb = exportFilter.Apply(pb)
+		slog.Info("filtered data", "facilities", len(pb.GetFacilities()), "total", n)
+	}
+	if *ExportLite {
+		pb = schema.Lightweight(pb)
+	}
+	if *ExportCanonical {
+		pb = schema.DeepClone(pb)
+		schema.Canonicalize(pb)
+	}
+	if meta := pb.GetXMeta(); meta != nil {
+		meta = proto.CloneOf(meta)
+		meta.SetCounts(pb)
+		if *Reproducible {
+			meta.SetRunId("")
+			meta.ClearStarted()
+			meta.ClearFinished()
+		}
+		pb = proto.CloneOf(pb)
+		pb.SetXMeta(meta)
+	}
+	now := time.Now()
+	if *Reproducible {
+		now = dataUpdated(pb)
+	}
+	now = now.In(loc)
 	if name := *ExportProto; name != "" {
 		slog.Info("exporting proto", "name", name)
-		if err := os.WriteFile(name, []byte(schema.Proto()), 0644); err != nil {
+		if err := writeExport(name, []byte(schema.Proto())); err != nil {
 			return fmt.Errorf("proto: write: %w", err)
 		}
 	}
@@ -396,10 +823,30 @@ func export(pb *schema.Data) error {
 			Deterministic: true,
 		}).Marshal(pb); err != nil {
 			return fmt.Errorf("binpb: marshal: %w", err)
-		} else if err := os.WriteFile(name, buf, 0644); err != nil {
+		} else if err := writeExport(name, buf); err != nil {
 			return fmt.Errorf("binpb: write: %w", err)
 		}
 	}
+	if name := *ExportPBRaw; name != "" {
+		slog.Info("exporting raw binpb", "name", name)
+		if buf, err := (proto.MarshalOptions{
+			Deterministic: true,
+		}).Marshal(schema.StripDerived(pb)); err != nil {
+			return fmt.Errorf("binpb raw: marshal: %w", err)
+		} else if err := writeExport(name, buf); err != nil {
+			return fmt.Errorf("binpb raw: write: %w", err)
+		}
+	}
+	if name := *ExportDesc; name != "" {
+		slog.Info("exporting descriptor set", "name", name)
+		if buf, err := (proto.MarshalOptions{
+			Deterministic: true,
+		}).Marshal(schema.Descriptor()); err != nil {
+			return fmt.Errorf("desc: marshal: %w", err)
+		} else if err := writeExport(name, buf); err != nil {
+			return fmt.Errorf("desc: write: %w", err)
+		}
+	}
 	if name, pretty := *ExportTextPB, *ExportPretty; name != "" {
 		slog.Info("exporting textpb", "name", name, "pretty", pretty)
 		opt := prototext.MarshalOptions{
@@ -423,40 +870,269 @@ func export(pb *schema.Data) error {
 			if err != nil {
 				return fmt.Errorf("textpb: format: %w", err)
 			}
+		} else if *Reproducible {
+			if buf, err = textpbfmt.FormatWithConfig(buf, textpbfmt.Config{}); err != nil {
+				return fmt.Errorf("textpb: format: %w", err)
+			}
 		}
-		if err := os.WriteFile(name, buf, 0644); err != nil {
+		if err := writeExport(name, buf); err != nil {
 			return fmt.Errorf("textpb: write: %w", err)
 		}
 	}
 	if name, pretty := *ExportJSON, *ExportPretty; name != "" {
 		slog.Info("exporting json", "name", name, "pretty", pretty)
-		opt := protojson.MarshalOptions{
-			EmitUnpopulated:   true,
-			EmitDefaultValues: true,
-			Multiline:         false,
-			AllowPartial:      false,
-			UseEnumNumbers:    true,
-			UseProtoNames:     false,
-		}
-		buf, err := opt.Marshal(pb)
+		buf, err := exportJSONOptions.Marshal(pb)
 		if err != nil {
 			return fmt.Errorf("json: marshal: %w", err)
 		}
-		if *ExportPretty {
+		if *Reproducible {
+			if buf, err = canonicalJSON(buf, pretty); err != nil {
+				return fmt.Errorf("json: format: %w", err)
+			}
+		} else if pretty {
 			var buf1 bytes.Buffer
 			if err := json.Indent(&buf1, buf, "", "  "); err != nil {
 				return fmt.Errorf("json: format: %w", err)
 			}
 			buf = buf1.Bytes()
 		}
-		if err := os.WriteFile(name, buf, 0644); err != nil {
+		if err := writeExport(name, buf); err != nil {
 			return fmt.Errorf("json: write: %w", err)
 		}
 	}
+	if name := *ExportJSONCompact; name != "" {
+		slog.Info("exporting compact json", "name", name)
+		buf, err := exportCompactJSON(pb)
+		if err != nil {
+			return fmt.Errorf("compact json: marshal: %w", err)
+		}
+		if err := writeExport(name, buf); err != nil {
+			return fmt.Errorf("compact json: write: %w", err)
+		}
+	}
+	if name := *ExportCBOR; name != "" {
+		slog.Info("exporting cbor", "name", name)
+		buf, err := exportJSONOptions.Marshal(pb)
+		if err != nil {
+			return fmt.Errorf("cbor: marshal: %w", err)
+		}
+		if buf, err = jsonToCBOR(buf); err != nil {
+			return fmt.Errorf("cbor: marshal: %w", err)
+		}
+		if err := writeExport(name, buf); err != nil {
+			return fmt.Errorf("cbor: write: %w", err)
+		}
+	}
+	if name := *ExportXLSX; name != "" {
+		slog.Info("exporting xlsx", "name", name)
+		buf, err := exportXLSX(pb)
+		if err != nil {
+			return fmt.Errorf("xlsx: marshal: %w", err)
+		}
+		if err := writeExport(name, buf); err != nil {
+			return fmt.Errorf("xlsx: write: %w", err)
+		}
+	}
+	if name, jname := *ExportStats, *ExportStatsJSON; name != "" || jname != "" {
+		st := computeStats(pb)
+		if name != "" {
+			slog.Info("exporting stats", "name", name)
+			if err := writeExport(name, []byte(st.String())); err != nil {
+				return fmt.Errorf("stats: write: %w", err)
+			}
+		}
+		if jname != "" {
+			slog.Info("exporting stats json", "name", jname)
+			buf, err := json.MarshalIndent(st, "", "  ")
+			if err != nil {
+				return fmt.Errorf("stats: marshal: %w", err)
+			}
+			if err := writeExport(jname, append(buf, '\n')); err != nil {
+				return fmt.Errorf("stats: write: %w", err)
+			}
+		}
+	}
+	if name := *ExportNDJSON; name != "" {
+		slog.Info("exporting ndjson", "name", name)
+		var buf bytes.Buffer
+		if err := exportNDJSON(&buf, pb); err != nil {
+			return fmt.Errorf("ndjson: marshal: %w", err)
+		}
+		if err := writeExport(name, buf.Bytes()); err != nil {
+			return fmt.Errorf("ndjson: write: %w", err)
+		}
+	}
+	if name := *ExportPivot; name != "" {
+		slog.Info("exporting pivot csv", "name", name)
+		var buf bytes.Buffer
+		if err := exportPivotCSV(&buf, pb); err != nil {
+			return fmt.Errorf("pivot: marshal: %w", err)
+		}
+		if err := writeExport(name, buf.Bytes()); err != nil {
+			return fmt.Errorf("pivot: write: %w", err)
+		}
+	}
+	if name := *ExportHTML; name != "" {
+		slog.Info("exporting html", "name", name)
+		buf, err := exportHTML(pb)
+		if err != nil {
+			return fmt.Errorf("html: marshal: %w", err)
+		}
+		if err := writeExport(name, buf); err != nil {
+			return fmt.Errorf("html: write: %w", err)
+		}
+	}
+	if name := *ExportSite; name != "" {
+		slog.Info("exporting site", "name", name)
+		if err := exportSite(pb, name); err != nil {
+			return fmt.Errorf("site: %w", err)
+		}
+	}
+	if name := *ExportJSONLD; name != "" {
+		slog.Info("exporting json-ld", "name", name)
+		buf, err := exportJSONLD(pb, loc)
+		if err != nil {
+			return fmt.Errorf("json-ld: marshal: %w", err)
+		}
+		if err := writeExport(name, buf); err != nil {
+			return fmt.Errorf("json-ld: write: %w", err)
+		}
+	}
+	if name := *ExportGCal; name != "" {
+		from, to, err := expandRange(now)
+		if err != nil {
+			return fmt.Errorf("gcal: %w", err)
+		}
+		slog.Info("exporting google calendar csv", "name", name, "from", from.Format(time.DateOnly), "to", to.Format(time.DateOnly))
+		var buf bytes.Buffer
+		if err := exportGCal(&buf, pb, from, to, loc); err != nil {
+			return fmt.Errorf("gcal: marshal: %w", err)
+		}
+		if err := writeExport(name, buf.Bytes()); err != nil {
+			return fmt.Errorf("gcal: write: %w", err)
+		}
+	}
+	if name := *ExportKML; name != "" {
+		slog.Info("exporting kml", "name", name)
+		buf, err := exportKML(pb, now)
+		if err != nil {
+			return fmt.Errorf("kml: marshal: %w", err)
+		}
+		if err := writeExport(name, buf); err != nil {
+			return fmt.Errorf("kml: write: %w", err)
+		}
+	}
+	if id := *Sheets; id != "" {
+		if err := pushSheets(ctx, *SheetsCredentials, id, *SheetsSheet, slices.Collect(pivotRows(pb))); err != nil {
+			return fmt.Errorf("sheets: %w", err)
+		}
+	}
+	files := exportFiles()
+	if *ExportAttribution {
+		var dirs []string
+		for _, f := range files {
+			dirs = append(dirs, filepath.Dir(f.Name))
+		}
+		if name := *ExportSite; name != "" {
+			dirs = append(dirs, name)
+		}
+		slog.Info("exporting attribution", "dirs", dirs)
+		written, err := writeAttribution(pb, dirs)
+		if err != nil {
+			return fmt.Errorf("attribution: write: %w", err)
+		}
+		for _, name := range written {
+			files = append(files, exportFile{name, "attribution"})
+		}
+	}
+	if name := *ExportDatapackage; name != "" {
+		slog.Info("exporting datapackage", "name", name)
+		buf, err := exportDatapackage(filepath.Dir(name), pb, files)
+		if err != nil {
+			return fmt.Errorf("datapackage: marshal: %w", err)
+		}
+		if err := writeExport(name, buf); err != nil {
+			return fmt.Errorf("datapackage: write: %w", err)
+		}
+		if name != "-" {
+			files = append(files, exportFile{name, "datapackage"})
+		}
+	}
+	if name := *ExportManifest; name != "" {
+		slog.Info("exporting manifest", "name", name)
+		if err := writeManifest(name, pb, files, *ExportSite); err != nil {
+			return fmt.Errorf("manifest: %w", err)
+		}
+		if name != "-" {
+			files = append(files, exportFile{name, "manifest"})
+		}
+	}
+	if dst := *Upload; dst != "" {
+		if err := uploadExports(ctx, dst, *UploadCacheControl, files, *ExportSite); err != nil {
+			return fmt.Errorf("upload: %w", err)
+		}
+	}
 	return nil
 }
 
-// geocode geocodes an address using geocodio.
+// exportFile is a file written by export.
+type exportFile struct {
+	Name   string
+	Format string // the export flag name without the prefix
+}
+
+// exportFiles returns the files written by export, excluding stdout, the site
+// directory, and the manifest.
+func exportFiles() []exportFile {
+	var files []exportFile
+	flag.VisitAll(func(f *flag.Flag) {
+		format, ok := strings.CutPrefix(f.Name, "export.")
+		if !ok || format == "site" || format == "datapackage" || format == "manifest" {
+			return
+		}
+		if name, ok := f.Value.(flag.Getter).Get().(string); ok && name != "" && name != "-" {
+			files = append(files, exportFile{name, format})
+		}
+	})
+	return files
+}
+
+// exportLocation loads the -tz timezone.
+func exportLocation() (*time.Location, error) {
+	return time.LoadLocation(*TZ)
+}
+
+// expandRange parses -expand.from and -expand.to.
+func expandRange(now time.Time) (from, to time.Time, err error) {
+	from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if *ExpandFrom != "" {
+		if from, err = time.Parse(time.DateOnly, *ExpandFrom); err != nil {
+			return from, to, fmt.Errorf("invalid from date: %w", err)
+		}
+	}
+	to = from.AddDate(0, 0, 27)
+	if *ExpandTo != "" {
+		if to, err = time.Parse(time.DateOnly, *ExpandTo); err != nil {
+			return from, to, fmt.Errorf("invalid to date: %w", err)
+		}
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("to date is before from date")
+	}
+	return from, to, nil
+}
+
+// writeExport writes buf to the named file, or stdout if name is "-".
+func writeExport(name string, buf []byte) error {
+	if name == "-" {
+		_, err := os.Stdout.Write(buf)
+		return err
+	}
+	return os.WriteFile(name, buf, 0644)
+}
+
+// geocode geocodes addresses using geocodio. Results are cached per-address,
+// and only uncached addresses are fetched, using the batch endpoint.
 //
 // As of 2025-09-16, geocodio works better than nominatim and
 // pelias/geocode.earth:
@@ -470,21 +1146,100 @@ func export(pb *schema.Data) error {
 //   - Pelias and Geocodio resolve all addresses successfully.
 //   - Pelias is better than Geocodio at choosing a point near the entrance instead of somewhere on the property.
 //   - For incorrect street names, Geocodio is better at resolving them based on the postal code, but Pelias just ignores the street and chooses somewhere seemingly random.
-func geocode(ctx context.Context, addr string) (lng, lat float64, attrib string, ok bool, err error) {
+func geocode(ctx context.Context, addrs []string) []geocodeResult {
+	res := make([]geocodeResult, len(addrs))
+	var missed []int
+	for i, addr := range addrs {
+		r, err := geocodeCached(ctx, addr)
+		if errors.Is(err, httpcache.ErrNotCached) {
+			missed = append(missed, i)
+			continue
+		}
+		if err != nil {
+			r.Err = err
+		}
+		res[i] = r
+	}
+	if len(missed) == 0 {
+		return res
+	}
+
+	batch := make([]string, len(missed))
+	for i, j := range missed {
+		batch[i] = addrs[j]
+	}
+	bres, err := geocodeBatch(ctx, batch)
+	for i, j := range missed {
+		if err != nil {
+			res[j].Err = err
+		} else {
+			res[j] = bres[i]
+		}
+	}
+	return res
+}
+
+// geocodeRequest returns a request for geocoding a single address. Batch
+// results are cached as responses to it.
+func geocodeRequest(ctx context.Context, addr string) (*http.Request, error) {
+	u := &url.URL{
+		Scheme: "https",
+		Host:   "api.geocod.io",
+		Path:   "/v1.9/geocode",
+		RawQuery: url.Values{
+			"q":       {addr},
+			"country": {"CA"},
+		}.Encode(),
+	}
+	return http.NewRequestWithContext(httpcache.CategoryContext(ctx, CacheCategoryGeocode), http.MethodGet, u.String(), nil)
+}
+
+// geocodeCached geocodes an address using a cached result.
+func geocodeCached(ctx context.Context, addr string) (geocodeResult, error) {
+	req, err := geocodeRequest(httpcache.NoFetchContext(ctx), addr)
+	if err != nil {
+		return geocodeResult{}, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return geocodeResult{}, err
+	}
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return geocodeResult{}, err
+	}
+	return parseGeocodeResponse(resp.StatusCode, buf), nil
+}
+
+// geocodeBatch geocodes addresses using the batch endpoint, caching the result
+// for each address.
+func geocodeBatch(ctx context.Context, addrs []string) ([]geocodeResult, error) {
 	u := &url.URL{
 		Scheme: "https",
 		Host:   "api.geocod.io",
 		Path:   "/v1.9/geocode",
 		RawQuery: url.Values{
-			"q":       {addr},
 			"country": {"CA"},
 		}.Encode(),
 	}
-	slog.Info("fetch geocodio", "url", u.String())
+	slog.Info("fetch geocodio", "url", u.String(), "count", len(addrs))
+
+	buf, err := json.Marshal(addrs)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(httpcache.NoStoreContext(httpcache.CategoryContext(ctx, CacheCategoryGeocode)), http.MethodPost, u.String(), bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := fetch(ctx, CacheCategoryGeocode, u.String())
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return 0, 0, "", false, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -493,12 +1248,54 @@ func geocode(ctx context.Context, addr string) (lng, lat float64, attrib string,
 			Error string
 		}
 		if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil || obj.Error == "" {
-			return 0, 0, "", false, fmt.Errorf("response status %d", resp.StatusCode)
+			return nil, fmt.Errorf("response status %d", resp.StatusCode)
+		}
+		return nil, fmt.Errorf("response status %d: geocodio error: %q", resp.StatusCode, obj.Error)
+	}
+
+	var obj struct {
+		Results []struct {
+			Query    string
+			Response json.RawMessage
+		}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		return nil, fmt.Errorf("decode geocodio response: %w", err)
+	}
+	if len(obj.Results) != len(addrs) {
+		return nil, fmt.Errorf("decode geocodio response: expected %d results, got %d", len(addrs), len(obj.Results))
+	}
+	res := make([]geocodeResult, len(addrs))
+	for i, x := range obj.Results {
+		res[i] = parseGeocodeResponse(http.StatusOK, x.Response)
+		if fetchCache != nil {
+			req, err := geocodeRequest(ctx, addrs[i])
+			if err != nil {
+				return nil, err
+			}
+			header := http.Header{"Content-Type": {"application/json"}}
+			if date := resp.Header.Get("Date"); date != "" {
+				header.Set("Date", date)
+			}
+			if err := fetchCache.Put(req, &http.Response{
+				StatusCode:    http.StatusOK,
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        header,
+				Body:          io.NopCloser(bytes.NewReader(x.Response)),
+				ContentLength: int64(len(x.Response)),
+			}); err != nil {
+				return nil, err
+			}
 		}
-		return 0, 0, "", false, fmt.Errorf("response status %d: geocodio error: %q", resp.StatusCode, obj.Error)
 	}
+	return res, nil
+}
 
+// parseGeocodeResponse parses a single-address geocodio response.
+func parseGeocodeResponse(status int, buf []byte) geocodeResult {
 	var obj struct {
+		Error   string
 		Results []struct {
 			Location struct {
 				Lat float64
@@ -507,45 +1304,176 @@ func geocode(ctx context.Context, addr string) (lng, lat float64, attrib string,
 			Source string
 		}
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
-		return 0, 0, "", false, fmt.Errorf("decode geocodio response: %w", err)
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		if status != http.StatusOK {
+			return geocodeResult{Err: fmt.Errorf("response status %d", status)}
+		}
+		return geocodeResult{Err: fmt.Errorf("decode geocodio response: %w", err)}
+	}
+	if obj.Error != "" {
+		return geocodeResult{Err: fmt.Errorf("geocodio error: %q", obj.Error)}
 	}
-	if len(obj.Results) != 0 {
-		r := obj.Results[0]
-		if r.Location.Lat == 0 || r.Location.Lng == 0 {
-			return 0, 0, "", false, fmt.Errorf("decode geocodio response: missing lng/lat")
+	if status != http.StatusOK {
+		return geocodeResult{Err: fmt.Errorf("response status %d", status)}
+	}
+	if len(obj.Results) == 0 {
+		return geocodeResult{}
+	}
+	r := obj.Results[0]
+	if r.Location.Lat == 0 || r.Location.Lng == 0 {
+		return geocodeResult{Err: fmt.Errorf("decode geocodio response: missing lng/lat")}
+	}
+	return geocodeResult{
+		Lng:    r.Location.Lng,
+		Lat:    r.Location.Lat,
+		Attrib: "via geocodio (" + r.Source + ")",
+		OK:     true,
+	}
+}
+
+// geocodeResult is the result of geocoding a single address.
+type geocodeResult struct {
+	Lng, Lat float64
+	Attrib   string
+	OK       bool  // false if no match was found
+	Err      error // geocoding failed for this address
+}
+
+// geocodeBatchSize is the maximum number of addresses geocoded per request.
+const geocodeBatchSize = 1000
+
+// geocodeFacilities geocodes the addresses of facilities in batches, setting
+// the coordinates or adding a diagnostic on failure, and returns the
+// attributions for the results.
+func geocodeFacilities(ctx context.Context, facilities []*schema.Facility) []string {
+	byAddr := map[string][]*schema.Facility{}
+	for _, f := range facilities {
+		if addr := f.GetAddress(); addr != "" {
+			byAddr[addr] = append(byAddr[addr], f)
+		}
+	}
+	attrib := map[string]struct{}{}
+	for addrs := range slices.Chunk(slices.Sorted(maps.Keys(byAddr)), geocodeBatchSize) {
+		for i, r := range geocode(ctx, addrs) {
+			for _, f := range byAddr[addrs[i]] {
+				if r.Err != nil {
+					slog.Warn("failed to geocode place", "name", f.GetName(), "address", addrs[i], "error", r.Err)
+					f.SetXDiagnostics(append(f.GetXDiagnostics(), schema.DiagError(schema.Diagnostic_GEOCODE, "", "failed to resolve address: %v", r.Err)))
+				} else if r.OK {
+					f.SetXLnglat(schema.LngLat_builder{
+						Lat: float32(r.Lat),
+						Lng: float32(r.Lng),
+					}.Build())
+					if r.Attrib != "" {
+						attrib[r.Attrib] = struct{}{}
+					}
+				}
+			}
 		}
-		return r.Location.Lng, r.Location.Lat, "via geocodio (" + r.Source + ")", true, nil
 	}
-	return 0, 0, "", false, nil
+	return slices.Sorted(maps.Keys(attrib))
 }
 
-func fetchPage(ctx context.Context, category, u string) (*goquery.Document, time.Time, error) {
+// pageInfo contains information about a fetched page.
+type pageInfo struct {
+	Date    time.Time
+	Status  int
+	Hash    string // hex sha256 of the response body
+	Cached  bool
+	Channel string
+	URL     string // final url after redirects
+}
+
+func fetchPage(ctx context.Context, category, u string) (*goquery.Document, pageInfo, error) {
 	slog.Info("fetch page", "url", u, "category", category)
 
+	var info pageInfo
 	resp, err := fetch(ctx, category, u)
+	if resp != nil {
+		info.Date, _ = time.Parse(http.TimeFormat, resp.Header.Get("Date"))
+		info.Status = resp.StatusCode
+		info.Cached = httpcache.Cached(resp)
+		info.Channel = resp.Header.Get(fetchChannelHeader)
+		if resp.Request != nil {
+			info.URL = resp.Request.URL.String()
+		}
+	}
 	if err != nil {
-		return nil, time.Time{}, err
+		return nil, info, err
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	hash := sha256.New()
+	doc, err := goquery.NewDocumentFromReader(io.TeeReader(resp.Body, hash))
 	if err != nil {
-		return nil, time.Time{}, err
+		return nil, info, err
 	}
 	doc.Url = resp.Request.URL
+	info.Hash = hex.EncodeToString(hash.Sum(nil))
 
 	if doc.Find(`#main-content, #ottux-header, meta[name='dcterms.title'], meta[content*='drupal']`).Length() == 0 {
 		if h, _ := doc.Html(); strings.Contains(h, "Pardon Our Interruption") || strings.Contains(h, "showBlockPage()") || strings.Contains(h, "Request unsuccessful. Incapsula incident ID: ") {
-			return nil, time.Time{}, fmt.Errorf("imperva blocked request")
+			return nil, info, fmt.Errorf("imperva blocked request")
 		}
-		return nil, time.Time{}, fmt.Errorf("page content not found, might be imperva")
+		return nil, info, fmt.Errorf("page content not found, might be imperva")
 	}
+	return doc, info, nil
+}
+
+// strictWarnings are the message prefixes of the parse warnings promoted to
+// errors by -strict. These mean the schedule times or dates are missing, unlike
+// the other warnings, which are about optional information or ambiguities.
+var strictWarnings = []string{
+	"failed to parse time range ",
+	"failed to parse weekday from header ",
+	"failed to parse date range ",
+}
+
+// strictDiagnostics promotes parse warnings matching strictWarnings to errors,
+// returning the number of diagnostics which were promoted.
+func strictDiagnostics(diags []*schema.Diagnostic) (n int) {
+	for _, d := range diags {
+		if d.GetStage() != schema.Diagnostic_PARSE || d.GetSeverity() != schema.Diagnostic_WARNING {
+			continue
+		}
+		if slices.ContainsFunc(strictWarnings, func(p string) bool {
+			return strings.HasPrefix(d.GetMessage(), p)
+		}) {
+			d.SetSeverity(schema.Diagnostic_ERROR)
+			n++
+		}
+	}
+	return n
+}
+
+// withContext prefixes the context of diagnostics with context.
+func withContext(context string, diags []*schema.Diagnostic) []*schema.Diagnostic {
+	for _, d := range diags {
+		if d.GetContext() == "" {
+			d.SetContext(context)
+		} else {
+			d.SetContext(context + " > " + d.GetContext())
+		}
+	}
+	return diags
+}
 
-	date, _ := time.Parse(http.TimeFormat, resp.Header.Get("Date"))
-	return doc, date, nil
+// setSourceInfo sets the source fields from info.
+func setSourceInfo(src *schema.Source, info pageInfo) {
+	if !info.Date.IsZero() {
+		src.SetXDate(timestamppb.New(info.Date))
+	}
+	src.SetXStatus(int32(info.Status))
+	src.SetXHash(info.Hash)
+	src.SetXCached(info.Cached)
+	src.SetXChannel(info.Channel)
+	if info.URL != "" && info.URL != src.GetUrl() {
+		src.SetXUrl(info.URL)
+	}
 }
 
+// fetch fetches u. If the response status is not 200, the response is returned
+// with a closed body along with the error.
 func fetch(ctx context.Context, category, u string) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(httpcache.CategoryContext(ctx, category), http.MethodGet, u, nil)
 	if err != nil {
@@ -556,11 +1484,57 @@ func fetch(ctx context.Context, category, u string) (*http.Response, error) {
 		return nil, err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("response status %d", resp.StatusCode)
+		resp.Body.Close()
+		return resp, fmt.Errorf("response status %d", resp.StatusCode)
 	}
 	return resp, nil
 }
 
+// pageLanguage detects the language of a City of Ottawa page using the html
+// lang attribute, the URL, or the page text (in that order), returning an empty
+// string if unknown.
+func pageLanguage(doc *goquery.Document) string {
+	if lang := doc.Find("html").AttrOr("lang", ""); lang != "" {
+		lang, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(lang)), "-")
+		if lang != "" {
+			return lang
+		}
+	}
+	if doc.Url != nil {
+		if lang := urlLanguage(doc.Url.String()); lang != "" {
+			return lang
+		}
+	}
+	var en, fr int
+	for w := range strings.FieldsSeq(normalizeText(doc.Find("body").Text(), false, true)) {
+		switch w {
+		case "the", "and", "of", "to", "is":
+			en++
+		case "le", "la", "les", "et", "des", "du", "est":
+			fr++
+		}
+	}
+	switch {
+	case en > 2*fr && en >= 10:
+		return "en"
+	case fr > 2*en && fr >= 10:
+		return "fr"
+	}
+	return ""
+}
+
+// urlLanguage returns the language of a City of Ottawa page based on the first
+// path component, returning an empty string if unknown.
+func urlLanguage(u string) string {
+	if x, err := url.Parse(u); err == nil {
+		switch lang, _, _ := strings.Cut(strings.TrimPrefix(x.Path, "/"), "/"); lang {
+		case "en", "fr":
+			return lang
+		}
+	}
+	return ""
+}
+
 // resolve resolves a href from against the document.
 func resolve(d *goquery.Document, href string) (*url.URL, error) {
 	var err error
@@ -710,6 +1684,123 @@ func scrapeCollapseSections(s *goquery.Selection, fn func(title string, content
 	return nil
 }
 
+// scrapeAlerts extracts site-wide alert banners from a City of Ottawa page.
+func scrapeAlerts(doc *goquery.Document) []*schema.Alert {
+	var alerts []*schema.Alert
+	for _, el := range doc.Find(`.sitewide-alert, .region-alerts .alert, .block-alerts .alert`).EachIter() {
+		if el.Closest(`#block-mainpagecontent`).Length() != 0 {
+			continue // not site-wide
+		}
+		if el.Find(`.sitewide-alert, .alert`).Length() != 0 {
+			continue // use the innermost one
+		}
+		tmp := el.Clone()
+		tmp.Find(`.visually-hidden`).Remove() // accessibility text
+
+		var alert schema.Alert_builder
+		alert.Title = normalizeText(tmp.Find(`h1,h2,h3,h4,h5,h6,strong`).First().Text(), false, false)
+		if raw, err := sanitizeHTML(doc, tmp); err != nil || strings.TrimSpace(tmp.Text()) == "" {
+			continue
+		} else {
+			alert.Html = strings.TrimSpace(raw)
+		}
+		if t, err := time.Parse(time.RFC3339, tmp.Find(`time[datetime]`).First().AttrOr("datetime", "")); err == nil {
+			alert.XDate = timestamppb.New(t)
+		}
+		alert.Source = schema.Source_builder{
+			Url: doc.Url.String(),
+		}.Build()
+		alerts = append(alerts, alert.Build())
+	}
+	return alerts
+}
+
+// sanitizeHTML returns the inner html of a copy of s with scripts, styles,
+// comments, and attributes other than basic semantic ones removed, and with
+// links resolved to absolute urls.
+func sanitizeHTML(doc *goquery.Document, s *goquery.Selection) (string, error) {
+	tmp := s.Clone()
+	tmp.Find(`script, style, noscript, template, iframe, object, embed, link, meta, form, input, button, select, textarea`).Remove()
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; {
+			next := c.NextSibling
+			switch c.Type {
+			case html.CommentNode:
+				n.RemoveChild(c)
+			case html.ElementNode:
+				attrs := c.Attr[:0]
+				for _, a := range c.Attr {
+					switch a.Key {
+					case "href", "src":
+						if u, err := resolve(doc, a.Val); err == nil && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "mailto" || u.Scheme == "tel") {
+							a.Val = u.String()
+							attrs = append(attrs, a)
+						}
+					case "alt", "title", "colspan", "rowspan", "scope", "headers", "datetime", "lang":
+						attrs = append(attrs, a)
+					}
+				}
+				c.Attr = attrs
+				walk(c)
+			}
+			c = next
+		}
+	}
+	for _, n := range tmp.Nodes {
+		walk(n)
+	}
+	return tmp.Html()
+}
+
+// scrapeActivityLinks finds links to other City of Ottawa pages in the activity
+// names of schedule tables within doc, returning the cleaned activity names
+// for each url.
+func scrapeActivityLinks(doc *goquery.Document) map[string][]string {
+	links := map[string][]string{}
+	for _, row := range doc.Find(`#block-mainpagecontent table tr`).EachIter() {
+		cell := row.Find("th,td").First()
+		name := cleanActivityName(cell.Text())
+		if name == "" {
+			continue
+		}
+		for _, a := range cell.Find("a[href]").EachIter() {
+			u, err := resolve(doc, a.AttrOr("href", ""))
+			if err != nil || !matchDomain(".ottawa.ca", u) {
+				continue
+			}
+			u.Fragment = ""
+			if !slices.Contains(links[u.String()], name) {
+				links[u.String()] = append(links[u.String()], name)
+			}
+		}
+	}
+	return links
+}
+
+// scrapeActivityPage extracts the title and description from a City of Ottawa
+// page describing an activity.
+func scrapeActivityPage(doc *goquery.Document) (title, desc string, err error) {
+	content, err := scrapeMainContentBlock(doc)
+	if err != nil {
+		return "", "", err
+	}
+	title = normalizeText(cmp.Or(
+		doc.Find(`meta[name='dcterms.title']`).AttrOr("content", ""),
+		content.Find("h1").First().Text(),
+		doc.Find("h1").First().Text(),
+	), false, false)
+	body := content.Find(".field--name-body")
+	if body.Length() == 0 {
+		body = content
+	}
+	desc = strings.Join(strings.Fields(body.Text()), " ")
+	if desc == "" {
+		return "", "", fmt.Errorf("no description found")
+	}
+	return title, desc, nil
+}
+
 // scrapeNodeField gets a node field, ensuring it is the expected type.
 func scrapeNodeField(s *goquery.Selection, name, typ string, array, optional bool) (*goquery.Selection, error) {
 	fields := s.Find(".field")
@@ -769,9 +1860,8 @@ func scrapeNodeField(s *goquery.Selection, name, typ string, array, optional boo
 }
 
 // scrapeScheduleGroup scrapes a schedule group collapse section, returning nil
-// on failure, and returning a slice of warnings/errors from parsing the
-// schedule.
-func scrapeScheduleGroup(doc *goquery.Document, facilityName, label string, content *goquery.Selection) (msg *schema.ScheduleGroup, xerrs []string) {
+// on failure, and returning a slice of diagnostics from parsing the schedule.
+func scrapeScheduleGroup(doc *goquery.Document, facilityName, label string, content *goquery.Selection, scraped time.Time) (msg *schema.ScheduleGroup, diags []*schema.Diagnostic) {
 	var group schema.ScheduleGroup_builder
 	group.Label = label
 	group.XTitle = extractScheduleGroupTitle(label)
@@ -782,14 +1872,24 @@ func scrapeScheduleGroup(doc *goquery.Document, facilityName, label string, cont
 		if sel := scheduleChangeH.Next(); sel.Is("ul") {
 			if raw, err := sel.Html(); err == nil {
 				group.ScheduleChangesHtml = "<ul>" + raw + "</ul>"
+				if safe, err := sanitizeHTML(doc, sel); err != nil {
+					diags = append(diags, schema.DiagWarning(schema.Diagnostic_PARSE, "schedule changes", "failed to sanitize schedule changes: %v", err))
+				} else {
+					group.XScheduleChangesHtml = "<ul>" + safe + "</ul>"
+				}
+				for _, li := range sel.Find("li").EachIter() {
+					if h, ok := schema.FindHoliday(normalizeText(li.Text(), false, false)); ok && !slices.Contains(group.XHolidays, h.Name) {
+						group.XHolidays = append(group.XHolidays, h.Name)
+					}
+				}
 			} else {
-				xerrs = append(xerrs, fmt.Sprintf("parse schedule changes for schedule group %q: %v", label, err))
+				diags = append(diags, schema.DiagError(schema.Diagnostic_PARSE, "schedule changes", "failed to parse schedule changes: %v", err))
 			}
 		} else {
-			xerrs = append(xerrs, fmt.Sprintf("parse schedule changes for schedule group %q: header is not followed by a list", label))
+			diags = append(diags, schema.DiagError(schema.Diagnostic_PARSE, "schedule changes", "failed to parse schedule changes: header is not followed by a list"))
 		}
 	} else if scheduleChangeH.Length() != 0 {
-		xerrs = append(xerrs, fmt.Sprintf("parse schedule changes for schedule group %q: multiple selector matches found", label))
+		diags = append(diags, schema.DiagError(schema.Diagnostic_PARSE, "schedule changes", "failed to parse schedule changes: multiple selector matches found"))
 	}
 
 	for _, btn := range content.Find(".btn").EachIter() {
@@ -807,9 +1907,9 @@ func scrapeScheduleGroup(doc *goquery.Document, facilityName, label string, cont
 
 		var burl string
 		if href := btn.AttrOr("href", ""); href == "" {
-			xerrs = append(xerrs, fmt.Sprintf("parse reservation button for schedule group %q: href is empty", group.Label))
+			diags = append(diags, schema.DiagError(schema.Diagnostic_PARSE, ".btn", "failed to parse reservation button: href is empty"))
 		} else if u, err := resolve(doc, href); err != nil {
-			xerrs = append(xerrs, fmt.Sprintf("parse reservation button for schedule group %q: failed to parse href: %v", group.Label, err))
+			diags = append(diags, schema.DiagError(schema.Diagnostic_PARSE, ".btn", "failed to parse reservation button: failed to parse href: %v", err))
 		} else {
 			burl = u.String()
 		}
@@ -832,7 +1932,7 @@ func scrapeScheduleGroup(doc *goquery.Document, facilityName, label string, cont
 			if req {
 				if len(group.ReservationLinks) == 0 {
 					slog.Warn("unexpected top-level reservation required text without reservation links")
-					xerrs = append(xerrs, "unexpected top-level reservation required text without reservation links")
+					diags = append(diags, schema.DiagWarning(schema.Diagnostic_PARSE, "", "unexpected top-level reservation required text without reservation links"))
 				}
 				continue
 			}
@@ -844,32 +1944,57 @@ func scrapeScheduleGroup(doc *goquery.Document, facilityName, label string, cont
 	}
 
 	for _, table := range content.Find("table").EachIter() {
-		schedule, xerrs := scrapeSchedule(table, facilityName)
+		schedule, sdiags := scrapeSchedule(doc, table, facilityName, scraped)
 		if schedule != nil {
 			group.Schedules = append(group.Schedules, schedule)
 		}
-		for _, xerr := range xerrs {
-			xerrs = append(xerrs, fmt.Sprintf("group %q: %s", group.Label, xerr))
+		diags = append(diags, sdiags...)
+	}
+	if n := len(group.Schedules); n != 0 {
+		var ddiags []*schema.Diagnostic
+		group.Schedules, ddiags = dedupeSchedules(group.Schedules)
+		if d := n - len(group.Schedules); d != 0 {
+			slog.Warn("removed duplicate schedules", "group", group.Label, "count", d)
 		}
+		diags = append(diags, ddiags...)
 	}
-	return group.Build(), xerrs
+	return group.Build(), withContext(fmt.Sprintf("group %q", group.Label), diags)
 }
 
 // scrapeSchedule scrapes a schedule table, returning nil on failure, and
-// returning a slice of warnings/errors from parsing the schedule.
-func scrapeSchedule(table *goquery.Selection, facilityName string) (msg *schema.Schedule, xerrs []string) {
+// returning a slice of diagnostics from parsing the schedule. If scraped is not
+// zero, it is used to resolve dates without a year.
+func scrapeSchedule(doc *goquery.Document, table *goquery.Selection, facilityName string, scraped time.Time) (msg *schema.Schedule, diags []*schema.Diagnostic) {
 	var schedule schema.Schedule_builder
 	schedule.Caption = normalizeText(table.Find("caption").First().Text(), false, false)
+	defer func() {
+		withContext(fmt.Sprintf("schedule %q", schedule.Caption), diags)
+	}()
 
 	// date range suffix
 	name, date, ok := cutDateRange(schedule.Caption)
 	if ok {
 		schedule.XDate = date
 		if r, ok := parseDateRange(date); ok {
+			if !scraped.IsZero() {
+				if x, ok := inferYear(r, scraped); ok {
+					r = x
+				} else {
+					diags = append(diags, schema.DiagWarning(schema.Diagnostic_PARSE, "caption", "ambiguous year for date range %q", date))
+				}
+			}
 			schedule.XFrom = ptrTo(int32(r.From))
 			schedule.XTo = ptrTo(int32(r.To))
 		} else {
-			xerrs = append(xerrs, fmt.Sprintf("schedule %q: failed to parse date range %q", schedule.Caption, date))
+			diags = append(diags, schema.DiagWarning(schema.Diagnostic_PARSE, "caption", "failed to parse date range %q", date))
+		}
+	} else if prefix, holiday, h, ok := cutHoliday(schedule.Caption); ok {
+		name = prefix
+		schedule.XDate = holiday
+		if !scraped.IsZero() {
+			d := h.Nearest(scraped)
+			schedule.XFrom = ptrTo(int32(d))
+			schedule.XTo = ptrTo(int32(d))
 		}
 	}
 	// " schedule" suffix
@@ -889,6 +2014,11 @@ func scrapeSchedule(table *goquery.Selection, facilityName string) (msg *schema.
 	name = strings.TrimLeft(name, " -")
 	schedule.XName = strings.TrimLeft(name, " -")
 
+	// holiday schedules
+	if h, ok := schema.FindHoliday(schedule.Caption); ok {
+		schedule.XHoliday = h.Name
+	}
+
 	// TODO: refactor
 	for _, row := range table.Find("tr").EachIter() {
 		cells := row.Find("th,td")
@@ -907,32 +2037,40 @@ func scrapeSchedule(table *goquery.Selection, facilityName string) (msg *schema.
 		} else {
 			var activity schema.Schedule_Activity_builder
 			if cells.Length() != len(schedule.Days)+1 {
-				xerrs = append(xerrs, fmt.Sprintf("failed to parse schedule %q: row size mismatch", schedule.Caption))
-				return nil, xerrs
+				diags = append(diags, schema.DiagError(schema.Diagnostic_PARSE, "", "failed to parse schedule: row size mismatch"))
+				return nil, diags
+			}
+			for _, a := range cells.Find("a[href]").EachIter() {
+				if u, err := resolve(doc, a.AttrOr("href", "")); err != nil {
+					diags = append(diags, schema.DiagWarning(schema.Diagnostic_PARSE, "a[href]", "failed to parse activity link %q: %v", a.AttrOr("href", ""), err))
+				} else if !slices.ContainsFunc(activity.Links, func(l *schema.Link) bool { return l.GetUrl() == u.String() }) {
+					activity.Links = append(activity.Links, schema.Link_builder{
+						Label: normalizeText(a.Text(), false, false),
+						Url:   u.String(),
+					}.Build())
+				}
 			}
 			for i, cell := range cells.EachIter() {
 				if i == 0 {
 					activity.Label = normalizeText(cell.Text(), false, false)
-					activity.XName = cleanActivityName(cell.Text())
+					activity.XName, activity.XVenue = cutVenue(cleanActivityName(cell.Text()))
 					if _, resv, ok := cutReservationRequirement(activity.Label); ok {
 						activity.XResv = ptrTo(resv)
 					}
 				} else {
 					hdr := schedule.Days[i-1]
 					wkday := time.Weekday(-1)
-					for wd := range 7 {
-						if strings.Contains(strings.ToLower(hdr), strings.ToLower(time.Weekday(wd).String())[:3]) {
-							if wkday == -1 {
-								wkday = time.Weekday(wd)
-							} else {
-								slog.Warn("multiple weekday matches for header, ignoring", "schedule", schedule.Caption, "header", hdr)
-								wkday = -1 // multiple matches
-								break
+					if set, ok := schema.ParseWeekdaySet(hdr); ok {
+						if set.Len() == 1 {
+							for wd := range set.All() {
+								wkday = wd
 							}
+						} else {
+							slog.Warn("multiple weekday matches for header, ignoring", "schedule", schedule.Caption, "header", hdr, "weekdays", set)
 						}
 					}
 					if wkday == -1 {
-						xerrs = append(xerrs, fmt.Sprintf("warning: failed to parse weekday from header %q", hdr))
+						diags = append(diags, schema.DiagWarning(schema.Diagnostic_PARSE, "th", "failed to parse weekday from header %q", hdr))
 					}
 					times := []*schema.TimeRange{}
 					for t := range strings.FieldsFuncSeq(cell.Text(), func(r rune) bool {
@@ -946,22 +2084,17 @@ func scrapeSchedule(table *goquery.Selection, facilityName string) (msg *schema.
 						}, normalizeText(t, false, true)) == "n/a" {
 							continue
 						}
-						var trange schema.TimeRange_builder
-						trange.Label = strings.TrimSpace(normalizeText(t, false, false))
-						if wkday != -1 {
-							trange.XWkday = ptrTo(schema.Weekday(wkday))
-						}
-						if r, ok := parseClockRange(t); ok {
-							trange.XStart = ptrTo(int32(r.Start))
-							trange.XEnd = ptrTo(int32(r.End))
+						r, ok := parseClockRange(t)
+						if ok {
 							if r.Start > 24*60 || r.End > 24*60 {
 								slog.Warn("note: time range goes into the next day", "raw", t, "parsed", r)
 							}
 						} else {
+							r = schema.ClockRange{Start: -1, End: -1}
 							slog.Warn("failed to parse time range", "range", t)
-							xerrs = append(xerrs, fmt.Sprintf("warning: failed to parse time range %q", t))
+							diags = append(diags, schema.DiagWarning(schema.Diagnostic_PARSE, "td", "failed to parse time range %q", t))
 						}
-						times = append(times, trange.Build())
+						times = append(times, schema.MakeTimeRange(strings.TrimSpace(normalizeText(t, false, false)), wkday, r))
 					}
 					activity.Days = append(activity.Days, schema.Schedule_ActivityDay_builder{
 						Times: times,
@@ -972,10 +2105,10 @@ func scrapeSchedule(table *goquery.Selection, facilityName string) (msg *schema.
 		}
 	}
 	if len(schedule.Days) == 0 || len(schedule.Activities) == 0 {
-		xerrs = append(xerrs, fmt.Sprintf("failed to parse schedule %q: invalid table layout", schedule.Caption))
-		return nil, xerrs
+		diags = append(diags, schema.DiagError(schema.Diagnostic_PARSE, "", "failed to parse schedule: invalid table layout"))
+		return nil, diags
 	}
-	return schedule.Build(), xerrs
+	return schedule.Build(), diags
 }
 
 // normalizeText performs various transformations on s:
@@ -1155,411 +2288,195 @@ func cleanActivityName(activity string) string {
 	return activity
 }
 
-// parseClockRange parses a time range for an activity.
-func parseClockRange(s string) (r schema.ClockRange, ok bool) {
-	strict := false
-
-	s = strings.ReplaceAll(normalizeText(s, false, true), " ", "")
-
-	// TODO: rewrite this all now that I've decided how the edge cases should behave
-
-	parseSeparator := func(s string) (s1, s2 string, ok bool) {
-		return stringsCutFirst(s, "-", "to")
-	}
-
-	parsePart := func(s string, mdef byte) (t schema.ClockTime, m byte, ok bool) {
-		switch s {
-		case "midnight":
-			return schema.MakeClockTime(0, 0), 'a', true // midnight implies am
-		case "noon":
-			return schema.MakeClockTime(12, 0), 'p', true // noon implies pm
-		}
-		sh, sm, ok := strings.Cut(s, "h") // french time
-		if !ok {
-			if len(s) == 4 && strings.TrimFunc(s, func(r rune) bool { return r >= '0' && r <= '9' }) == "" {
-				sh, sm, m = s[:2], s[2:], 0 // military time
-			} else {
-				if s, ok = strings.CutSuffix(s, "pm"); ok {
-					if !strict {
-						for {
-							x, ok := strings.CutSuffix(strings.TrimRight(s, " "), "pm")
-							if !ok {
-								break
-							}
-							s = x // be lenient about duplicate pm suffixes
-						}
-					}
-					m = 'p' // 12h pm
-				} else if s, ok = strings.CutSuffix(s, "am"); ok {
-					if !strict {
-						for {
-							x, ok := strings.CutSuffix(strings.TrimRight(s, " "), "am")
-							if !ok {
-								break
-							}
-							s = x // be lenient about duplicate am suffixes
-						}
-					}
-					m = 'a' // 12h am
-				} else {
-					m = mdef // 24h or assumed am/pm
-				}
-				sh, sm, ok = strings.Cut(s, ":")
-				if !ok {
-					sm = "00" // no minute
-				}
-			}
-		}
-		if len(sh) > 2 || len(sm) > 2 {
-			return 0, 0, false // invalid hour/minute length
-		}
-		hh, err := strconv.ParseInt(sh, 10, 0)
-		if err != nil {
-			return 0, 0, false // invalid hour
-		}
-		if m != 0 {
-			if hh < 1 || hh > 12 {
-				return 0, 0, false // invalid 12h hour
-			}
-			switch m {
-			case 'p':
-				if hh < 12 {
-					hh += 12
-				}
-			case 'a':
-				if hh == 12 {
-					hh = 0
-				}
-			}
-		} else {
-			if hh < 0 || hh > 23 {
-				return 0, 0, false // invalid 24h hour
+// venueRe matches venue qualifiers in activity names.
+var venueRe = regexp.MustCompile(`^(?:` +
+	`(?:(?:25|50)\s*m(?:etre)?\s+)?(?:(?:therapeutic|warm|leisure|wave|main|shared|teach|teaching|tot|lap|competitive)\s+)?pool(?:\s+(?:shallow|deep))?` + // e.g., "25m pool", "therapeutic pool", "25m pool shallow"
+	`|(?:shallow|deep)(?:\s*(?:/|and|&)\s*(?:shallow|deep))?(?:\s+combo)?` + // e.g., "deep", "shallow/deep combo"
+	`|(?:(?:25|50)\s*m\s+)?(?:long|short)\s+course` + // e.g., "50m long course"
+	`)$`)
+
+// cutVenue removes the venue qualifier suffix (e.g., "- 25m pool" or "(shared
+// pool)") from a cleaned activity name, keeping the reduced capacity suffix.
+func cutVenue(activity string) (string, string) {
+	name, reduced := strings.CutSuffix(activity, " - reduced capacity")
+	var venue string
+	if x, ok := strings.CutSuffix(name, ")"); ok {
+		if i := strings.LastIndex(x, "("); i != -1 {
+			if v := strings.TrimSpace(x[i+1:]); venueRe.MatchString(v) {
+				name, venue = x[:i], v
 			}
 		}
-		mm, err := strconv.ParseInt(sm, 10, 0)
-		if err != nil {
-			return 0, 0, false // invalid minute
-		}
-		if mm < 0 || mm > 59 {
-			return 0, 0, false // invalid 24h minute
-		}
-		return schema.MakeClockTime(int(hh), int(mm)), m, true
-	}
-
-	if s == "" {
-		return r, false // empty
-	}
-	s1, s2, ok := parseSeparator(s)
-	if !ok {
-		return r, false // single time
 	}
-	if !strict {
-		for {
-			s2a, s2b, ok := parseSeparator(s2)
-			if !ok {
-				break // no extraneous separators
+	if venue == "" {
+		if i := strings.LastIndex(name, "-"); i != -1 {
+			if v := strings.Trim(name[i+1:], " ,"); venueRe.MatchString(v) {
+				name, venue = name[:i], v
 			}
-			if strings.TrimSpace(s2a) != "" || strings.TrimSpace(s2b) == "" {
-				break // junk on the left side, or nothing on the right side
-			}
-			s2 = s2b // be lenient about extraneous separators with nothing in between (it's a frequent typo)
 		}
 	}
-	if s1 == "" || s2 == "" {
-		return r, false // open range
-	}
-	t1, m1, ok := parsePart(s1, 0)
-	if !ok {
-		return r, false // invalid lhs
-	}
-	t2, m2, ok := parsePart(s2, 0)
-	if !ok {
-		return r, false // invalid rhs
-	}
-	if m1 != 0 && m2 == 0 {
-		return r, false // ambiguous lhs 12h and rhs 24h
+	if venue == "" {
+		return activity, ""
 	}
-	if m1 == 0 && t1 >= 13*60 && m2 != 0 {
-		return r, false // ambiguous lhs 24h and rhs 12h
-	}
-	if m1 == 0 && m2 == 'a' && t2 < 60 && t1 >= 12*60 && t1 < 13*60 {
-		t1 -= 12 * 60 // RHS is 12:XX AM and LHS is 12:XX
-	}
-	if m1 == 0 && m2 != 0 {
-		// only if lhs is before rhs AND the difference is greater than 12h
-		if t1 < t2 && t2-t1 >= 12*60 {
-			t1, m1, ok = parsePart(s1, m2) // reparse lhs with 12h rhs am/pm
-			if !ok {
-				return r, false // lhs hour is now invalid
-			}
-			_ = m1
-		}
-	}
-	if t1 == t2 {
-		return r, false // zero range
-	}
-	if t1 > t2 {
-		t2 += 24 * 60 // next day
-	}
-	return schema.ClockRange{Start: t1, End: t2}, true
-}
-
-var cutDateRangeRe = sync.OnceValue(func() *regexp.Regexp {
-	var b strings.Builder
-	b.WriteString(`(?i)`)                 // case-insensitive
-	b.WriteString(`^`)                    // anchor
-	b.WriteString(`\s*`)                  // trim whitespace
-	b.WriteString(`(.+?)`)                // prefix
-	b.WriteString(`[ -]*[-][ -]*`)        // separator (spaces/dashes around at least one dash)
-	b.WriteString(`((?:(?:[a-z]+|)\s*)?`) // date range modifier
-	b.WriteString(`(?:`)                  // start of date range:
-	b.WriteString(`(?:`)                  // ... month
-	for i := range 12 {
-		x := time.Month(1 + i).String()
-		if i != 0 {
-			b.WriteString(`|`)
-		}
-		b.WriteString(x[:3]) // first 3
-		b.WriteString(`|`)
-		b.WriteString(x) // or the whole thing
-	}
-	b.WriteString(`)(?:$|[ ,])`) // ... ... followed by a space or comma or end
-	b.WriteString(`|(?:`)        // ... or weekday
-	for i := range 7 {
-		x := time.Weekday(i).String()
-		if i != 0 {
-			b.WriteString(`|`)
-		}
-		b.WriteString(x[:3]) // first 3
-		b.WriteString(`|`)
-		b.WriteString(x) // or the whole thing
-	}
-	b.WriteString(`)(?:$|[ ,])`) // ... ... followed by a space or comma or end
-	b.WriteString(`).*)`)        // and the rest
-	b.WriteString(`\s*`)         // trim whitespace
-	b.WriteString(`$`)           // anchor
-	return regexp.MustCompile(b.String())
-})
-
-// cutDateRange cuts s around the first match of spacs/dash characters followed
-// by a month+space, day+space, or day+comma or day (3 letters) and a
-// non-alphanumeric character. For best results, the string should have already
-// been normalized.
-//
-// note: we do it this way so we can be sure we didn't leave part of a date
-// behind with parseDateRange.
-func cutDateRange(s string) (prefix, dates string, ok bool) {
-	if m := cutDateRangeRe().FindStringSubmatch(s); m != nil {
-		return m[1], m[2], true
+	name = strings.TrimRight(name, "-, ")
+	if reduced {
+		name += " - reduced capacity"
 	}
-	return s, "", false
+	return name, venue
 }
 
-// parseDateRange parses a schedule date range. If successful, the range will
-// always have at least the month and day set on one side.
-func parseDateRange(s string) (r schema.DateRange, ok bool) {
-	s = normalizeText(s, false, true)
+// parseOptions are the options for parsing text from the city's pages, which
+// may be in english or french.
+var parseOptions = schema.ParseOptions{French: true}
 
-	var starting, until bool
-	if s, starting = strings.CutPrefix(s, "starting "); !starting {
-		s, until = strings.CutPrefix(s, "until ")
-	}
+// parseClockRange is [schema.ParseClockRange] with parseOptions.
+func parseClockRange(s string) (r schema.ClockRange, ok bool) {
+	return schema.ParseClockRange(s, parseOptions)
+}
 
-	var and, to bool
-	leftStr, rightStr, to := strings.Cut(s, " to ")
-	if !to {
-		leftStr, rightStr, and = strings.Cut(s, " and ")
-	}
-	if (and || to) && (starting || until) {
-		return r, false // can't both be a range and a one-sided date
-	}
+// cutDateRange is [schema.CutDateRange] with parseOptions.
+func cutDateRange(s string) (prefix, dates string, ok bool) {
+	return schema.CutDateRange(s, parseOptions)
+}
 
-	parsePart := func(s string) (schema.Date, bool) {
-		d, ok := parseLooseDate(s)
-		if !ok {
-			return d, false
-		}
-		if _, hasDay := d.Day(); !hasDay {
-			return d, false
+// cutHoliday cuts s around the last dash if it is followed by only the name of
+// a holiday. For best results, the string should have already been normalized.
+func cutHoliday(s string) (prefix, holiday string, h *schema.Holiday, ok bool) {
+	if i := strings.LastIndex(s, "-"); i != -1 {
+		holiday = strings.TrimSpace(s[i+1:])
+		if h, ok = schema.ParseHoliday(holiday); ok {
+			return strings.TrimRight(s[:i], " -"), holiday, h, true
 		}
-		if _, hasMonth := d.Month(); !hasMonth {
-			return d, false
-		}
-		return d, true
 	}
+	return s, "", nil, false
+}
 
-	left, ok := parsePart(leftStr)
-	if !ok {
-		return r, false // failed to parse left side or single
-	}
+// parseDateRange is [schema.ParseDateRange] with parseOptions.
+func parseDateRange(s string) (r schema.DateRange, ok bool) {
+	return schema.ParseDateRange(s, parseOptions)
+}
 
-	switch {
-	case to, and: // ... and/to ...
-		var right schema.Date
-		if and {
-			if _, hasYear := left.Year(); hasYear {
-				return r, false // cannot have year for an "and" range
-			}
-		}
-		if day, err := strconv.ParseInt(rightStr, 10, 0); err == nil && day >= 1 && day <= 32 {
-			year, hasYear := left.Year()
-			if !hasYear {
-				year = 0
-			}
-			month, hasMonth := left.Month()
-			if !hasMonth {
-				month = 0
-			}
-			if and {
-				leftDay, hasLeftDay := left.Day()
-				if !hasLeftDay {
-					return r, false // must have left day for an "and" range
-				}
-				if leftDay+1 != int(day) {
-					return r, false // right day must be 1 more than the left day for an "and" range
-				}
+// inferYear fills in the year for the sides of r without one, choosing the
+// candidate range nearest to t (the date the page was scraped), wrapping the
+// to date into the next year if it would otherwise be before the from date
+// (e.g., "December 20 to January 5"). It returns false if the year is
+// ambiguous (i.e., the two nearest candidates are similarly close to t).
+func inferYear(r schema.DateRange, t time.Time) (schema.DateRange, bool) {
+	withYear := func(d schema.Date, year int) schema.Date {
+		if _, ok := d.Year(); ok || d.IsZero() {
+			return d
+		}
+		month, _ := d.Month()
+		day, _ := d.Day()
+		wkday, ok := d.Weekday()
+		if !ok {
+			wkday = -1
+		}
+		return schema.MakeDate(year, month, day, wkday)
+	}
+	toTime := func(d schema.Date) time.Time {
+		year, _ := d.Year()
+		month, _ := d.Month()
+		day, _ := d.Day()
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+	_, fromYear := r.From.Year()
+	_, toYear := r.To.Year()
+	if (fromYear || r.From.IsZero()) && (toYear || r.To.IsZero()) {
+		return r, true
+	}
+	if fromYear != toYear && !r.From.IsZero() && !r.To.IsZero() {
+		// the other side is relative to the one with the year
+		c := r
+		if fromYear {
+			year, _ := r.From.Year()
+			if c.To = withYear(r.To, year); c.To < c.From {
+				c.To = withYear(r.To, year+1)
 			}
-			right, ok = schema.MakeDate(year, month, int(day), -1), true
-		} else if and {
-			return r, false // must only have day number for an "and" range
 		} else {
-			right, ok = parsePart(rightStr)
+			year, _ := r.To.Year()
+			if c.From = withYear(r.From, year); c.To < c.From {
+				c.From = withYear(r.From, year-1)
+			}
 		}
-		if !ok {
-			return r, false // failed to parse right side
+		if !c.From.IsValid() || !c.To.IsValid() {
+			return r, false
 		}
-		r.From = left
-		r.To = right
-
-	case starting: // starting ...
-		r.From = left
-
-	case until: // until ...
-		r.To = left
-
-	default: // ...
-		r.From = left
-		r.To = left
+		return c, true
 	}
-	return r, true
-}
-
-// parseLooseDate attempts to loosely parse an incomplete date string. The date
-// string must contain only any of the month, day, year, and/or weekday. It
-// returns false if there is any unparsed text or ambiguity.
-func parseLooseDate(s string) (schema.Date, bool) {
-	var (
-		yyyy int
-		mm   time.Month
-		dd   int
-		w    time.Weekday = -1
-	)
-	for seg := range strings.FieldsFuncSeq(normalizeText(s, false, true), func(r rune) bool {
-		return r == '.' || r == ',' || r == '-' || unicode.IsSpace(r)
-	}) {
-		var (
-			segMonth time.Month
-			segWkday time.Weekday = -1
-		)
-		switch seg {
-		case "sun", "sunday":
-			segWkday = time.Sunday
-		case "mon", "monday":
-			segWkday = time.Monday
-		case "tue", "tuesday":
-			segWkday = time.Tuesday
-		case "wed", "wednesday":
-			segWkday = time.Wednesday
-		case "thu", "thursday":
-			segWkday = time.Thursday
-		case "fri", "friday":
-			segWkday = time.Friday
-		case "sat", "saturday":
-			segWkday = time.Saturday
-		case "jan", "january":
-			segMonth = time.January
-		case "feb", "february":
-			segMonth = time.February
-		case "mar", "march":
-			segMonth = time.March
-		case "apr", "april":
-			segMonth = time.April
-		case "may":
-			segMonth = time.May
-		case "jun", "june":
-			segMonth = time.June
-		case "jul", "july":
-			segMonth = time.July
-		case "aug", "august":
-			segMonth = time.August
-		case "sep", "september":
-			segMonth = time.September
-		case "oct", "october":
-			segMonth = time.October
-		case "nov", "november":
-			segMonth = time.November
-		case "dec", "december":
-			segMonth = time.December
-		}
-		if segMonth != 0 {
-			if mm != 0 {
-				return 0, false // duplicate month
+	type candidate struct {
+		r schema.DateRange
+		d time.Duration
+	}
+	var cs []candidate
+	for year := t.Year() - 1; year <= t.Year()+1; year++ {
+		c := schema.DateRange{From: withYear(r.From, year), To: withYear(r.To, year)}
+		if !c.From.IsZero() && !c.To.IsZero() && c.To < c.From {
+			if !toYear {
+				c.To = withYear(r.To, year+1) // wraps into the next year
+			} else {
+				c.From = withYear(r.From, year-1) // wraps from the previous year
 			}
-			mm = segMonth
-			continue
 		}
-		if segWkday != -1 {
-			if w != -1 {
-				return 0, false // duplicate weekday
-			}
-			w = segWkday
-			continue
+		if (!c.From.IsZero() && !c.From.IsValid()) || (!c.To.IsZero() && !c.To.IsValid()) {
+			continue // wrong weekday for the year
 		}
-		if len(seg) == 4 && seg[0] == '2' {
-			if n, err := strconv.ParseInt(seg, 10, 0); err == nil {
-				if n < 2000 || n >= 3000 {
-					return 0, false // year out of range
-				}
-				if yyyy != 0 {
-					return 0, false // duplicate year
-				}
-				yyyy = int(n)
-				continue
-			}
+		if !c.From.IsZero() && !c.To.IsZero() && c.To < c.From {
+			continue // still backwards
 		}
-		if len(seg) == 2 || len(seg) == 1 {
-			if n, err := strconv.ParseInt(seg, 10, 0); err == nil {
-				if n < 1 || n > 31 {
-					return 0, false // day out of range
-				}
-				if dd != 0 {
-					return 0, false // duplicate day
-				}
-				dd = int(n)
-				continue
-			}
+		if slices.ContainsFunc(cs, func(x candidate) bool { return x.r == c }) {
+			continue
+		}
+		var d time.Duration
+		if c.From.IsZero() || c.To.IsZero() {
+			d = toTime(cmp.Or(c.From, c.To)).Sub(t).Abs() // one-sided, so use the nearest date
+		} else if t.Before(toTime(c.From)) {
+			d = toTime(c.From).Sub(t)
+		} else if t.After(toTime(c.To).AddDate(0, 0, 1)) {
+			d = t.Sub(toTime(c.To).AddDate(0, 0, 1))
 		}
-		return 0, false // unparsed segment
+		cs = append(cs, candidate{c, d})
 	}
-	d := schema.MakeDate(yyyy, mm, dd, w)
-	return d, d.IsValid() // checks that it's nonzero, that the weekday/day is valid for the month/year if specified
+	if len(cs) == 0 {
+		return r, false
+	}
+	slices.SortStableFunc(cs, func(a, b candidate) int {
+		return cmp.Compare(a.d, b.d)
+	})
+	if len(cs) > 1 && cs[1].d-cs[0].d < 30*24*time.Hour {
+		return r, false // ambiguous
+	}
+	return cs[0].r, true
 }
 
-// stringsCutFirst is like [strings.Cut], but selects the earliest of multiple
-// possible separators.
-func stringsCutFirst(s string, sep ...string) (before, after string, ok bool) {
-	sn, si := 0, -1
-	for _, sep := range sep {
-		if i := strings.Index(s, sep); i >= 0 {
-			if si < 0 || i < si {
-				sn, si = len(sep), i
-			}
+// parseLooseDate is [schema.ParseLooseDate] with parseOptions.
+func parseLooseDate(s string) (schema.Date, bool) {
+	return schema.ParseLooseDate(s, parseOptions)
+}
+
+// parseProxy parses a proxy url, returning nil if s is empty.
+func parseProxy(s string) (*url.URL, error) {
+	if s == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err // don't leak credentials
 		}
+		return nil, fmt.Errorf("parse proxy url: %w", err)
+	}
+	if u.Opaque != "" {
+		return nil, fmt.Errorf("parse proxy url: missing scheme") // e.g., user:pass@host:port, which is parsed as an opaque url with user as the scheme
 	}
-	if si >= 0 {
-		return s[:si], s[si+sn:], true
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("parse proxy url %q: unsupported scheme %q", u.Redacted(), u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("parse proxy url %q: missing host", u.Redacted())
 	}
-	return s, "", false
+	return u, nil
 }
 
 func ptrTo[T any](x T) *T {
@@ -1590,6 +2507,24 @@ func headerRoundTripper(next http.RoundTripper, domain, name, value string) http
 	})
 }
 
+// channelRoundTripper sets fetchChannelHeader on responses where it isn't
+// already set.
+func channelRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		resp, err := cmp.Or(next, http.DefaultTransport).RoundTrip(r)
+		if err == nil && resp.Header.Get(fetchChannelHeader) == "" {
+			if r.Header.Get("X-Scraper-Secret") != "" {
+				resp.Header.Set(fetchChannelHeader, "secret")
+			} else if *FetchProxy != "" && matchDomain(".ottawa.ca", r.URL) {
+				resp.Header.Set(fetchChannelHeader, "proxy")
+			} else {
+				resp.Header.Set(fetchChannelHeader, "direct")
+			}
+		}
+		return resp, err
+	})
+}
+
 func rateLimitRoundTripper(next http.RoundTripper, domain string, limiter *rate.Limiter) http.RoundTripper {
 	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
 		if matchDomain(domain, r.URL) {
@@ -1601,6 +2536,43 @@ func rateLimitRoundTripper(next http.RoundTripper, domain string, limiter *rate.
 	})
 }
 
+// timeoutRoundTripper cancels requests (including reading the response body)
+// which take longer than timeout.
+func timeoutRoundTripper(next http.RoundTripper, timeout time.Duration) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		ctx, cancel := context.WithTimeoutCause(r.Context(), timeout, fmt.Errorf("request timeout of %s exceeded", timeout))
+		resp, err := cmp.Or(next, http.DefaultTransport).RoundTrip(r.WithContext(ctx))
+		if err != nil {
+			cancel()
+			if cause := context.Cause(ctx); cause != nil && r.Context().Err() == nil {
+				err = fmt.Errorf("%w (%w)", err, cause)
+			}
+			return nil, err
+		}
+		resp.Body = &cancelReadCloser{resp.Body, cancel}
+		return resp, nil
+	})
+}
+
+// cancelReadCloser calls a function after closing a reader.
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelReadCloser) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// cutDomain cuts the optional "domain=" prefix from a flag value.
+func cutDomain(s string) (domain, value string) {
+	if i := strings.IndexByte(s, '='); i > 0 && !strings.ContainsAny(s[:i], " \t/():;") {
+		return s[:i], s[i+1:]
+	}
+	return "", s
+}
+
 func matchDomain(domain string, u *url.URL) bool {
 	if domain == "" {
 		return true // match all
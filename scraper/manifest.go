@@ -0,0 +1,98 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// manifest lists exported files so mirrors and clients can verify them.
+type manifest struct {
+	Schema        string          `json:"schema"`            // proto package
+	SchemaVersion int32           `json:"schema_version"`    // see schema.SchemaVersion
+	Updated       string          `json:"updated,omitempty"` // latest source date, RFC 3339
+	Files         []manifestEntry `json:"files"`
+}
+
+type manifestEntry struct {
+	Name   string `json:"name"` // relative to the manifest, slash-separated
+	Format string `json:"format"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// writeManifest writes a manifest of files and the files in the site
+// directory to name, or stdout if name is "-".
+func writeManifest(name string, pb *schema.Data, files []exportFile, site string) error {
+	base := "."
+	if name != "-" {
+		base = filepath.Dir(name)
+	}
+	m := manifest{
+		Schema:        string(schema.File_schema_proto.Package()),
+		SchemaVersion: pb.GetSchemaVersion(),
+		Files:         []manifestEntry{},
+	}
+	if t := dataUpdated(pb); !t.IsZero() {
+		m.Updated = t.UTC().Format(time.RFC3339)
+	}
+	add := func(file, format string) error {
+		e, err := manifestFile(base, file, format)
+		if err != nil {
+			return err
+		}
+		m.Files = append(m.Files, e)
+		return nil
+	}
+	for _, f := range files {
+		if err := add(f.Name, f.Format); err != nil {
+			return err
+		}
+	}
+	if site != "" {
+		if err := filepath.WalkDir(site, func(file string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			return add(file, "site")
+		}); err != nil {
+			return err
+		}
+	}
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeExport(name, append(buf, '\n'))
+}
+
+func manifestFile(base, name, format string) (manifestEntry, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return manifestEntry{}, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return manifestEntry{}, err
+	}
+	rel, err := filepath.Rel(base, name)
+	if err != nil {
+		rel = name
+	}
+	return manifestEntry{
+		Name:   filepath.ToSlash(rel),
+		Format: format,
+		Size:   n,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
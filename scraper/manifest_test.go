@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestWriteManifest(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"data.pb":         "pb",
+		"site/index.html": "<html>",
+	} {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pb := schema.Data_builder{
+		SchemaVersion: schema.SchemaVersion,
+		Facilities: []*schema.Facility{schema.Facility_builder{
+			Source: schema.Source_builder{XDate: timestamppb.New(time.Date(2025, time.September, 1, 12, 0, 0, 0, time.UTC))}.Build(),
+		}.Build()},
+	}.Build()
+	name := filepath.Join(dir, "manifest.json")
+	if err := writeManifest(name, pb, []exportFile{{filepath.Join(dir, "data.pb"), "pb"}}, filepath.Join(dir, "site")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m manifest
+	if err := json.Unmarshal(buf, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if m.Schema != "ottrec.v1" || m.SchemaVersion != schema.SchemaVersion || m.Updated != "2025-09-01T12:00:00Z" {
+		t.Errorf("unexpected manifest %+v", m)
+	}
+	if exp := []manifestEntry{
+		{"data.pb", "pb", 2, "3315f44da4a7aaaf8d84382c7583233f697787f5871294ed49cd41207f7375a0"},
+		{"site/index.html", "site", 6, "b7d082ee12e91b756ea22e8513b8594eebcf5d39fab813da3cb55794dc888ad7"},
+	}; !slices.Equal(m.Files, exp) {
+		t.Errorf("unexpected files:\n\tgot: %+v\n\texp: %+v", m.Files, exp)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import "github.com/pgaskin/ottrec/schema"
+
+// mergeData merges multiple snapshots into one, combining the attribution,
+// activities, and alerts, and deduplicating facilities (see
+// [dedupeFacilities]). Warnings are added to duplicate facilities from
+// different snapshots which don't agree. It returns the merged data and the
+// number of conflicting duplicates.
+func mergeData(pbs ...*schema.Data) (*schema.Data, int) {
+	var (
+		data      = schema.Merge(pbs...)
+		conflicts int
+		n         int
+	)
+	for i, pb := range pbs {
+		fs := data.GetFacilities()[n:][:len(pb.GetFacilities())] // copied from pb
+		n += len(fs)
+		for _, f := range fs {
+			for _, prev := range pbs[:i] {
+				for _, o := range prev.GetFacilities() {
+					if isDuplicateFacility(o, f) && checkMergedFacility(f, o) {
+						conflicts++
+					}
+				}
+			}
+		}
+	}
+	data.SetFacilities(dedupeFacilities(data.GetFacilities()))
+	return data, conflicts
+}
+
+// checkMergedFacility adds warnings to f if it doesn't agree with o, which is
+// the same facility from another snapshot, returning true if it didn't.
+func checkMergedFacility(f, o *schema.Facility) bool {
+	var conflict bool
+	if normalizeFuzzy(f.GetAddress()) != normalizeFuzzy(o.GetAddress()) {
+		f.SetXDiagnostics(append(f.GetXDiagnostics(), schema.DiagWarning(schema.Diagnostic_CHECK, "", "merge: address differs from %q in another snapshot (%q)", o.GetSource().GetUrl(), o.GetAddress())))
+		conflict = true
+	}
+	if onlyF, onlyO := compareTimeSlots(f, o); len(onlyF) != 0 || len(onlyO) != 0 {
+		f.SetXDiagnostics(append(f.GetXDiagnostics(), schema.DiagWarning(schema.Diagnostic_CHECK, "", "merge: %d time slots differ from %q in another snapshot (%s)", len(onlyF)+len(onlyO), o.GetSource().GetUrl(), formatTimeSlots(append(onlyF, onlyO...), 3))))
+		conflict = true
+	}
+	return conflict
+}
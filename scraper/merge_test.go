@@ -0,0 +1,60 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestMergeData(t *testing.T) {
+	a := schema.Data_builder{
+		Attribution: []string{"City", "Geocoding"},
+		Activities:  []*schema.ActivityInfo{schema.ActivityInfo_builder{XName: "swim"}.Build()},
+		Alerts:      []*schema.Alert{schema.Alert_builder{Title: "Closure"}.Build()},
+		Facilities: []*schema.Facility{
+			testFacility("A Pool", "1 A Road", testSchedule("", nil, testActivity("", []*schema.TimeRange{testSlot(1, 60, 120)}))),
+			testFacility("B Pool", "2 B Road", testSchedule("", nil, testActivity("", []*schema.TimeRange{testSlot(1, 60, 120)}))),
+		},
+	}.Build()
+	b := schema.Data_builder{
+		Attribution: []string{"City", "Other city"},
+		Activities:  []*schema.ActivityInfo{schema.ActivityInfo_builder{XName: "skate"}.Build(), schema.ActivityInfo_builder{XName: "swim"}.Build()},
+		Alerts:      []*schema.Alert{schema.Alert_builder{Title: "Closure"}.Build()},
+		Facilities: []*schema.Facility{
+			testFacility("A Pool", "1 A Road", testSchedule("", nil, testActivity("", []*schema.TimeRange{testSlot(1, 60, 120)}))),
+			testFacility("B Pool", "2 B Road", testSchedule("", nil, testActivity("", []*schema.TimeRange{testSlot(1, 120, 180)}))),
+			testFacility("C Pool", "3 C Road", testSchedule("", nil, testActivity("", []*schema.TimeRange{testSlot(1, 60, 120)}))),
+		},
+	}.Build()
+	pb, conflicts := mergeData(a, b)
+	if conflicts != 1 {
+		t.Errorf("expected 1 conflict, got %d", conflicts)
+	}
+	if exp := []string{"City", "Geocoding", "Other city"}; !slices.Equal(pb.GetAttribution(), exp) {
+		t.Errorf("expected attribution %q, got %q", exp, pb.GetAttribution())
+	}
+	if n := len(pb.GetActivities()); n != 2 || pb.GetActivities()[0].GetXName() != "skate" {
+		t.Errorf("expected 2 sorted activities, got %v", pb.GetActivities())
+	}
+	if n := len(pb.GetAlerts()); n != 1 {
+		t.Errorf("expected 1 alert, got %d", n)
+	}
+	if n := len(pb.GetFacilities()); n != 3 {
+		t.Fatalf("expected 3 facilities, got %d", n)
+	}
+	for _, f := range pb.GetFacilities() {
+		var n int
+		for _, d := range f.GetXDiagnostics() {
+			if d.GetStage() == schema.Diagnostic_CHECK {
+				n++
+			}
+		}
+		if exp := map[string]int{"B Pool": 1}[f.GetName()]; n != exp {
+			t.Errorf("facility %q: expected %d check diagnostics, got %v", f.GetName(), exp, f.GetXDiagnostics())
+		}
+	}
+	if len(a.GetFacilities()[1].GetXDiagnostics())+len(b.GetFacilities()[1].GetXDiagnostics()) != 0 {
+		t.Errorf("input data was modified")
+	}
+}
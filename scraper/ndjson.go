@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// ndjsonSlot is a denormalized schedule time slot.
+type ndjsonSlot struct {
+	Facility        string   `json:"facility"`
+	FacilityAddress string   `json:"facility_address,omitempty"`
+	FacilityURL     string   `json:"facility_url,omitempty"`
+	FacilityLng     *float32 `json:"facility_lng,omitempty"`
+	FacilityLat     *float32 `json:"facility_lat,omitempty"`
+	Group           string   `json:"group"`
+	GroupTitle      string   `json:"group_title,omitempty"`
+	Schedule        string   `json:"schedule"`
+	ScheduleName    string   `json:"schedule_name,omitempty"`
+	ScheduleFrom    string   `json:"schedule_from,omitempty"` // YYYY-MM-DD
+	ScheduleTo      string   `json:"schedule_to,omitempty"`   // YYYY-MM-DD
+	Activity        string   `json:"activity"`
+	ActivityName    string   `json:"activity_name,omitempty"`
+	ActivityVenue   string   `json:"activity_venue,omitempty"`
+	Reservation     *bool    `json:"reservation,omitempty"`
+	Day             string   `json:"day,omitempty"`
+	Weekday         *int32   `json:"weekday,omitempty"` // sunday = 0
+	Start           *int32   `json:"start,omitempty"`   // minutes from 00:00
+	End             *int32   `json:"end,omitempty"`     // minutes from 00:00
+	Time            string   `json:"time"`
+}
+
+// exportNDJSON writes one json object per schedule time slot in pb to w.
+func exportNDJSON(w io.Writer, pb *schema.Data) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	for _, f := range pb.GetFacilities() {
+		for x := range f.AllTimeRanges() {
+			obj := ndjsonSlot{
+				Facility:        f.GetName(),
+				FacilityAddress: f.GetAddress(),
+				FacilityURL:     f.GetSource().GetUrl(),
+				Group:           x.Group.GetLabel(),
+				GroupTitle:      x.Group.GetXTitle(),
+				Schedule:        x.Schedule.GetCaption(),
+				ScheduleName:    x.Schedule.GetXName(),
+				ScheduleFrom:    ndjsonDate(x.Schedule.GetXFrom()),
+				ScheduleTo:      ndjsonDate(x.Schedule.GetXTo()),
+				Activity:        x.Activity.GetLabel(),
+				ActivityName:    x.Activity.GetXName(),
+				ActivityVenue:   x.Activity.GetXVenue(),
+				Day:             x.Day,
+				Time:            x.Time.GetLabel(),
+			}
+			if f.HasXLnglat() {
+				obj.FacilityLng = ptrTo(f.GetXLnglat().GetLng())
+				obj.FacilityLat = ptrTo(f.GetXLnglat().GetLat())
+			}
+			if x.Activity.HasXResv() {
+				obj.Reservation = ptrTo(x.Activity.GetXResv())
+			}
+			if x.Time.HasXWkday() {
+				obj.Weekday = ptrTo(int32(x.Time.GetXWkday()))
+			}
+			if x.Time.HasXStart() {
+				obj.Start = ptrTo(x.Time.GetXStart())
+			}
+			if x.Time.HasXEnd() {
+				obj.End = ptrTo(x.Time.GetXEnd())
+			}
+			if err := enc.Encode(obj); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// ndjsonDate formats a fully-specified YYYYMMDDW date as YYYY-MM-DD, returning
+// an empty string if it isn't.
+func ndjsonDate(d int32) string {
+	if t, ok := scheduleDate(d); ok {
+		return t.Format(time.DateOnly)
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestExportNDJSON(t *testing.T) {
+	pb := schema.Data_builder{
+		Facilities: []*schema.Facility{schema.Facility_builder{
+			Name:    "A Pool",
+			XLnglat: schema.LngLat_builder{Lng: -75.5, Lat: 45.25}.Build(),
+			ScheduleGroups: []*schema.ScheduleGroup{schema.ScheduleGroup_builder{
+				Label: "Swimming",
+				Schedules: []*schema.Schedule{schema.Schedule_builder{
+					Caption: "Swim",
+					XFrom:   ptrTo(int32(schema.MakeDate(2025, time.September, 1, -1))),
+					XTo:     ptrTo(int32(schema.MakeDate(0, time.December, 21, -1))),
+					Days:    []string{"Monday", "Tuesday"},
+					Activities: []*schema.Schedule_Activity{schema.Schedule_Activity_builder{
+						Label: "Lane swim",
+						XResv: ptrTo(false),
+						Days: []*schema.Schedule_ActivityDay{
+							schema.Schedule_ActivityDay_builder{
+								Times: []*schema.TimeRange{schema.TimeRange_builder{
+									Label:  "7 - 8 am",
+									XWkday: ptrTo(schema.Weekday(1)),
+									XStart: ptrTo(int32(420)),
+									XEnd:   ptrTo(int32(480)),
+								}.Build()},
+							}.Build(),
+							schema.Schedule_ActivityDay_builder{
+								Times: []*schema.TimeRange{schema.TimeRange_builder{Label: "noon"}.Build()},
+							}.Build(),
+						},
+					}.Build()},
+				}.Build()},
+			}.Build()},
+		}.Build()},
+	}.Build()
+	var buf bytes.Buffer
+	if err := exportNDJSON(&buf, pb); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exp := `{"facility":"A Pool","facility_lng":-75.5,"facility_lat":45.25,"group":"Swimming","schedule":"Swim","schedule_from":"2025-09-01","activity":"Lane swim","reservation":false,"day":"Monday","weekday":1,"start":420,"end":480,"time":"7 - 8 am"}` + "\n" +
+		`{"facility":"A Pool","facility_lng":-75.5,"facility_lat":45.25,"group":"Swimming","schedule":"Swim","schedule_from":"2025-09-01","activity":"Lane swim","reservation":false,"day":"Tuesday","time":"noon"}` + "\n"
+	if act := buf.String(); act != exp {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, act)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"io"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// pivotWeekdays is the order of the weekday columns in the pivot csv.
+var pivotWeekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+// exportPivotCSV writes pivotRows as a csv file.
+func exportPivotCSV(w io.Writer, pb *schema.Data) error {
+	cw := csv.NewWriter(w)
+	for row := range pivotRows(pb) {
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// pivotRows iterates over a header row, then a row for each activity in each
+// schedule, with a column for each weekday containing the time ranges, like
+// the schedule tables on the website. Time ranges without a parsed weekday are
+// put in the last column, prefixed by the day.
+func pivotRows(pb *schema.Data) iter.Seq[[]string] {
+	return func(yield func([]string) bool) {
+		header := []string{"Facility", "Group", "Schedule", "From", "To", "Activity"}
+		for _, wd := range pivotWeekdays {
+			header = append(header, wd.String())
+		}
+		header = append(header, "Other")
+		if !yield(header) {
+			return
+		}
+		for _, f := range pb.GetFacilities() {
+			for _, g := range f.GetScheduleGroups() {
+				for _, s := range g.GetSchedules() {
+					for _, a := range s.GetActivities() {
+						var cols [8][]string
+						for i, d := range a.GetDays() {
+							for _, t := range d.GetTimes() {
+								if t.HasXWkday() {
+									wd := t.GetXWkday().AsWeekday()
+									c := (int(wd) + 6) % 7 // monday first
+									cols[c] = append(cols[c], t.GetLabel())
+								} else if i < len(s.GetDays()) {
+									cols[7] = append(cols[7], s.GetDays()[i]+" "+t.GetLabel())
+								} else {
+									cols[7] = append(cols[7], t.GetLabel())
+								}
+							}
+						}
+						row := []string{
+							f.GetName(),
+							cmp.Or(g.GetXTitle(), g.GetLabel()),
+							s.GetCaption(),
+							ndjsonDate(s.GetXFrom()),
+							ndjsonDate(s.GetXTo()),
+							a.GetLabel(),
+						}
+						for _, c := range cols {
+							row = append(row, strings.Join(c, ", "))
+						}
+						if !yield(row) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestExportPivotCSV(t *testing.T) {
+	pb := schema.Data_builder{
+		Facilities: []*schema.Facility{schema.Facility_builder{
+			Name: "A Pool",
+			ScheduleGroups: []*schema.ScheduleGroup{schema.ScheduleGroup_builder{
+				Label: "Drop-in swimming",
+				Schedules: []*schema.Schedule{schema.Schedule_builder{
+					Caption: "Fall schedule",
+					XFrom:   ptrTo(int32(schema.MakeDate(2025, time.September, 1, -1))),
+					Days:    []string{"Sunday", "Monday", "Holiday"},
+					Activities: []*schema.Schedule_Activity{
+						schema.Schedule_Activity_builder{
+							Label: "Lane swim",
+							Days: []*schema.Schedule_ActivityDay{
+								schema.Schedule_ActivityDay_builder{
+									Times: []*schema.TimeRange{schema.TimeRange_builder{Label: "9 - 10 am", XWkday: ptrTo(schema.Weekday_SUNDAY)}.Build()},
+								}.Build(),
+								schema.Schedule_ActivityDay_builder{
+									Times: []*schema.TimeRange{
+										schema.TimeRange_builder{Label: "7 - 8 am", XWkday: ptrTo(schema.Weekday_MONDAY)}.Build(),
+										schema.TimeRange_builder{Label: "6 - 7 pm", XWkday: ptrTo(schema.Weekday_MONDAY)}.Build(),
+									},
+								}.Build(),
+								schema.Schedule_ActivityDay_builder{
+									Times: []*schema.TimeRange{schema.TimeRange_builder{Label: "noon"}.Build()},
+								}.Build(),
+							},
+						}.Build(),
+						schema.Schedule_Activity_builder{
+							Label: "Aquafit",
+						}.Build(),
+					},
+				}.Build()},
+			}.Build()},
+		}.Build()},
+	}.Build()
+	var buf bytes.Buffer
+	if err := exportPivotCSV(&buf, pb); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exp := "Facility,Group,Schedule,From,To,Activity,Monday,Tuesday,Wednesday,Thursday,Friday,Saturday,Sunday,Other\n" +
+		"A Pool,Drop-in swimming,Fall schedule,2025-09-01,,Lane swim,\"7 - 8 am, 6 - 7 pm\",,,,,,9 - 10 am,Holiday noon\n" +
+		"A Pool,Drop-in swimming,Fall schedule,2025-09-01,,Aquafit,,,,,,,,\n"
+	if act := buf.String(); act != exp {
+		t.Errorf("unexpected output:\n%s", act)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// reprocess loads previously scraped data (merging it if there are multiple
+// snapshots), geocodes facilities which need it (if enabled), and exports it,
+// without fetching any pages.
+func reprocess(ctx context.Context, names []string) error {
+	var pbs []*schema.Data
+	for _, name := range names {
+		slog.Info("loading data", "name", name)
+		pb, err := loadData(name)
+		if err != nil {
+			return fmt.Errorf("load %q: %w", name, err)
+		}
+		pbs = append(pbs, pb)
+	}
+	pb := pbs[0]
+	if len(pbs) > 1 {
+		var conflicts int
+		pb, conflicts = mergeData(pbs...)
+		if conflicts != 0 {
+			slog.Warn("merged snapshots disagree", "conflicts", conflicts)
+		}
+		slog.Info("merged snapshots", "count", len(pbs), "facilities", len(pb.GetFacilities()))
+	}
+	if *Geocodio {
+		var pending []*schema.Facility
+		for _, f := range pb.GetFacilities() {
+			if !needsGeocode(f) {
+				continue
+			}
+			f.SetXDiagnostics(schema.WithoutStage(f.GetXDiagnostics(), schema.Diagnostic_GEOCODE))
+			pending = append(pending, f)
+		}
+		slog.Info("geocoding facilities", "count", len(pending))
+		for _, attrib := range geocodeFacilities(ctx, pending) {
+			if x := "Address data " + strings.TrimPrefix(attrib, "Data "); !slices.Contains(pb.GetAttribution(), x) {
+				pb.SetAttribution(append(pb.GetAttribution(), x))
+			}
+		}
+	}
+	if err := export(ctx, pb); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	return nil
+}
+
+// loadData reads a data file (or stdin if name is "-"), detecting whether it's
+// binpb, json, or textpb, and migrating it to the current schema version.
+func loadData(name string) (*schema.Data, error) {
+	var (
+		buf []byte
+		err error
+	)
+	if name == "-" {
+		buf, err = io.ReadAll(os.Stdin)
+	} else {
+		buf, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return schema.Unmarshal(buf, dataFormat(buf))
+}
+
+// dataFormat guesses the encoding of buf. Binary protobuf is assumed unless it
+// looks like a json object or printable text.
+func dataFormat(buf []byte) string {
+	buf = bytes.TrimSpace(buf)
+	if len(buf) != 0 && buf[0] == '{' {
+		return "json"
+	}
+	if len(buf) == 0 || !utf8.Valid(buf) {
+		return "binpb"
+	}
+	if bytes.ContainsFunc(buf, func(r rune) bool {
+		return r < ' ' && r != '\t' && r != '\n' && r != '\r'
+	}) {
+		return "binpb"
+	}
+	return "textpb"
+}
+
+// needsGeocode checks whether f is missing coordinates or previously failed
+// geocoding.
+func needsGeocode(f *schema.Facility) bool {
+	if f.GetAddress() == "" {
+		return false
+	}
+	if !f.HasXLnglat() {
+		return true
+	}
+	return len(schema.WithStage(f.GetXDiagnostics(), schema.Diagnostic_GEOCODE)) != 0
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pgaskin/ottrec/schema"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNeedsGeocode(t *testing.T) {
+	lnglat := schema.LngLat_builder{Lng: -75.7, Lat: 45.4}.Build()
+	for i, tc := range []struct {
+		F *schema.Facility
+		N bool
+	}{
+		{schema.Facility_builder{Address: "1 A Road"}.Build(), true},
+		{schema.Facility_builder{Address: "1 A Road", XLnglat: lnglat}.Build(), false},
+		{schema.Facility_builder{XLnglat: lnglat}.Build(), false},
+		{schema.Facility_builder{}.Build(), false},
+		{schema.Facility_builder{Address: "1 A Road", XLnglat: lnglat, XDiagnostics: []*schema.Diagnostic{
+			schema.DiagWarning(schema.Diagnostic_PARSE, "", "test"),
+		}}.Build(), false},
+		{schema.Facility_builder{Address: "1 A Road", XLnglat: lnglat, XDiagnostics: []*schema.Diagnostic{
+			schema.DiagWarning(schema.Diagnostic_GEOCODE, "", "test"),
+		}}.Build(), true},
+	} {
+		if n := needsGeocode(tc.F); n != tc.N {
+			t.Errorf("%d: expected %t, got %t", i, tc.N, n)
+		}
+	}
+}
+
+func TestLoadData(t *testing.T) {
+	pb := schema.Data_builder{
+		SchemaVersion: schema.SchemaVersion,
+		Attribution:   []string{"Test"},
+		Facilities: []*schema.Facility{schema.Facility_builder{
+			Name:              "A Pool",
+			NotificationsHtml: strings.Repeat("<p>Closed for maintenance.</p>\n", 10),
+			XLnglat:           schema.LngLat_builder{Lng: -75.5, Lat: 45.25}.Build(),
+		}.Build()},
+	}.Build()
+	dir := t.TempDir()
+	*ExportPB = filepath.Join(dir, "data.pb")
+	*ExportJSON = filepath.Join(dir, "data.json")
+	*ExportTextPB = filepath.Join(dir, "data.textpb")
+	*ExportPretty = true
+	defer func() {
+		*ExportPB, *ExportJSON, *ExportTextPB, *ExportPretty = "", "", "", false
+	}()
+	if err := export(context.Background(), pb); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	for name, format := range map[string]string{
+		*ExportPB:     "binpb",
+		*ExportJSON:   "json",
+		*ExportTextPB: "textpb",
+	} {
+		buf, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if act := dataFormat(buf); act != format {
+			t.Errorf("%s: expected format %s, got %s", name, format, act)
+		}
+		other, err := loadData(name)
+		if err != nil {
+			t.Errorf("%s: load: %v", name, err)
+		} else if !proto.Equal(pb, other) {
+			t.Errorf("%s: data mismatch", name)
+		}
+	}
+}
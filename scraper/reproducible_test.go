@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	for _, tc := range []struct {
+		In     string
+		Pretty bool
+		Out    string
+	}{
+		{`{"b": 1,  "a":{"d":[1.50, 2e3, 12345678901234567890], "c":"<&>"}}`, false, `{"a":{"c":"<&>","d":[1.50,2e3,12345678901234567890]},"b":1}`},
+		{`{"b":true,"a":null}`, true, "{\n  \"a\": null,\n  \"b\": true\n}"},
+	} {
+		out, err := canonicalJSON([]byte(tc.In), tc.Pretty)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.In, err)
+		} else if string(out) != tc.Out {
+			t.Errorf("%s: expected %q, got %q", tc.In, tc.Out, out)
+		}
+	}
+	if _, err := canonicalJSON([]byte(`{`), false); err == nil {
+		t.Errorf("expected error for invalid json")
+	}
+}
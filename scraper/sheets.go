@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// sheetsCredentials is a google service account key file.
+type sheetsCredentials struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// pushSheets replaces the contents of the named sheet in the spreadsheet with
+// rows, authenticating with the service account key in the credentials file.
+// The spreadsheet must be shared with the service account.
+func pushSheets(ctx context.Context, credentials, spreadsheet, sheet string, rows [][]string) error {
+	buf, err := os.ReadFile(credentials)
+	if err != nil {
+		return fmt.Errorf("read credentials: %w", err)
+	}
+	var creds sheetsCredentials
+	if err := json.Unmarshal(buf, &creds); err != nil {
+		return fmt.Errorf("read credentials: %w", err)
+	}
+	token, err := sheetsToken(ctx, creds, time.Now())
+	if err != nil {
+		return fmt.Errorf("get access token: %w", err)
+	}
+
+	rng := "'" + strings.ReplaceAll(sheet, "'", "''") + "'"
+	base := "https://sheets.googleapis.com/v4/spreadsheets/" + url.PathEscape(spreadsheet) + "/values/"
+
+	slog.Info("clear sheet", "spreadsheet", spreadsheet, "sheet", sheet)
+	if err := sheetsDo(ctx, token, http.MethodPost, base+url.PathEscape(rng)+":clear", struct{}{}); err != nil {
+		return fmt.Errorf("clear sheet: %w", err)
+	}
+
+	slog.Info("update sheet", "spreadsheet", spreadsheet, "sheet", sheet, "rows", len(rows))
+	if err := sheetsDo(ctx, token, http.MethodPut, base+url.PathEscape(rng+"!A1")+"?valueInputOption=RAW", map[string]any{
+		"range":          rng + "!A1",
+		"majorDimension": "ROWS",
+		"values":         rows,
+	}); err != nil {
+		return fmt.Errorf("update sheet: %w", err)
+	}
+	return nil
+}
+
+// sheetsToken gets an access token for the service account using a signed
+// jwt assertion.
+func sheetsToken(ctx context.Context, creds sheetsCredentials, now time.Time) (string, error) {
+	if creds.ClientEmail == "" || creds.PrivateKey == "" || creds.TokenURI == "" {
+		return "", fmt.Errorf("not a service account key")
+	}
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("invalid private key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("invalid private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("invalid private key: expected rsa, got %T", key)
+	}
+
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   creds.ClientEmail,
+		"scope": "https://www.googleapis.com/auth/spreadsheets",
+		"aud":   creds.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	jwt := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(jwt))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("sign jwt: %w", err)
+	}
+	jwt += "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, creds.TokenURI, strings.NewReader(url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {jwt},
+	}.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := publishClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var obj struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("decode response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if obj.Error != "" {
+			return "", fmt.Errorf("response status %d: %s: %s", resp.StatusCode, obj.Error, obj.ErrorDescription)
+		}
+		return "", fmt.Errorf("response status %d", resp.StatusCode)
+	}
+	if obj.AccessToken == "" {
+		return "", fmt.Errorf("decode response: missing access token")
+	}
+	return obj.AccessToken, nil
+}
+
+// sheetsDo makes a sheets api request with a json body.
+func sheetsDo(ctx context.Context, token, method, u string, body any) error {
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := publishClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var obj struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil || obj.Error.Message == "" {
+			return fmt.Errorf("response status %d", resp.StatusCode)
+		}
+		return fmt.Errorf("response status %d: %s", resp.StatusCode, obj.Error.Message)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPushSheets(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	creds, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "test@example.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    "https://oauth2.googleapis.com/token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	credsFile := filepath.Join(t.TempDir(), "creds.json")
+	if err := os.WriteFile(credsFile, creds, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	var requests []string
+	cache := setupTestHTTP(t, roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requests = append(requests, r.Method+" "+r.URL.String())
+		if x := r.Header.Get("User-Agent"); x != defaultUserAgent() {
+			t.Errorf("unexpected user agent %q", x)
+		}
+		if r.URL.Host == "oauth2.googleapis.com" {
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("parse token request: %v", err)
+			}
+			parts := strings.Split(r.PostForm.Get("assertion"), ".")
+			if len(parts) != 3 {
+				t.Fatalf("invalid jwt %q", r.PostForm.Get("assertion"))
+			}
+			sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], sig); err != nil {
+				t.Errorf("invalid jwt signature: %v", err)
+			}
+			var claims map[string]any
+			if buf, err := base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+				t.Errorf("invalid jwt claims: %v", err)
+			} else if err := json.Unmarshal(buf, &claims); err != nil {
+				t.Errorf("invalid jwt claims: %v", err)
+			} else if claims["iss"] != "test@example.iam.gserviceaccount.com" || claims["aud"] != "https://oauth2.googleapis.com/token" {
+				t.Errorf("unexpected jwt claims %v", claims)
+			}
+			return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"access_token":"token","expires_in":3599,"token_type":"Bearer"}`))}, nil
+		}
+		if x := r.Header.Get("Authorization"); x != "Bearer token" {
+			t.Errorf("unexpected authorization %q", x)
+		}
+		if r.Method == http.MethodPut {
+			var obj struct {
+				Values [][]string
+			}
+			if err := json.NewDecoder(r.Body).Decode(&obj); err != nil {
+				t.Errorf("decode update request: %v", err)
+			} else if !slices.EqualFunc(obj.Values, [][]string{{"a", "b"}, {"c", ""}}, slices.Equal) {
+				t.Errorf("unexpected values %q", obj.Values)
+			}
+		}
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+	}))
+
+	if err := pushSheets(context.Background(), credsFile, "abc", "Bob's Sheet", [][]string{{"a", "b"}, {"c", ""}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exp := []string{
+		"POST https://oauth2.googleapis.com/token",
+		"POST https://sheets.googleapis.com/v4/spreadsheets/abc/values/%27Bob%27%27s%20Sheet%27:clear",
+		"PUT https://sheets.googleapis.com/v4/spreadsheets/abc/values/%27Bob%27%27s%20Sheet%27%21A1?valueInputOption=RAW",
+	}; !slices.Equal(requests, exp) {
+		t.Errorf("unexpected requests:\n\tgot: %q\n\texp: %q", requests, exp)
+	}
+	assertEmptyDir(t, cache) // the token must not be cached
+}
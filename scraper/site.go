@@ -0,0 +1,169 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+//go:embed site.tmpl
+var siteTemplateText string
+
+var siteTemplate = template.Must(template.New("").Funcs(template.FuncMap{
+	"root":        func() string { return "" },
+	"updated":     func() string { return "" },
+	"attribution": func() []string { return nil },
+	"activity":    func(string) string { return "" },
+	"trusted":     func(s string) template.HTML { return template.HTML(s) }, // already sanitized by the scraper
+}).Parse(siteTemplateText))
+
+type siteFacility struct {
+	Path     string // relative to the root
+	Facility *schema.Facility
+}
+
+type siteActivity struct {
+	Path  string // relative to the root
+	Name  string
+	Info  *schema.ActivityInfo
+	Slots []siteActivitySlots
+}
+
+type siteActivitySlots struct {
+	siteFacility
+	Slots []schema.TimeRangeRef
+}
+
+// exportSite renders pb as a static website in dir, with an index page, a page
+// for each facility, and a page for each activity listing where it's offered.
+func exportSite(pb *schema.Data, dir string) error {
+	updated := dataUpdated(pb)
+
+	var (
+		slugs      = map[string]bool{}
+		actSlugs   = map[string]bool{}
+		facilities []siteFacility
+		activities = map[string]*siteActivity{}
+	)
+	for _, f := range pb.GetFacilities() {
+		sf := siteFacility{
+			Path:     "facilities/" + siteSlug(f.GetName(), slugs) + ".html",
+			Facility: f,
+		}
+		facilities = append(facilities, sf)
+		for x := range f.AllTimeRanges() {
+			name := x.Activity.GetXName()
+			if name == "" {
+				continue
+			}
+			a, ok := activities[name]
+			if !ok {
+				a = &siteActivity{Name: name}
+				activities[name] = a
+			}
+			if n := len(a.Slots); n == 0 || a.Slots[n-1].Facility != f {
+				a.Slots = append(a.Slots, siteActivitySlots{siteFacility: sf})
+			}
+			a.Slots[len(a.Slots)-1].Slots = append(a.Slots[len(a.Slots)-1].Slots, x)
+		}
+	}
+	var (
+		idx          = schema.NewIndex(pb)
+		activityList []*siteActivity
+	)
+	for _, name := range slices.Sorted(maps.Keys(activities)) {
+		a := activities[name]
+		a.Path = "activities/" + siteSlug(name, actSlugs) + ".html"
+		a.Info, _ = idx.ActivityInfo(name)
+		activityList = append(activityList, a)
+	}
+
+	render := func(name, tmpl string, data any) error {
+		root := strings.Repeat("../", strings.Count(name, "/"))
+		t, err := siteTemplate.Clone()
+		if err != nil {
+			return err
+		}
+		t.Funcs(template.FuncMap{
+			"root": func() string { return root },
+			"updated": func() string {
+				if updated.IsZero() {
+					return ""
+				}
+				return updated.Format("January 2, 2006")
+			},
+			"attribution": func() []string { return pb.GetAttribution() },
+			"activity": func(name string) string {
+				if a, ok := activities[name]; ok {
+					return a.Path
+				}
+				return ""
+			},
+		})
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			return err
+		}
+		f, err := os.Create(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := t.ExecuteTemplate(f, tmpl, data); err != nil {
+			return fmt.Errorf("render %s: %w", name, err)
+		}
+		return f.Close()
+	}
+	if err := render("index.html", "index", map[string]any{
+		"Facilities": facilities,
+		"Activities": activityList,
+	}); err != nil {
+		return err
+	}
+	for _, f := range facilities {
+		if err := render(f.Path, "facility", f); err != nil {
+			return err
+		}
+	}
+	for _, a := range activityList {
+		if err := render(a.Path, "activity", a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dataUpdated returns the latest source date in pb, or the zero time if there
+// aren't any.
+func dataUpdated(pb *schema.Data) time.Time {
+	var updated time.Time
+	for _, f := range pb.GetFacilities() {
+		if t := f.GetSource().GetXDate().AsTime(); f.GetSource().HasXDate() && t.After(updated) {
+			updated = t
+		}
+	}
+	return updated
+}
+
+// siteSlug makes a unique url-safe file name from s.
+func siteSlug(s string, used map[string]bool) string {
+	s = strings.Join(strings.Fields(normalizeFuzzy(s)), "-")
+	if s == "" {
+		s = "page"
+	}
+	slug := s
+	for i := 2; used[slug]; i++ {
+		slug = s + "-" + strconv.Itoa(i)
+	}
+	used[slug] = true
+	return slug
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestExportSite(t *testing.T) {
+	facility := func(name string) *schema.Facility {
+		a := testActivity("Lane swim <18+>", testTimes("7 - 8 am"))
+		a.SetXName("lane swim")
+		f := testFacility(name, "", testSchedule("Swim", []string{"Monday"}, a))
+		f.SetNotificationsHtml("<p>Closed <b>today</b></p><script>alert(1)</script>")
+		f.SetXNotificationsHtml("<p>Closed <b>today</b></p>")
+		return f
+	}
+	pb := schema.Data_builder{
+		Attribution: []string{"Test attribution"},
+		Facilities:  []*schema.Facility{facility("A Pool"), facility("a pool")},
+		Activities:  []*schema.ActivityInfo{schema.ActivityInfo_builder{XName: "lane swim", Description: "Swim lengths."}.Build()},
+	}.Build()
+
+	dir := t.TempDir()
+	if err := exportSite(pb, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, exp := range map[string][]string{
+		"index.html":                {`<a href="facilities/a-pool.html">A Pool</a>`, `<a href="facilities/a-pool-2.html">a pool</a>`, `<a href="activities/lane-swim.html">lane swim</a>`, "Last updated September 1, 2025.", "Test attribution"},
+		"facilities/a-pool.html":    {`<a href="../index.html">`, `<p>Closed <b>today</b></p>`, `<a href="../activities/lane-swim.html">Lane swim &lt;18&#43;&gt;</a>`, `<td>7 - 8 am</td>`},
+		"activities/lane-swim.html": {`<p>Swim lengths.</p>`, `<a href="../facilities/a-pool.html">A Pool</a>`, `<a href="../facilities/a-pool-2.html">a pool</a>`},
+	} {
+		buf, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("read %s: %v", name, err)
+			continue
+		}
+		for _, x := range exp {
+			if !strings.Contains(string(buf), x) {
+				t.Errorf("%s: expected output to contain %q", name, x)
+			}
+		}
+		if strings.Contains(string(buf), "alert(1)") {
+			t.Errorf("%s: expected unsanitized html not to be embedded", name)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// dataStats summarizes the coverage and quality of the data.
+type dataStats struct {
+	Facilities         int             `json:"facilities"`
+	Geocoded           int             `json:"geocoded"`
+	ScheduleGroups     int             `json:"schedule_groups"`
+	Schedules          int             `json:"schedules"`
+	DatedSchedules     int             `json:"dated_schedules"` // with a parsed from or to date
+	TimeRanges         int             `json:"time_ranges"`
+	ParsedTimeRanges   int             `json:"parsed_time_ranges"`          // with a parsed weekday, start, and end
+	FacilitiesWithDiag map[string]int  `json:"facilities_with_diagnostics"` // by "severity/stage"
+	Diagnostics        map[string]int  `json:"diagnostics"`                 // by "severity/stage"
+	Categories         map[string]int  `json:"categories"`                  // facilities by schedule group title
+	Activities         map[string]int  `json:"activities"`                  // time ranges by normalized activity name
+	PerFacility        []facilityStats `json:"per_facility"`
+}
+
+type facilityStats struct {
+	Name             string `json:"name"`
+	Schedules        int    `json:"schedules"`
+	TimeRanges       int    `json:"time_ranges"`
+	ParsedTimeRanges int    `json:"parsed_time_ranges"`
+	Diagnostics      int    `json:"diagnostics"`
+}
+
+// computeStats computes statistics about pb.
+func computeStats(pb *schema.Data) dataStats {
+	st := dataStats{
+		FacilitiesWithDiag: map[string]int{},
+		Diagnostics:        map[string]int{},
+		Categories:         map[string]int{},
+		Activities:         map[string]int{},
+	}
+	for _, f := range pb.GetFacilities() {
+		fs := facilityStats{Name: f.GetName(), Diagnostics: len(f.GetXDiagnostics())}
+		st.Facilities++
+		if f.HasXLnglat() {
+			st.Geocoded++
+		}
+		seen := map[string]bool{}
+		for _, d := range f.GetXDiagnostics() {
+			k := statsDiagKey(d)
+			st.Diagnostics[k]++
+			if !seen[k] {
+				st.FacilitiesWithDiag[k]++
+				seen[k] = true
+			}
+		}
+		categories := map[string]bool{}
+		for _, g := range f.GetScheduleGroups() {
+			st.ScheduleGroups++
+			if t := cmp.Or(g.GetXTitle(), g.GetLabel()); !categories[t] {
+				st.Categories[t]++
+				categories[t] = true
+			}
+			for _, s := range g.GetSchedules() {
+				fs.Schedules++
+				if s.HasXFrom() || s.HasXTo() {
+					st.DatedSchedules++
+				}
+			}
+		}
+		for x := range f.AllTimeRanges() {
+			fs.TimeRanges++
+			if _, _, ok := x.Time.AsXParsed(); ok {
+				fs.ParsedTimeRanges++
+			}
+			if n := x.Activity.GetXName(); n != "" {
+				st.Activities[n]++
+			}
+		}
+		st.Schedules += fs.Schedules
+		st.TimeRanges += fs.TimeRanges
+		st.ParsedTimeRanges += fs.ParsedTimeRanges
+		st.PerFacility = append(st.PerFacility, fs)
+	}
+	return st
+}
+
+func statsDiagKey(d *schema.Diagnostic) string {
+	return strings.ToLower(d.GetSeverity().String() + "/" + d.GetStage().String())
+}
+
+// String formats st as a human-readable report.
+func (st dataStats) String() string {
+	var b strings.Builder
+	pct := func(n, total int) string {
+		if total == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f%%", float64(n)/float64(total)*100)
+	}
+	fmt.Fprintf(&b, "facilities: %d\n", st.Facilities)
+	fmt.Fprintf(&b, "  geocoded: %d (%s)\n", st.Geocoded, pct(st.Geocoded, st.Facilities))
+	for _, k := range slices.Sorted(maps.Keys(st.FacilitiesWithDiag)) {
+		fmt.Fprintf(&b, "  with %s diagnostics: %d (%s)\n", k, st.FacilitiesWithDiag[k], pct(st.FacilitiesWithDiag[k], st.Facilities))
+	}
+	fmt.Fprintf(&b, "schedule groups: %d\n", st.ScheduleGroups)
+	fmt.Fprintf(&b, "schedules: %d\n", st.Schedules)
+	fmt.Fprintf(&b, "  with parsed dates: %d (%s)\n", st.DatedSchedules, pct(st.DatedSchedules, st.Schedules))
+	fmt.Fprintf(&b, "time ranges: %d\n", st.TimeRanges)
+	fmt.Fprintf(&b, "  parsed: %d (%s)\n", st.ParsedTimeRanges, pct(st.ParsedTimeRanges, st.TimeRanges))
+	fmt.Fprintf(&b, "diagnostics:\n")
+	for _, k := range slices.Sorted(maps.Keys(st.Diagnostics)) {
+		fmt.Fprintf(&b, "  %s: %d\n", k, st.Diagnostics[k])
+	}
+	for _, x := range []struct {
+		name string
+		m    map[string]int
+	}{
+		{"categories (facilities)", st.Categories},
+		{"activities (time ranges)", st.Activities},
+	} {
+		fmt.Fprintf(&b, "%s:\n", x.name)
+		for _, k := range statsSorted(x.m) {
+			fmt.Fprintf(&b, "  %5d %s\n", x.m[k], k)
+		}
+	}
+	fmt.Fprintf(&b, "per facility (schedules, time ranges, parsed, diagnostics):\n")
+	for _, f := range st.PerFacility {
+		fmt.Fprintf(&b, "  %3d %5d %5s %3d %s\n", f.Schedules, f.TimeRanges, pct(f.ParsedTimeRanges, f.TimeRanges), f.Diagnostics, f.Name)
+	}
+	return b.String()
+}
+
+// statsSorted returns the keys of m sorted by descending value, then key.
+func statsSorted(m map[string]int) []string {
+	return slices.SortedFunc(maps.Keys(m), func(a, b string) int {
+		return cmp.Or(cmp.Compare(m[b], m[a]), strings.Compare(a, b))
+	})
+}
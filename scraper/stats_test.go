@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestComputeStats(t *testing.T) {
+	slot := func(label string, parsed bool) *schema.TimeRange {
+		b := schema.TimeRange_builder{Label: label}
+		if parsed {
+			b.XWkday = ptrTo(schema.Weekday_MONDAY)
+			b.XStart = ptrTo(int32(420))
+			b.XEnd = ptrTo(int32(480))
+		}
+		return b.Build()
+	}
+	pb := schema.Data_builder{
+		Facilities: []*schema.Facility{
+			schema.Facility_builder{
+				Name:    "A Pool",
+				XLnglat: schema.LngLat_builder{Lng: -75.5, Lat: 45.25}.Build(),
+				XDiagnostics: []*schema.Diagnostic{
+					schema.DiagWarning(schema.Diagnostic_PARSE, "", "a"),
+					schema.DiagWarning(schema.Diagnostic_PARSE, "", "b"),
+				},
+				ScheduleGroups: []*schema.ScheduleGroup{schema.ScheduleGroup_builder{
+					XTitle: "Swimming",
+					Schedules: []*schema.Schedule{schema.Schedule_builder{
+						Days: []string{"Monday", "Tuesday"},
+						Activities: []*schema.Schedule_Activity{schema.Schedule_Activity_builder{
+							XName: "lane swim",
+							Days: []*schema.Schedule_ActivityDay{
+								schema.Schedule_ActivityDay_builder{Times: []*schema.TimeRange{slot("7 - 8 am", true)}}.Build(),
+								schema.Schedule_ActivityDay_builder{Times: []*schema.TimeRange{slot("later", false)}}.Build(),
+							},
+						}.Build()},
+					}.Build()},
+				}.Build()},
+			}.Build(),
+			schema.Facility_builder{Name: "B Arena"}.Build(),
+		},
+	}.Build()
+	st := computeStats(pb)
+	if st.Facilities != 2 || st.Geocoded != 1 || st.Schedules != 1 || st.TimeRanges != 2 || st.ParsedTimeRanges != 1 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+	if st.Diagnostics["warning/parse"] != 2 || st.FacilitiesWithDiag["warning/parse"] != 1 {
+		t.Errorf("unexpected diagnostic stats %v %v", st.Diagnostics, st.FacilitiesWithDiag)
+	}
+	if st.Categories["Swimming"] != 1 || st.Activities["lane swim"] != 2 {
+		t.Errorf("unexpected category/activity stats %v %v", st.Categories, st.Activities)
+	}
+	s := st.String()
+	for _, x := range []string{
+		"geocoded: 1 (50.0%)",
+		"with warning/parse diagnostics: 1 (50.0%)",
+		"parsed: 1 (50.0%)",
+		"    2 lane swim",
+		"    0     0     -   0 B Arena",
+	} {
+		if !strings.Contains(s, x) {
+			t.Errorf("expected report to contain %q, got:\n%s", x, s)
+		}
+	}
+}
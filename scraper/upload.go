@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// uploadTarget uploads files to cloud storage.
+type uploadTarget interface {
+	Put(ctx context.Context, key string, buf []byte, contentType, cacheControl string) error
+}
+
+// newUploadTarget parses an upload destination, which is one of:
+//
+//   - s3://bucket/prefix (using the AWS_* environment variables, and
+//     AWS_ENDPOINT_URL_S3 for other s3-compatible services)
+//   - gs://bucket/prefix (using the AWS_* environment variables set to a
+//     google cloud storage hmac key)
+//   - https://account.blob.core.windows.net/container/prefix?sas (an azure
+//     blob storage container sas url)
+func newUploadTarget(dst string) (uploadTarget, error) {
+	u, err := url.Parse(dst)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "s3", "gs":
+		if u.Host == "" {
+			return nil, fmt.Errorf("missing bucket")
+		}
+		t := &s3Target{
+			Bucket:       u.Host,
+			Prefix:       strings.Trim(u.Path, "/"),
+			AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+			Region:       cmp.Or(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1"),
+			Endpoint:     os.Getenv("AWS_ENDPOINT_URL_S3"),
+		}
+		if u.Scheme == "gs" {
+			t.Endpoint, t.Region = "https://storage.googleapis.com", "auto"
+		}
+		if t.AccessKey == "" || t.SecretKey == "" {
+			return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+		}
+		return t, nil
+	case "https":
+		if !strings.HasSuffix(u.Host, ".blob.core.windows.net") {
+			return nil, fmt.Errorf("unsupported https upload destination %q (expected an azure blob storage container url)", u.Host)
+		}
+		if u.RawQuery == "" {
+			return nil, fmt.Errorf("missing sas token")
+		}
+		return &azureTarget{URL: u}, nil
+	default:
+		return nil, fmt.Errorf("unsupported upload destination scheme %q", u.Scheme)
+	}
+}
+
+// uploadExports uploads the exported files to dst, using the base name of each
+// file as the key, and the base name of the site directory as the prefix for
+// the files in it. If multiple files have the same key, only the first one is
+// uploaded.
+func uploadExports(ctx context.Context, dst, cacheControl string, files []exportFile, site string) error {
+	t, err := newUploadTarget(dst)
+	if err != nil {
+		return err
+	}
+	var keys []string
+	upload := func(key, name string) error {
+		if slices.Contains(keys, key) {
+			return nil
+		}
+		keys = append(keys, key)
+		buf, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		ct := uploadContentType(name)
+		slog.Info("upload", "key", key, "size", len(buf), "content_type", ct)
+		if err := t.Put(ctx, key, buf, ct, cacheControl); err != nil {
+			return fmt.Errorf("upload %s: %w", key, err)
+		}
+		return nil
+	}
+	for _, f := range files {
+		if err := upload(filepath.Base(f.Name), f.Name); err != nil {
+			return err
+		}
+	}
+	if site != "" {
+		if err := filepath.WalkDir(site, func(name string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(site, name)
+			if err != nil {
+				return err
+			}
+			return upload(path.Join(filepath.Base(filepath.Clean(site)), filepath.ToSlash(rel)), name)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uploadContentType gets the content type for an exported file.
+func uploadContentType(name string) string {
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".pb", ".binpb", ".desc", ".cbor":
+		return "application/octet-stream"
+	case ".proto", ".textpb", ".txt":
+		return "text/plain; charset=utf-8"
+	case ".ndjson":
+		return "application/x-ndjson"
+	case ".jsonld":
+		return "application/ld+json"
+	case ".kml":
+		return "application/vnd.google-earth.kml+xml"
+	case ".gz":
+		return "application/gzip"
+	default:
+		if ct := mime.TypeByExtension(ext); ct != "" {
+			return ct
+		}
+		return "application/octet-stream"
+	}
+}
+
+// s3Target uploads to an s3-compatible bucket using signature v4.
+type s3Target struct {
+	Bucket       string
+	Prefix       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Region       string
+	Endpoint     string // path-style if set, otherwise aws virtual-hosted-style
+}
+
+func (t *s3Target) Put(ctx context.Context, key string, buf []byte, contentType, cacheControl string) error {
+	var u *url.URL
+	if t.Endpoint != "" {
+		base, err := url.Parse(t.Endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid endpoint: %w", err)
+		}
+		u = base.JoinPath(t.Bucket, t.Prefix, key)
+	} else {
+		u = &url.URL{Scheme: "https", Host: t.Bucket + ".s3." + t.Region + ".amazonaws.com", Path: "/" + path.Join(t.Prefix, key)}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if cacheControl != "" {
+		req.Header.Set("Cache-Control", cacheControl)
+	}
+	if t.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", t.SessionToken)
+	}
+	s3Sign(req, buf, t.AccessKey, t.SecretKey, t.Region, time.Now())
+	return uploadDo(req)
+}
+
+// s3Sign signs req (including all headers set on it) with aws signature v4. The
+// raw path of req is set to the escaped canonical uri so the signed path is the
+// one which is sent.
+func s3Sign(req *http.Request, body []byte, accessKey, secretKey, region string, now time.Time) {
+	req.URL.RawPath = s3EscapePath(req.URL.Path)
+	now = now.UTC()
+	date := now.Format("20060102")
+	payload := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payload[:]))
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.Join(v, ",")
+	}
+	var canonicalHeaders strings.Builder
+	names := slices.Sorted(maps.Keys(headers))
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + strings.TrimSpace(headers[k]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := req.URL.Query()
+	for k := range query {
+		slices.Sort(query[k])
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.RawPath,
+		strings.ReplaceAll(query.Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payload[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	crHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + scope + "\n" + hex.EncodeToString(crHash[:])
+
+	key := []byte("AWS4" + secretKey)
+	for _, x := range []string{date, region, "s3", "aws4_request"} {
+		key = s3HMAC(key, x)
+	}
+	sig := hex.EncodeToString(s3HMAC(key, stringToSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+sig)
+}
+
+// s3EscapePath escapes each segment of p for the canonical uri of an aws
+// signature v4 request, which requires everything other than unreserved
+// characters to be percent-encoded (unlike [url.URL.EscapedPath], which leaves
+// characters like "+", ":", and "(" as-is).
+func s3EscapePath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '.', c == '_', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// azureTarget uploads to an azure blob storage container using a sas url.
+type azureTarget struct {
+	URL *url.URL
+}
+
+func (t *azureTarget) Put(ctx context.Context, key string, buf []byte, contentType, cacheControl string) error {
+	u := t.URL.JoinPath(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Ms-Blob-Type", "BlockBlob")
+	req.Header.Set("X-Ms-Version", "2021-08-06")
+	req.Header.Set("X-Ms-Blob-Content-Type", contentType)
+	if cacheControl != "" {
+		req.Header.Set("X-Ms-Blob-Cache-Control", cacheControl)
+	}
+	return uploadDo(req)
+}
+
+func uploadDo(req *http.Request) error {
+	resp, err := publishClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("response status %d", resp.StatusCode)
+	}
+	return nil
+}
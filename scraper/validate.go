@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pgaskin/ottrec/schema"
+	"golang.org/x/net/html"
+)
+
+// validateData checks invariants which should hold for any data produced by
+// the scraper (see [schema.Validate]), and that the html fields are well-formed,
+// returning an error for each violation.
+func validateData(pb *schema.Data) []error {
+	var errs []error
+	for _, issue := range schema.Validate(pb) {
+		errs = append(errs, errors.New(issue.String()))
+	}
+	for _, f := range pb.GetFacilities() {
+		report := func(ctx, format string, a ...any) {
+			errs = append(errs, fmt.Errorf("facility %q%s: %s", f.GetName(), ctx, fmt.Sprintf(format, a...)))
+		}
+		for _, x := range []struct {
+			name, html string
+		}{
+			{"notifications", f.GetNotificationsHtml()},
+			{"special hours", f.GetSpecialHoursHtml()},
+		} {
+			if err := validateHTML(x.html); err != nil {
+				report("", "malformed %s html: %v", x.name, err)
+			}
+		}
+		for _, g := range f.GetScheduleGroups() {
+			if err := validateHTML(g.GetScheduleChangesHtml()); err != nil {
+				report(fmt.Sprintf(" > group %q", g.GetLabel()), "malformed schedule changes html: %v", err)
+			}
+		}
+	}
+	return errs
+}
+
+// validateHTML checks that all non-void elements in an html fragment are
+// properly nested and closed.
+func validateHTML(s string) error {
+	var stack []string
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return err
+			}
+			if len(stack) != 0 {
+				return fmt.Errorf("unclosed <%s>", stack[len(stack)-1])
+			}
+			return nil
+		case html.StartTagToken:
+			if tag := z.Token().Data; !htmlVoidElements[tag] {
+				stack = append(stack, tag)
+			}
+		case html.EndTagToken:
+			tag := z.Token().Data
+			if htmlVoidElements[tag] {
+				continue
+			}
+			if len(stack) == 0 || stack[len(stack)-1] != tag {
+				return fmt.Errorf("unexpected </%s>", tag)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// validate loads the data files and validates them, writing a report to w.
+func validate(w io.Writer, names []string) error {
+	var n int
+	for _, name := range names {
+		pb, err := loadData(name)
+		if err != nil {
+			return fmt.Errorf("load %q: %w", name, err)
+		}
+		errs := validateData(pb)
+		for _, err := range errs {
+			fmt.Fprintf(w, "%s: %v\n", name, err)
+		}
+		n += len(errs)
+	}
+	if n != 0 {
+		return fmt.Errorf("%d validation errors", n)
+	}
+	return nil
+}
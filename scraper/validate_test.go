@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestValidateHTML(t *testing.T) {
+	for _, tc := range []struct {
+		HTML string
+		Err  string
+	}{
+		{"", ""},
+		{"text", ""},
+		{`<p>a<br>b<img src="x"/></p><ul><li>c</li></ul>`, ""},
+		{"<p>a", "unclosed <p>"},
+		{"<p><b>a</p></b>", "unexpected </p>"},
+		{"a</div>", "unexpected </div>"},
+	} {
+		if err := validateHTML(tc.HTML); (err == nil) != (tc.Err == "") || (err != nil && err.Error() != tc.Err) {
+			t.Errorf("html %q: expected error %q, got %v", tc.HTML, tc.Err, err)
+		}
+	}
+}
+
+func TestValidateData(t *testing.T) {
+	if errs := validateData(schema.Data_builder{}.Build()); len(errs) != 1 {
+		t.Errorf("empty data: expected 1 error, got %q", errs)
+	}
+	good := schema.Data_builder{
+		Facilities: []*schema.Facility{schema.Facility_builder{
+			Name:              "A Pool",
+			Source:            schema.Source_builder{Url: "https://example.com/a"}.Build(),
+			XLnglat:           schema.LngLat_builder{Lng: -75.5, Lat: 45.25}.Build(),
+			NotificationsHtml: "<p>Closed</p>",
+			ScheduleGroups: []*schema.ScheduleGroup{schema.ScheduleGroup_builder{
+				Label: "Swimming",
+				Schedules: []*schema.Schedule{schema.Schedule_builder{
+					Caption:   "Swim",
+					XFrom:     ptrTo(int32(schema.MakeDate(2025, time.September, 1, -1))),
+					XTo:       ptrTo(int32(schema.MakeDate(2025, time.December, 21, -1))),
+					Days:      []string{"Monday"},
+					XDaydates: []int32{0},
+					Activities: []*schema.Schedule_Activity{schema.Schedule_Activity_builder{
+						Label: "Lane swim",
+						Days: []*schema.Schedule_ActivityDay{schema.Schedule_ActivityDay_builder{
+							Times: []*schema.TimeRange{schema.TimeRange_builder{
+								Label:  "7 - 8 am",
+								XWkday: ptrTo(schema.Weekday_MONDAY),
+								XStart: ptrTo(int32(420)),
+								XEnd:   ptrTo(int32(480)),
+							}.Build()},
+						}.Build()},
+					}.Build()},
+				}.Build()},
+			}.Build()},
+		}.Build()},
+	}.Build()
+	if errs := validateData(good); len(errs) != 0 {
+		t.Errorf("valid data: unexpected errors %q", errs)
+	}
+
+	f := good.GetFacilities()[0]
+	s := f.GetScheduleGroups()[0].GetSchedules()[0]
+	tr := s.GetActivities()[0].GetDays()[0].GetTimes()[0]
+	f.SetXLnglat(schema.LngLat_builder{}.Build())
+	f.SetNotificationsHtml("<p>Closed")
+	s.SetXTo(int32(schema.MakeDate(2025, time.February, 30, -1)))
+	s.SetDays([]string{"Monday", "Tuesday"})
+	tr.SetXEnd(400)
+	var msgs []string
+	for _, err := range validateData(good) {
+		msgs = append(msgs, err.Error())
+	}
+	for _, x := range []string{
+		"coordinates out of range",
+		"malformed notifications html: unclosed <p>",
+		"invalid to date 202502300",
+		"1 parsed day dates for 2 days",
+		"1 activity days for 2 schedule days",
+		`invalid time range 7:00am - 6:40am for "7 - 8 am"`,
+	} {
+		if !strings.Contains(strings.Join(msgs, "\n"), x) {
+			t.Errorf("expected error containing %q, got %q", x, msgs)
+		}
+	}
+}
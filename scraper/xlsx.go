@@ -0,0 +1,280 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+// scheduleDate converts a fully-specified YYYYMMDDW date into a time.
+func scheduleDate(d int32) (time.Time, bool) {
+	if _, hasYear := schema.Date(d).Year(); !hasYear {
+		return time.Time{}, false
+	}
+	return schema.Date(d).Resolve(time.Time{}, time.UTC)
+}
+
+// exportXLSX converts pb into a spreadsheet with an index sheet listing the
+// facilities, one sheet per facility listing the schedule time slots, and a
+// sheet with the attribution.
+func exportXLSX(pb *schema.Data) ([]byte, error) {
+	index := xlsxSheet{Name: "Facilities"}
+	index.Rows = append(index.Rows, xlsxHeader("Name", "Address", "Longitude", "Latitude", "Sheet", "URL"))
+
+	var sheets []xlsxSheet
+	names := map[string]bool{strings.ToLower(index.Name): true, "attribution": true}
+	for _, f := range pb.GetFacilities() {
+		sheet := xlsxSheet{Name: xlsxSheetName(f.GetName(), names)}
+		sheet.Rows = append(sheet.Rows, xlsxHeader("Group", "Schedule", "From", "To", "Activity", "Reservation", "Day", "Weekday", "Start", "End", "Time"))
+		for x := range f.AllTimeRanges() {
+			row := []xlsxCell{
+				xlsxStr(cmp.Or(x.Group.GetXTitle(), x.Group.GetLabel())),
+				xlsxStr(x.Schedule.GetCaption()),
+				xlsxDate(x.Schedule.GetXFrom()),
+				xlsxDate(x.Schedule.GetXTo()),
+				xlsxStr(x.Activity.GetLabel()),
+				{},
+				xlsxStr(x.Day),
+				{},
+				{},
+				{},
+				xlsxStr(x.Time.GetLabel()),
+			}
+			if x.Activity.HasXResv() {
+				row[5] = xlsxStr(map[bool]string{true: "required", false: "not required"}[x.Activity.GetXResv()])
+			}
+			if x.Time.HasXWkday() {
+				row[7] = xlsxStr(x.Time.GetXWkday().Name("en"))
+			}
+			if x.Time.HasXStart() {
+				row[8] = xlsxTime(x.Time.GetXStart())
+			}
+			if x.Time.HasXEnd() {
+				row[9] = xlsxTime(x.Time.GetXEnd())
+			}
+			sheet.Rows = append(sheet.Rows, row)
+		}
+		row := []xlsxCell{xlsxStr(f.GetName()), xlsxStr(strings.Join(strings.Fields(f.GetAddress()), " ")), {}, {}, xlsxStr(sheet.Name), xlsxStr(f.GetSource().GetUrl())}
+		if f.HasXLnglat() {
+			row[2] = xlsxCell{Num: float64(f.GetXLnglat().GetLng()), IsNum: true}
+			row[3] = xlsxCell{Num: float64(f.GetXLnglat().GetLat()), IsNum: true}
+		}
+		index.Rows = append(index.Rows, row)
+		sheets = append(sheets, sheet)
+	}
+
+	if attrib := pb.GetAttribution(); len(attrib) != 0 {
+		sheet := xlsxSheet{Name: "Attribution"}
+		sheet.Rows = append(sheet.Rows, xlsxHeader("Attribution"))
+		for _, x := range attrib {
+			sheet.Rows = append(sheet.Rows, []xlsxCell{xlsxStr(x)})
+		}
+		sheets = append(sheets, sheet)
+	}
+
+	var buf bytes.Buffer
+	if err := writeXLSX(&buf, append([]xlsxSheet{index}, sheets...)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// xlsxSheetName makes a valid unique sheet name from s.
+func xlsxSheetName(s string, used map[string]bool) string {
+	s = strings.Join(strings.Fields(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return ' '
+		}
+		return r
+	}, s)), " ")
+	s = strings.Trim(s, "'")
+	if s == "" {
+		s = "Sheet"
+	}
+	name := s
+	for i := 2; ; i++ {
+		if r := []rune(name); len(r) > 31 {
+			name = string(r[:31])
+		}
+		if !used[strings.ToLower(name)] {
+			break
+		}
+		suffix := " (" + strconv.Itoa(i) + ")"
+		if r := []rune(s); len(r)+len(suffix) > 31 {
+			name = string(r[:31-len(suffix)]) + suffix
+		} else {
+			name = s + suffix
+		}
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+// xlsxSheet is a worksheet.
+type xlsxSheet struct {
+	Name string
+	Rows [][]xlsxCell
+}
+
+// xlsxCell is a worksheet cell. The zero value is an empty cell.
+type xlsxCell struct {
+	Str   string
+	Num   float64
+	IsNum bool
+	Style int // index into the cellXfs in xlsxStyles
+}
+
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleHeader
+	xlsxStyleDate
+	xlsxStyleTime
+)
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/><numFmt numFmtId="165" formatCode="h:mm AM/PM"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="4"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>
+</styleSheet>`
+
+func xlsxStr(s string) xlsxCell {
+	return xlsxCell{Str: s}
+}
+
+func xlsxHeader(s ...string) []xlsxCell {
+	row := make([]xlsxCell, len(s))
+	for i, x := range s {
+		row[i] = xlsxCell{Str: x, Style: xlsxStyleHeader}
+	}
+	return row
+}
+
+// xlsxDate converts a fully-specified YYYYMMDDW date into a cell, returning an
+// empty cell if it isn't.
+func xlsxDate(d int32) xlsxCell {
+	t, ok := scheduleDate(d)
+	if !ok {
+		return xlsxCell{}
+	}
+	epoch := time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+	return xlsxCell{
+		Num:   float64(t.Sub(epoch) / (24 * time.Hour)),
+		IsNum: true,
+		Style: xlsxStyleDate,
+	}
+}
+
+// xlsxTime converts minutes from 00:00 into a cell.
+func xlsxTime(m int32) xlsxCell {
+	return xlsxCell{
+		Num:   float64(m) / (24 * 60),
+		IsNum: true,
+		Style: xlsxStyleTime,
+	}
+}
+
+// xlsxCol returns the column letters for the zero-based column index.
+func xlsxCol(i int) string {
+	var b []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		b = append([]byte{byte('A' + (i-1)%26)}, b...)
+	}
+	return string(b)
+}
+
+// writeXLSX writes a minimal office open xml workbook.
+func writeXLSX(w io.Writer, sheets []xlsxSheet) error {
+	var (
+		ct   strings.Builder
+		wb   strings.Builder
+		rels strings.Builder
+	)
+	ct.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	ct.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	ct.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	ct.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	ct.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	ct.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	wb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	wb.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	rels.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	rels.WriteString(`<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+	for i, sheet := range sheets {
+		n := i + 1
+		fmt.Fprintf(&ct, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&wb, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheet.Name), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+	ct.WriteString(`</Types>`)
+	wb.WriteString(`</sheets></workbook>`)
+	rels.WriteString(`</Relationships>`)
+
+	zw := zip.NewWriter(w)
+	for _, f := range []struct {
+		name, data string
+	}{
+		{"[Content_Types].xml", ct.String()},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", wb.String()},
+		{"xl/_rels/workbook.xml.rels", rels.String()},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.data); err != nil {
+			return err
+		}
+	}
+	for i, sheet := range sheets {
+		fw, err := zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return err
+		}
+		var b strings.Builder
+		b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+		b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+		if len(sheet.Rows) != 0 {
+			b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+		}
+		b.WriteString(`<sheetData>`)
+		for r, row := range sheet.Rows {
+			fmt.Fprintf(&b, `<row r="%d">`, r+1)
+			for c, cell := range row {
+				ref := xlsxCol(c) + strconv.Itoa(r+1)
+				switch {
+				case cell.IsNum:
+					fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, cell.Style, strconv.FormatFloat(cell.Num, 'f', -1, 64))
+				case cell.Str != "":
+					fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, cell.Style, xmlEscape(cell.Str))
+				}
+			}
+			b.WriteString(`</row>`)
+		}
+		b.WriteString(`</sheetData></worksheet>`)
+		if _, err := io.WriteString(fw, b.String()); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pgaskin/ottrec/schema"
+)
+
+func TestXLSXCol(t *testing.T) {
+	for i, exp := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if act := xlsxCol(i); act != exp {
+			t.Errorf("col %d: expected %q, got %q", i, exp, act)
+		}
+	}
+}
+
+func TestXLSXSheetName(t *testing.T) {
+	used := map[string]bool{"facilities": true}
+	for _, tc := range []struct {
+		In, Out string
+	}{
+		{"Facilities", "Facilities (2)"},
+		{"A/B: Pool [Main]", "A B Pool Main"},
+		{"a b pool main", "a b pool main (2)"},
+		{"A Very Long Community Centre Name That Goes On", "A Very Long Community Centre Na"},
+		{"A Very Long Community Centre Name That Goes On", "A Very Long Community Centr (2)"},
+		{"''", "Sheet"},
+	} {
+		if act := xlsxSheetName(tc.In, used); act != tc.Out {
+			t.Errorf("name %q: expected %q, got %q", tc.In, tc.Out, act)
+		}
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	pb := schema.Data_builder{
+		Attribution: []string{"Test attribution"},
+		Facilities: []*schema.Facility{schema.Facility_builder{
+			Name:    "A & B Pool",
+			Address: "1 A Road\nOttawa",
+			ScheduleGroups: []*schema.ScheduleGroup{schema.ScheduleGroup_builder{
+				Label: "Drop-in swimming",
+				Schedules: []*schema.Schedule{schema.Schedule_builder{
+					Caption: "Swim",
+					XFrom:   ptrTo(int32(schema.MakeDate(2025, time.September, 1, -1))),
+					Days:    []string{"Monday"},
+					Activities: []*schema.Schedule_Activity{schema.Schedule_Activity_builder{
+						Label: "Lane swim",
+						Days: []*schema.Schedule_ActivityDay{schema.Schedule_ActivityDay_builder{
+							Times: []*schema.TimeRange{schema.TimeRange_builder{
+								Label:  "7 - 8:30 am",
+								XWkday: ptrTo(schema.Weekday(1)),
+								XStart: ptrTo(int32(7 * 60)),
+								XEnd:   ptrTo(int32(8*60 + 30)),
+							}.Build()},
+						}.Build()},
+					}.Build()},
+				}.Build()},
+			}.Build()},
+		}.Build()},
+	}.Build()
+	buf, err := exportXLSX(pb)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %q: %v", f.Name, err)
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("read %q: %v", f.Name, err)
+		}
+		r.Close()
+		if strings.HasSuffix(f.Name, ".xml") || strings.HasSuffix(f.Name, ".rels") {
+			if err := xml.Unmarshal(b, new(struct{})); err != nil {
+				t.Errorf("invalid xml in %q: %v", f.Name, err)
+			}
+		}
+		files[f.Name] = string(b)
+	}
+	for name, exp := range map[string][]string{
+		"xl/workbook.xml":          {`<sheet name="Facilities" sheetId="1" r:id="rId1"/>`, `<sheet name="A &amp; B Pool" sheetId="2" r:id="rId2"/>`, `<sheet name="Attribution" sheetId="3" r:id="rId3"/>`},
+		"xl/worksheets/sheet1.xml": {`<t xml:space="preserve">1 A Road Ottawa</t>`},
+		"xl/worksheets/sheet2.xml": {`<c r="C2" s="2"><v>45901</v></c>`, `<c r="I2" s="3"><v>0.2916666666666667</v></c>`, `<t xml:space="preserve">Monday</t>`},
+		"xl/worksheets/sheet3.xml": {`<c r="A2" s="0" t="inlineStr"><is><t xml:space="preserve">Test attribution</t></is></c>`},
+	} {
+		for _, x := range exp {
+			if !strings.Contains(files[name], x) {
+				t.Errorf("%s: expected output to contain %q", name, x)
+			}
+		}
+	}
+}